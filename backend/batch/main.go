@@ -1,19 +1,24 @@
 package main
 
 import (
+	"context"
+	"encoding/binary"
 	"encoding/json"
 	"flag"
 	"fmt"
+	"hash/fnv"
 	"log"
 	"math/rand"
 	"os"
 	"path/filepath"
 	"runtime"
+	"sort"
 	"strconv"
 	"strings"
 	"sync"
 	"time"
 
+	"github.com/aws/aws-sdk-go/service/s3"
 	"github.com/chehsunliu/poker"
 	"github.com/joho/godotenv"
 
@@ -30,6 +35,33 @@ type Scenario struct {
 	PresetName     string
 	Description    string
 	HeroHandRanges []string // ヒーローハンドの範囲（将来的な拡張用）
+	DoubleBoard    bool     // ダブルボードのシナリオか（2つのフロップでポットを半分ずつ分け合う、PLO4・PLO5のみ）
+}
+
+// Variant はプリセット名からシナリオのゲームタイプを返します
+func (s Scenario) Variant() pkrlib.Variant {
+	return fileio.PresetVariant(s.PresetName)
+}
+
+// scenarioIndex はシナリオの定義順のインデックスを返します（見つからない場合は末尾扱い）
+func scenarioIndex(name string) int {
+	for i, s := range scenarios {
+		if s.Name == name {
+			return i
+		}
+	}
+	return len(scenarios)
+}
+
+// scenarioSeed はバッチの乱数シード・対象日付・シナリオ名からシナリオごとのシードを生成します
+// シナリオの実行順序や、データの有無で実行されるシナリオが変わっても同じ値になり、
+// 同じシードで複数日を生成しても日付ごとに異なる問題になります
+func scenarioSeed(seed int64, date time.Time, scenarioName string) int64 {
+	h := fnv.New64a()
+	binary.Write(h, binary.LittleEndian, seed)
+	h.Write([]byte(date.Format("2006-01-02")))
+	h.Write([]byte(scenarioName))
+	return int64(h.Sum64())
 }
 
 // 利用可能なシナリオのリスト
@@ -64,6 +96,8 @@ var scenarios = []Scenario{
 		PresetName:  "3BP BTN call vs BB 3bet",
 		Description: "3ベットポット: BTNがBBの3ベットに対してコール",
 	},
+	// 5-card PLOはアグレッサーの3ベットレンジ（data/plo5/.../3bp/*_3b_vs_*.csv）のみ配置済みで、
+	// コールするレンジとSRPのレンジがないため、CSVを追加するまで以下のシナリオはスキップされる
 	{
 		Name:        "PLO5 SRP UTG vs BB",
 		PresetName:  "PLO5 SRP BB call vs UTG open",
@@ -94,15 +128,103 @@ var scenarios = []Scenario{
 		PresetName:  "PLO5 3BP BTN call vs BB 3bet",
 		Description: "5-card PLO 3ベットポット: BTNがBBの3ベットに対してコール",
 	},
+	// 6-card PLOのレンジデータ（data/plo6）は未配置（ディレクトリのみ）のため、CSVを追加するまで以下のシナリオはスキップされる
+	{
+		Name:        "PLO6 SRP UTG vs BB",
+		PresetName:  "PLO6 SRP BB call vs UTG open",
+		Description: "6-card PLO シングルレイズポット: BBがUTGオープンに対してコール",
+	},
+	{
+		Name:        "PLO6 SRP BTN vs BB",
+		PresetName:  "PLO6 SRP BB call vs BTN open",
+		Description: "6-card PLO シングルレイズポット: BBがBTNオープンに対してコール",
+	},
+	{
+		Name:        "PLO6 SRP UTG vs BTN",
+		PresetName:  "PLO6 SRP BTN call vs UTG open",
+		Description: "6-card PLO シングルレイズポット: BTNがUTGオープンに対してコール",
+	},
+	{
+		Name:        "PLO6 3BP BB vs UTG",
+		PresetName:  "PLO6 3BP UTG call vs BB 3bet",
+		Description: "6-card PLO 3ベットポット: UTGがBBの3ベットに対してコール",
+	},
+	{
+		Name:        "PLO6 3BP BTN vs UTG",
+		PresetName:  "PLO6 3BP UTG call vs BTN 3bet",
+		Description: "6-card PLO 3ベットポット: UTGがBTNの3ベットに対してコール",
+	},
+	{
+		Name:        "PLO6 3BP BB vs BTN",
+		PresetName:  "PLO6 3BP BTN call vs BB 3bet",
+		Description: "6-card PLO 3ベットポット: BTNがBBの3ベットに対してコール",
+	},
+	{
+		Name:        "NLHE SRP UTG vs BB",
+		PresetName:  "NLHE SRP BB call vs UTG open",
+		Description: "ノーリミットホールデム シングルレイズポット: BBがUTGオープンに対してコール",
+	},
+	{
+		Name:        "NLHE SRP BTN vs BB",
+		PresetName:  "NLHE SRP BB call vs BTN open",
+		Description: "ノーリミットホールデム シングルレイズポット: BBがBTNオープンに対してコール",
+	},
+	{
+		Name:        "NLHE SRP UTG vs BTN",
+		PresetName:  "NLHE SRP BTN call vs UTG open",
+		Description: "ノーリミットホールデム シングルレイズポット: BTNがUTGオープンに対してコール",
+	},
+	{
+		Name:        "NLHE 3BP BB vs UTG",
+		PresetName:  "NLHE 3BP UTG call vs BB 3bet",
+		Description: "ノーリミットホールデム 3ベットポット: UTGがBBの3ベットに対してコール",
+	},
+	{
+		Name:        "NLHE 3BP BTN vs UTG",
+		PresetName:  "NLHE 3BP UTG call vs BTN 3bet",
+		Description: "ノーリミットホールデム 3ベットポット: UTGがBTNの3ベットに対してコール",
+	},
+	{
+		Name:        "NLHE 3BP BB vs BTN",
+		PresetName:  "NLHE 3BP BTN call vs BB 3bet",
+		Description: "ノーリミットホールデム 3ベットポット: BTNがBBの3ベットに対してコール",
+	},
+	{
+		Name:        "Double Board SRP BTN vs BB",
+		PresetName:  "SRP BB call vs BTN open",
+		Description: "ダブルボード シングルレイズポット: BBがBTNオープンに対してコール",
+		DoubleBoard: true,
+	},
+	{
+		Name:        "PLO5 Double Board SRP BTN vs BB",
+		PresetName:  "PLO5 SRP BB call vs BTN open",
+		Description: "5-card PLO ダブルボード シングルレイズポット: BBがBTNオープンに対してコール",
+		DoubleBoard: true,
+	},
 }
 
 // EquityResult は1つのシナリオの計算結果を表します
 type EquityResult struct {
 	Scenario      Scenario
+	Variant       pkrlib.Variant // ゲームタイプ
 	HeroHand      string
 	Flop          []poker.Card
+	SecondFlop    []poker.Card // ダブルボードのシナリオの2つ目のフロップ（通常のシナリオではnil）
 	Equities      map[string]float64
-	AverageEquity float64 // 平均エクイティ
+	AverageEquity float64             // 平均エクイティ
+	Stats         pkrlib.EquityResult // 平均エクイティの勝敗数・標準誤差・95%信頼区間
+	// HandClassBreakdown は相手レンジのハンドクラス（セット・ラップ・フラッシュドローなど）ごとの割合とエクイティです
+	HandClassBreakdown []pkrlib.HandClassBreakdown
+	// BlockerInsight はヒーローのハンドが相手レンジのどの分類を多くブロックしているかの解説です（該当なしの場合は空文字列）
+	BlockerInsight string
+}
+
+// BoardString はフロップを文字列で返します（ダブルボードの場合は "2d3cJc / 9c8h4d" の形式）
+func (r EquityResult) BoardString() string {
+	if len(r.SecondFlop) == 0 {
+		return pkrlib.GenerateBoardString(r.Flop)
+	}
+	return pkrlib.GenerateBoardString(r.Flop) + " / " + pkrlib.GenerateBoardString(r.SecondFlop)
 }
 
 // バッチ処理の設定
@@ -129,7 +251,20 @@ type BatchConfig struct {
 	UseMonteCarloEquity bool   // Monte Carlo法を使用するか（false: exhaustive）
 	MonteCarloMode      string // Monte Carloの精度モード（FAST/NORMAL/ACCURATE）
 	UseAdaptiveSampling bool   // Adaptive samplingを使用するか
+	EnableDoubleBoard   bool   // ダブルボードのシナリオを計算するか（ハンドごとのモンテカルロのため計算時間が長い）
+	FlopTexture         string // フロップのテクスチャの条件（"monotone,connected"など。空文字列の場合は条件なし）
 	AutoNext            bool   // DBの最新日付+1日を自動的に対象とする
+
+	// 乱数設定
+	Seed int64 // 乱数シード（0の場合は現在時刻から生成）。同じシードと日付で同じクイズを再生成できる
+
+	// 実行時間の設定
+	Timeout time.Duration // エクイティ計算の制限時間（0の場合は無制限）。超過した時点で未完了のシナリオを打ち切る
+
+	// エクイティキャッシュの設定
+	UseEquityCache     bool // 全数計算の結果をequity_cacheテーブルに保存・再利用するか
+	EquityCacheSize    int  // メモリ上に保持するキャッシュの最大エントリ数
+	EquityCacheMaxRows int  // equity_cacheテーブルの最大エントリ数（0の場合は上限なし）
 }
 
 func main() {
@@ -145,8 +280,29 @@ func main() {
 	// ログの設定
 	setupLogging(config.LogFile)
 
-	// 乱数生成器の初期化
-	rand.Seed(time.Now().UnixNano())
+	// 乱数シードの決定（指定がない場合は現在時刻から生成し、DBに保存して再生成できるようにする）
+	if config.Seed == 0 {
+		config.Seed = time.Now().UnixNano()
+	}
+	log.Printf("Using random seed: %d", config.Seed)
+
+	// フロップのテクスチャの条件を検証（同じカテゴリのタグはOR、異なるカテゴリのタグはAND）
+	if config.FlopTexture != "" {
+		filter, err := pkrlib.ParseBoardTextureFilter(config.FlopTexture)
+		if err != nil {
+			log.Fatalf("Invalid flop texture: %v", err)
+		}
+		log.Printf("Flop texture: %s", filter)
+	}
+
+	// エクイティ計算の制限時間（GitHub Actionsのタイムアウト前に、完了したシナリオだけでも保存するため）
+	ctx := context.Background()
+	if config.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, config.Timeout)
+		defer cancel()
+		log.Printf("Equity calculation timeout: %v", config.Timeout)
+	}
 
 	// PostgreSQL接続の確立
 	pgConfig := db.PostgresConfig{
@@ -163,6 +319,14 @@ func main() {
 	}
 	defer pgDB.Close()
 
+	// エクイティキャッシュの設定（全数計算の結果を実行をまたいで再利用する）
+	var equityCache *pkrlib.EquityCache
+	if config.UseEquityCache {
+		equityCache = pkrlib.NewEquityCache(config.EquityCacheSize, db.NewPostgresEquityCacheStore(pgDB))
+		pkrlib.SetEquityCache(equityCache)
+		log.Printf("Using equity cache (memory: %d entries, table: %d rows)", config.EquityCacheSize, config.EquityCacheMaxRows)
+	}
+
 	// 並列処理の設定
 	var results []EquityResult
 
@@ -203,7 +367,12 @@ func main() {
 			scenarioName, _ := result["scenario"].(string)
 			heroHand, _ := result["hero_hand"].(string)
 			flopStr, _ := result["flop"].(string) // フロップ文字列を取得
+			secondFlopStr, _ := result["second_flop"].(string) // ダブルボードの2つ目のフロップ（通常のクイズは空）
 			averageEquity, _ := result["average_equity"].(float64)
+			gameType, _ := result["game_type"].(string)
+			if seed, ok := result["seed"].(int64); ok {
+				log.Printf("Stored quiz for %s was generated with seed %d", scenarioName, seed)
+			}
 
 			// シナリオの検索
 			var foundScenario Scenario
@@ -214,52 +383,83 @@ func main() {
 				}
 			}
 
+			// ゲームタイプはDBのgame_typeを優先し、不明な場合はシナリオから判定
+			variant, err := pkrlib.VariantFromGameType(gameType)
+			if err != nil {
+				log.Printf("Warning: %v. Using the scenario's game type", err)
+				variant = foundScenario.Variant()
+			}
+
+			// ヒーローハンドを正規化された文字列に変換
+			if hand, err := pkrlib.ParseHand(heroHand); err != nil {
+				log.Printf("Warning: Failed to parse hero hand %q: %v", heroHand, err)
+			} else {
+				heroHand = hand.String()
+			}
+
 			// フロップの文字列をpoker.Card配列に変換
 			var flopCards []poker.Card
 			if flopStr != "" {
-				// フロップ文字列は "2d3cJc" のような形式と仮定
-				// 2文字ずつ（数字+スート）で分割して処理
-				for i := 0; i < len(flopStr); i += 2 {
-					if i+2 <= len(flopStr) {
-						cardStr := strings.ToUpper(flopStr[i:i+1]) + strings.ToLower(flopStr[i+1:i+2])
-						card := poker.NewCard(cardStr)
-						flopCards = append(flopCards, card)
-					}
+				// フロップ文字列は "2d3cJc" のような形式
+				board, err := pkrlib.ParseBoard(flopStr)
+				if err != nil {
+					log.Printf("Warning: Failed to parse flop %q: %v", flopStr, err)
+				} else {
+					flopCards = board
+					log.Printf("Using flop cards: %s", board)
 				}
-				log.Printf("Using flop cards: %s", pkrlib.GenerateBoardString(flopCards))
 			} else {
 				log.Printf("Warning: No flop data found for date %s", targetDate.Format("2006-01-02"))
 			}
+			var secondFlopCards []poker.Card
+			if secondFlopStr != "" {
+				board, err := pkrlib.ParseBoard(secondFlopStr)
+				if err != nil {
+					log.Printf("Warning: Failed to parse second flop %q: %v", secondFlopStr, err)
+				} else {
+					secondFlopCards = board
+				}
+			}
 
 			// Equitiesマップの作成（データベースから取得したデータに基づく）
 			equities := make(map[string]float64)
 
 			results = append(results, EquityResult{
 				Scenario:      foundScenario,
+				Variant:       variant,
 				HeroHand:      heroHand,
 				Flop:          flopCards,
+				SecondFlop:    secondFlopCards,
 				Equities:      equities,
 				AverageEquity: averageEquity,
 			})
 		}
 	} else {
+		// レンジデータが用意されているシナリオのみを計算する
+		activeScenarios := availableScenarios(config)
+
 		// 計算処理に進む
 		if config.EnableParallelProcessing {
 			// 並列処理が有効な場合
 			maxJobs := config.MaxParallelJobs
-			log.Printf("Starting parallel processing for %d scenarios using %d jobs", len(scenarios), maxJobs)
+			log.Printf("Starting parallel processing for %d scenarios using %d jobs", len(activeScenarios), maxJobs)
 
 			// 同時実行数を制限するセマフォ
 			semaphore := make(chan struct{}, maxJobs)
 			var wg sync.WaitGroup
 
 			// 結果を収集するためのチャネル
-			resultChan := make(chan EquityResult, len(scenarios))
+			resultChan := make(chan EquityResult, len(activeScenarios))
 
 			// 各シナリオを並列で実行
-			for i, scenario := range scenarios {
-				wg.Add(1)
+			for i, scenario := range activeScenarios {
 				semaphore <- struct{}{} // セマフォを取得
+				if ctx.Err() != nil {
+					<-semaphore
+					log.Printf("Skipping scenario %d: %s (%v)", i+1, scenario.Name, ctx.Err())
+					continue
+				}
+				wg.Add(1)
 
 				// シナリオ処理をgoroutineで実行
 				go func(index int, currentScenario Scenario) {
@@ -270,34 +470,24 @@ func main() {
 					log.Printf("Selected scenario: %s", currentScenario.Name)
 
 					// シナリオに基づいてハンドとフロップを生成
-					heroHand, opponentRange, flop := generateHandsAndFlop(currentScenario, config)
+					// シナリオごとの乱数生成器（実行順序に関係なくシード・日付・シナリオ名から決まる）
+					rng := rand.New(rand.NewSource(scenarioSeed(config.Seed, targetDate, currentScenario.Name)))
+					heroHand, opponentRange, flop := generateHandsAndFlop(currentScenario, config, rng)
+					secondFlop := generateSecondFlop(currentScenario, heroHand, flop, rng)
 
 					// equity計算
-					equities, _, err := calculateEquity(heroHand, opponentRange, flop, config)
+					result, err := calculateScenarioResult(ctx, currentScenario, heroHand, opponentRange, flop, secondFlop, config, rng, newProgressLogger(currentScenario.Name))
 					if err != nil {
 						log.Printf("Error calculating equity: %v", err)
 						log.Printf("Scenario %d failed: %v", index+1, err)
 						return
 					}
 
-					// 平均エクイティの計算
-					var totalEquity float64
-					for _, equity := range equities {
-						totalEquity += equity
-					}
-					averageEquity := totalEquity / float64(len(equities))
-
 					// 結果をチャネルに送信
-					resultChan <- EquityResult{
-						Scenario:      currentScenario,
-						HeroHand:      heroHand,
-						Flop:          flop,
-						Equities:      equities,
-						AverageEquity: averageEquity,
-					}
+					resultChan <- result
 
-					log.Printf("Scenario %d completed: %s - Flop: %s, Hero: %s, Average Equity: %.2f%%",
-						index+1, currentScenario.Name, pkrlib.GenerateBoardString(flop), heroHand, averageEquity)
+					log.Printf("Scenario %d completed: %s - Flop: %s, Hero: %s, Average Equity: %.2f%% (±%.2f%%)",
+						index+1, currentScenario.Name, result.BoardString(), heroHand, result.AverageEquity, result.Stats.Margin())
 				}(i, scenario)
 			}
 
@@ -311,44 +501,62 @@ func main() {
 			for result := range resultChan {
 				results = append(results, result)
 			}
+
+			// 完了順序に関係なくシナリオの定義順に並べる（画像にする問題の選択を再現可能にするため）
+			sort.SliceStable(results, func(i, j int) bool {
+				return scenarioIndex(results[i].Scenario.Name) < scenarioIndex(results[j].Scenario.Name)
+			})
 		} else {
 			// 並列処理が無効な場合（シーケンシャル処理）
-			log.Printf("Starting sequential processing for %d scenarios", len(scenarios))
+			log.Printf("Starting sequential processing for %d scenarios", len(activeScenarios))
 
 			// 各シナリオを順次実行
-			for i, scenario := range scenarios {
+			for i, scenario := range activeScenarios {
+				if ctx.Err() != nil {
+					log.Printf("Skipping scenario %d: %s (%v)", i+1, scenario.Name, ctx.Err())
+					continue
+				}
 				log.Printf("Starting scenario %d: %s", i+1, scenario.Name)
 				log.Printf("Selected scenario: %s", scenario.Name)
 
 				// シナリオに基づいてハンドとフロップを生成
-				heroHand, opponentRange, flop := generateHandsAndFlop(scenario, config)
+				// シナリオごとの乱数生成器（実行順序に関係なくシード・日付・シナリオ名から決まる）
+				rng := rand.New(rand.NewSource(scenarioSeed(config.Seed, targetDate, scenario.Name)))
+				heroHand, opponentRange, flop := generateHandsAndFlop(scenario, config, rng)
+				secondFlop := generateSecondFlop(scenario, heroHand, flop, rng)
 
 				// equity計算
-				equities, _, err := calculateEquity(heroHand, opponentRange, flop, config)
+				result, err := calculateScenarioResult(ctx, scenario, heroHand, opponentRange, flop, secondFlop, config, rng, newProgressLogger(scenario.Name))
 				if err != nil {
 					log.Printf("Error calculating equity: %v", err)
 					log.Printf("Scenario %d failed: %v", i+1, err)
 					continue
 				}
 
-				// 平均エクイティの計算
-				var totalEquity float64
-				for _, equity := range equities {
-					totalEquity += equity
-				}
-				averageEquity := totalEquity / float64(len(equities))
-
 				// 結果を追加
-				results = append(results, EquityResult{
-					Scenario:      scenario,
-					HeroHand:      heroHand,
-					Flop:          flop,
-					Equities:      equities,
-					AverageEquity: averageEquity,
-				})
+				results = append(results, result)
 
-				log.Printf("Scenario %d completed: %s - Flop: %s, Hero: %s, Average Equity: %.2f%%",
-					i+1, scenario.Name, pkrlib.GenerateBoardString(flop), heroHand, averageEquity)
+				log.Printf("Scenario %d completed: %s - Flop: %s, Hero: %s, Average Equity: %.2f%% (±%.2f%%)",
+					i+1, scenario.Name, result.BoardString(), heroHand, result.AverageEquity, result.Stats.Margin())
+			}
+		}
+
+		// 計算したエクイティをキャッシュに保存（打ち切られた場合も完了した分は次回に再利用する）
+		if equityCache != nil {
+			if err := equityCache.Flush(); err != nil {
+				log.Printf("Warning: %v", err)
+			}
+			stats := equityCache.Stats()
+			log.Printf("Equity cache stats: %d/%d hits (%.1f%%), loaded %d, saved %d",
+				stats.Hits, stats.Hits+stats.Misses, stats.HitRate(), stats.Loaded, stats.Saved)
+
+			// テーブルの上限を超えた分は実行の最後に1回だけ削除する（失敗してもクイズの生成は続ける）
+			if config.EquityCacheMaxRows > 0 {
+				if deleted, err := db.PruneEquityCache(pgDB, config.EquityCacheMaxRows, time.Time{}); err != nil {
+					log.Printf("Warning: %v", err)
+				} else if deleted > 0 {
+					log.Printf("Pruned %d equity cache entries", deleted)
+				}
 			}
 		}
 
@@ -357,13 +565,18 @@ func main() {
 
 		// 結果をシナリオごとにグループ化
 		scenarioResults := make(map[string][]EquityResult)
+		var scenarioNames []string // 保存順序を固定するため、resultsでの出現順に保持
 		for _, result := range results {
 			scenarioName := result.Scenario.Name
+			if _, exists := scenarioResults[scenarioName]; !exists {
+				scenarioNames = append(scenarioNames, scenarioName)
+			}
 			scenarioResults[scenarioName] = append(scenarioResults[scenarioName], result)
 		}
 
 		// 各シナリオごとにバッチ用データを作成
-		for scenarioName, scenarioResultList := range scenarioResults {
+		for _, scenarioName := range scenarioNames {
+			scenarioResultList := scenarioResults[scenarioName]
 			if len(scenarioResultList) == 0 {
 				continue
 			}
@@ -377,15 +590,25 @@ func main() {
 			// このシナリオのすべての結果から対戦相手のハンドとエクイティの配列を作成
 			allVillainEquities := []VillainEquity{}
 
-			// 平均エクイティの計算用
-			totalEquity := 0.0
+			// 平均エクイティの推定精度の集計用
+			var statsList []pkrlib.EquityResult
+			var statsWeights []float64
 
-			// 最初の結果からheroHandとflopを取得（代表値として）
+			// 最初の結果からheroHand・flop・ゲームタイプ・ハンドクラスの内訳を取得（代表値として）
 			var heroHand string
-			var flop string
+			var flop, secondFlop, boardTexture, blockerInsight string
+			var variant pkrlib.Variant
+			var handClassBreakdown []pkrlib.HandClassBreakdown
 			if len(scenarioResultList) > 0 {
 				heroHand = scenarioResultList[0].HeroHand
 				flop = pkrlib.GenerateBoardString(scenarioResultList[0].Flop)
+				secondFlop = pkrlib.GenerateBoardString(scenarioResultList[0].SecondFlop)
+				variant = scenarioResultList[0].Variant
+				handClassBreakdown = scenarioResultList[0].HandClassBreakdown
+				blockerInsight = scenarioResultList[0].BlockerInsight
+				if texture, err := pkrlib.ClassifyBoardTexture(scenarioResultList[0].Flop); err == nil {
+					boardTexture = texture.String()
+				}
 			}
 
 			for _, result := range scenarioResultList {
@@ -397,7 +620,8 @@ func main() {
 					})
 				}
 
-				totalEquity += result.AverageEquity
+				statsList = append(statsList, result.Stats)
+				statsWeights = append(statsWeights, 1.0)
 			}
 
 			// VillainEquities配列をJSON文字列に変換
@@ -407,14 +631,12 @@ func main() {
 				continue
 			}
 
-			// 平均エクイティはすべての結果の平均を使用
-			averageEquity := totalEquity / float64(len(scenarioResultList))
+			// 平均エクイティはすべての結果の平均を使用し、推定精度も合成する
+			stats := pkrlib.CombineEquityResults(statsList, statsWeights)
+			averageEquity := stats.Equity
 
-			// ゲームタイプの判定
-			gameType := "4card_plo"
-			if len(heroHand) == 10 {
-				gameType = "5card_plo"
-			}
+			// 平均エクイティから相手のポットサイズのベットにコールすべきかの正解を作成する
+			callDecision := calculateCallDecision(scenarioResultList[0].Scenario, averageEquity)
 
 			// バッチ用データに追加
 			batchResults = append(batchResults, db.DailyQuizResult{
@@ -424,7 +646,15 @@ func main() {
 				Flop:          flop,
 				Result:        string(villainEquitiesJSON),
 				AverageEquity: averageEquity,
-				GameType:      gameType,
+				GameType:      variant.GameType(),
+				Stats:         &stats,
+				Seed:          config.Seed,
+
+				HandClassBreakdown: handClassBreakdown,
+				SecondFlop:         secondFlop,
+				BoardTexture:       boardTexture,
+				BlockerInsight:     blockerInsight,
+				CallDecision:       callDecision,
 			})
 		}
 
@@ -446,25 +676,21 @@ func main() {
 	if config.EnableImageUpload {
 		log.Println("Image upload is enabled. Starting image generation and upload...")
 
-		// 4-card PLOと5-card PLOそれぞれから1問ずつ選択
-		var fourCardResult *EquityResult
-		var fiveCardResult *EquityResult
+		// 4-card・5-card・6-card PLOとHold'emそれぞれから1問ずつ選択
+		quizVariants := []pkrlib.Variant{pkrlib.VariantPLO4, pkrlib.VariantPLO5, pkrlib.VariantPLO6, pkrlib.VariantHoldem}
+		quizResults := make(map[pkrlib.Variant]*EquityResult)
 
-		// resultsから4-cardと5-cardの問題を抽出
+		// resultsから各ゲームタイプの最初の問題を抽出（画像は1つのフロップのみ表示するため、ダブルボードの問題は除く）
 		for i := range results {
-			if len(results[i].HeroHand) == 8 && fourCardResult == nil {
-				fourCardResult = &results[i]
-			} else if len(results[i].HeroHand) == 10 && fiveCardResult == nil {
-				fiveCardResult = &results[i]
+			if results[i].Scenario.DoubleBoard {
+				continue
 			}
-			
-			// 両方見つかったら終了
-			if fourCardResult != nil && fiveCardResult != nil {
-				break
+			if _, found := quizResults[results[i].Variant]; !found {
+				quizResults[results[i].Variant] = &results[i]
 			}
 		}
 
-		// R2設定を取得（両方の画像で共通使用）
+		// R2設定を取得（すべての画像で共通使用）
 		r2Config := storage.R2Config{
 			Endpoint:   getEnvOrDefault("R2_ENDPOINT", ""),
 			AccessKey:  getEnvOrDefault("R2_ACCESS_KEY", ""),
@@ -478,83 +704,85 @@ func main() {
 			log.Printf("Error creating R2 client: %v", err)
 		}
 
-		// 4-card PLOの画像生成とアップロード
-		if fourCardResult != nil {
-			gameTypeDir := "4card"
-			imagePath := filepath.Join("images/daily-quiz", gameTypeDir, targetDate.Format("2006-01-02")+".png")
+		// ゲームタイプごとに画像生成とアップロード
+		for _, variant := range quizVariants {
+			generateAndUploadQuizImage(targetDate, variant, quizResults[variant], r2Client, r2Config)
+		}
 
-			err := image.GenerateDailyQuizImage(
-				targetDate,
-				fourCardResult.Scenario.Name,
-				fourCardResult.HeroHand,
-				fourCardResult.Flop,
-			)
-			if err != nil {
-				log.Printf("Error generating 4-card PLO daily quiz image: %v", err)
-			} else {
-				log.Printf("Successfully generated 4-card PLO daily quiz image for %s", targetDate.Format("2006-01-02"))
+		// 明示的にGCを呼び出し
+		runtime.GC()
 
-				if r2Client != nil {
-					// 画像をR2にアップロード
-					objectKey := "daily-quiz/" + gameTypeDir + "/" + targetDate.Format("2006-01-02") + ".png"
-					err = storage.UploadImageToR2(r2Client, r2Config.BucketName, imagePath, objectKey)
-					if err != nil {
-						log.Printf("Error uploading 4-card PLO image to R2: %v", err)
-					} else {
-						log.Printf("Successfully uploaded 4-card PLO image to R2: %s", objectKey)
+		log.Println("Image generation and upload completed")
+	} else {
+		log.Println("Image upload is disabled. Skipping image generation and upload.")
+	}
+}
 
-						// 公開URLを生成
-						publicURL := storage.GetR2ObjectURL(r2Config.Endpoint, r2Config.BucketName, objectKey)
-						log.Printf("4-card PLO image public URL: %s", publicURL)
-					}
-				}
-			}
-		} else {
-			log.Printf("No 4-card PLO result found for image generation")
-		}
+// generateAndUploadQuizImage は1問分のデイリークイズ画像を生成し、R2にアップロードする
+func generateAndUploadQuizImage(targetDate time.Time, variant pkrlib.Variant, result *EquityResult, r2Client *s3.S3, r2Config storage.R2Config) {
+	label := variant.String()
+	if result == nil {
+		log.Printf("No %s result found for image generation", label)
+		return
+	}
 
-		// 5-card PLOの画像生成とアップロード
-		if fiveCardResult != nil {
-			gameTypeDir := "5card"
-			imagePath := filepath.Join("images/daily-quiz", gameTypeDir, targetDate.Format("2006-01-02")+".png")
+	gameTypeDir := image.GameTypeDir(variant)
+	imagePath := filepath.Join("images/daily-quiz", gameTypeDir, targetDate.Format("2006-01-02")+".png")
 
-			err := image.GenerateDailyQuizImage(
-				targetDate,
-				fiveCardResult.Scenario.Name,
-				fiveCardResult.HeroHand,
-				fiveCardResult.Flop,
-			)
-			if err != nil {
-				log.Printf("Error generating 5-card PLO daily quiz image: %v", err)
-			} else {
-				log.Printf("Successfully generated 5-card PLO daily quiz image for %s", targetDate.Format("2006-01-02"))
+	err := image.GenerateDailyQuizImage(
+		targetDate,
+		result.Scenario.Name,
+		variant,
+		result.HeroHand,
+		result.Flop,
+	)
+	if err != nil {
+		log.Printf("Error generating %s daily quiz image: %v", label, err)
+		return
+	}
+	log.Printf("Successfully generated %s daily quiz image for %s", label, targetDate.Format("2006-01-02"))
 
-				if r2Client != nil {
-					// 画像をR2にアップロード
-					objectKey := "daily-quiz/" + gameTypeDir + "/" + targetDate.Format("2006-01-02") + ".png"
-					err = storage.UploadImageToR2(r2Client, r2Config.BucketName, imagePath, objectKey)
-					if err != nil {
-						log.Printf("Error uploading 5-card PLO image to R2: %v", err)
-					} else {
-						log.Printf("Successfully uploaded 5-card PLO image to R2: %s", objectKey)
+	if r2Client == nil {
+		return
+	}
 
-						// 公開URLを生成
-						publicURL := storage.GetR2ObjectURL(r2Config.Endpoint, r2Config.BucketName, objectKey)
-						log.Printf("5-card PLO image public URL: %s", publicURL)
-					}
-				}
-			}
-		} else {
-			log.Printf("No 5-card PLO result found for image generation")
-		}
+	// 画像をR2にアップロード
+	objectKey := "daily-quiz/" + gameTypeDir + "/" + targetDate.Format("2006-01-02") + ".png"
+	err = storage.UploadImageToR2(r2Client, r2Config.BucketName, imagePath, objectKey)
+	if err != nil {
+		log.Printf("Error uploading %s image to R2: %v", label, err)
+		return
+	}
+	log.Printf("Successfully uploaded %s image to R2: %s", label, objectKey)
 
-		// 明示的にGCを呼び出し
-		runtime.GC()
+	// 公開URLを生成
+	publicURL := storage.GetR2ObjectURL(r2Config.Endpoint, r2Config.BucketName, objectKey)
+	log.Printf("%s image public URL: %s", label, publicURL)
+}
 
-		log.Println("Image generation and upload completed")
-	} else {
-		log.Println("Image upload is disabled. Skipping image generation and upload.")
+// availableScenarios はレンジデータ（CSV）が存在するシナリオのみを返す
+// データが未配置のゲームタイプ（例: data/plo6）のシナリオと、無効な場合のダブルボードのシナリオはスキップする
+// レンジデータがないシナリオは、気づけるように最後にまとめて警告する
+func availableScenarios(config *BatchConfig) []Scenario {
+	var available []Scenario
+	var missingData []string
+	for _, scenario := range scenarios {
+		if scenario.DoubleBoard && !config.EnableDoubleBoard {
+			log.Printf("Skipping scenario %s: double board scenarios are disabled", scenario.Name)
+			continue
+		}
+		if !fileio.PresetDataExists(scenario.PresetName, config.DataDir) {
+			log.Printf("Skipping scenario %s: range data not found in %s", scenario.Name, config.DataDir)
+			missingData = append(missingData, scenario.Name)
+			continue
+		}
+		available = append(available, scenario)
 	}
+	if len(missingData) > 0 {
+		log.Printf("Warning: %d scenarios were skipped because their range data is missing (see \"レンジデータ\" in batch/README.md): %s",
+			len(missingData), strings.Join(missingData, ", "))
+	}
+	return available
 }
 
 // コマンドライン引数を解析する
@@ -579,6 +807,8 @@ func parseFlags() *BatchConfig {
 	useMonteCarloEquity := getEnvBoolOrDefault("USE_MONTE_CARLO_EQUITY", false)
 	monteCarloMode := getEnvOrDefault("MONTE_CARLO_MODE", "ACCURATE")
 	useAdaptiveSampling := getEnvBoolOrDefault("USE_ADAPTIVE_SAMPLING", false)
+	enableDoubleBoard := getEnvBoolOrDefault("ENABLE_DOUBLE_BOARD", false)
+	flopTexture := getEnvOrDefault("FLOP_TEXTURE", "")
 
 	flag.StringVar(&config.LogFile, "log", "", "Log file (empty for stdout)")
 	flag.StringVar(&config.DataDir, "data", "data", "Directory containing preset data files")
@@ -602,8 +832,21 @@ func parseFlags() *BatchConfig {
 	flag.BoolVar(&config.UseMonteCarloEquity, "monte-carlo", useMonteCarloEquity, "Use Monte Carlo equity calculation instead of exhaustive")
 	flag.StringVar(&config.MonteCarloMode, "monte-carlo-mode", monteCarloMode, "Monte Carlo accuracy mode (FAST/NORMAL/ACCURATE)")
 	flag.BoolVar(&config.UseAdaptiveSampling, "adaptive", useAdaptiveSampling, "Use adaptive sampling for hand vs range calculation")
+	flag.BoolVar(&config.EnableDoubleBoard, "double-board", enableDoubleBoard, "Also generate double board (PLO4/PLO5) scenarios")
+	flag.StringVar(&config.FlopTexture, "flop-texture", flopTexture, "Comma-separated flop textures, e.g. monotone,connected (empty: any flop)")
 	flag.BoolVar(&config.AutoNext, "auto-next", false, "Automatically use latest DB date + 1 day as target date")
 
+	// 乱数設定
+	flag.Int64Var(&config.Seed, "seed", int64(getEnvIntOrDefault("BATCH_SEED", 0)), "Random seed for quiz generation and sampling (0: derived from the current time)")
+
+	// 実行時間の設定
+	flag.DurationVar(&config.Timeout, "timeout", getEnvDurationOrDefault("BATCH_TIMEOUT", 0), "Time limit for equity calculations, e.g. 55m (0: no limit)")
+
+	// エクイティキャッシュ設定
+	flag.BoolVar(&config.UseEquityCache, "equity-cache", getEnvBoolOrDefault("USE_EQUITY_CACHE", false), "Store and reuse exhaustive equities in the equity_cache table")
+	flag.IntVar(&config.EquityCacheSize, "equity-cache-size", getEnvIntOrDefault("EQUITY_CACHE_SIZE", 500000), "Maximum number of equity cache entries kept in memory")
+	flag.IntVar(&config.EquityCacheMaxRows, "equity-cache-max-rows", getEnvIntOrDefault("EQUITY_CACHE_MAX_ROWS", 2000000), "Maximum number of rows in the equity_cache table (0: no limit)")
+
 	flag.Parse()
 
 	return config
@@ -637,6 +880,16 @@ func getEnvBoolOrDefault(key string, defaultValue bool) bool {
 	return defaultValue
 }
 
+// 環境変数から時間（"55m"などの形式）を取得するヘルパー関数
+func getEnvDurationOrDefault(key string, defaultValue time.Duration) time.Duration {
+	if value, exists := os.LookupEnv(key); exists {
+		if durationValue, err := time.ParseDuration(value); err == nil {
+			return durationValue
+		}
+	}
+	return defaultValue
+}
+
 // ログの設定
 func setupLogging(logFile string) {
 	if logFile != "" {
@@ -649,67 +902,51 @@ func setupLogging(logFile string) {
 }
 
 // シナリオに基づいてハンドとフロップを生成する
-func generateHandsAndFlop(scenario Scenario, config *BatchConfig) (string, string, []poker.Card) {
-	// Opponentレンジはプリセットから読み込む
-	opponentRange, err := fileio.LoadOpponentRangeFromPreset(scenario.PresetName, config.DataDir)
+// ヒーローハンドとフロップの選択にはrngを使用するため、同じシードから同じ問題が生成される
+func generateHandsAndFlop(scenario Scenario, config *BatchConfig, rng *rand.Rand) (string, pkrlib.WeightedRange, []poker.Card) {
+	// Opponentレンジは頻度付きでプリセットから読み込む
+	opponentRange, err := fileio.LoadWeightedOpponentRangeFromPreset(scenario.PresetName, config.DataDir)
 	if err != nil {
 		// 失敗したらpanicを投げる
 		panic(fmt.Sprintf("Failed to load opponent range: %v", err))
 	}
 
-	// アグレッサー側のレンジを読み込む
-	aggressorRange, err := fileio.LoadAggressorRangeFromPreset(scenario.PresetName, config.DataDir)
+	// アグレッサー側のレンジを頻度付きで読み込む
+	aggressorRange, err := fileio.LoadWeightedAggressorRangeFromPreset(scenario.PresetName, config.DataDir)
 	if err != nil {
 		log.Printf("Error loading aggressor range: %v", err)
 		// 失敗したらpanicを投げる
 		panic(fmt.Sprintf("Failed to load aggressor range: %v", err))
 	}
 
-	// アグレッサー側のレンジからランダムに1ハンドを選ぶ
-	aggressorHands := strings.Split(aggressorRange, ",")
-	if len(aggressorHands) == 0 {
+	// アグレッサー側のレンジから頻度に比例して1ハンドを選ぶ（"@1"のハンドは"@100"のハンドの1/100の確率）
+	heroCards, err := aggressorRange.Sample(rng)
+	if err != nil {
 		// 失敗したらpanicを投げる
-		panic("No aggressor hands found")
+		panic(fmt.Sprintf("Failed to select hero hand: %v", err))
 	}
-	heroHand := aggressorHands[rand.Intn(len(aggressorHands))]
-	log.Printf("Selected hero hand from aggressor range: %s", heroHand)
-
-	// heroHandに含まれるカードは除外して、flopをランダムに生成
-	// ヒーローハンドをpoker.Card形式に変換
-	var heroCards []poker.Card
-	if len(heroHand) == 8 { // 4-card PLOハンド（4枚）
-		for j := 0; j < 8; j += 2 {
-			cardStr := strings.ToUpper(heroHand[j:j+1]) + strings.ToLower(heroHand[j+1:j+2])
-			card := poker.NewCard(cardStr)
-			heroCards = append(heroCards, card)
-		}
-	} else if len(heroHand) == 10 { // 5-card PLOハンド（5枚）
-		for j := 0; j < 10; j += 2 {
-			cardStr := strings.ToUpper(heroHand[j:j+1]) + strings.ToLower(heroHand[j+1:j+2])
-			card := poker.NewCard(cardStr)
-			heroCards = append(heroCards, card)
-		}
-	} else {
-		log.Printf("Warning: Unexpected hero hand format: %s", heroHand)
+	if err := scenario.Variant().ValidateHand(heroCards); err != nil {
+		panic(fmt.Sprintf("Unexpected hero hand for scenario %s: %v", scenario.Name, err))
 	}
+	heroHand := pkrlib.Hand(heroCards).String()
+	log.Printf("Selected hero hand from aggressor range: %s", heroHand)
 
-	// 52枚のデッキを生成
-	deck := poker.NewDeck()
-	fullDeck := deck.Draw(52) // 52枚すべてのカードを取得
+	// ヒーローハンドに含まれるカードを除外したデッキ（固定順序のため、同じrngから同じフロップになる）
+	remainingDeck := pkrlib.RemainingDeck(heroCards)
 
-	// ヒーローハンドに含まれるカードを除外
-	remainingDeck := []poker.Card{}
-	for _, card := range fullDeck {
-		isInHeroHand := false
-		for _, heroCard := range heroCards {
-			if card.String() == heroCard.String() {
-				isInHeroHand = true
-				break
-			}
+	// テクスチャの条件がある場合は、条件を満たすフロップから選ぶ
+	if config.FlopTexture != "" {
+		filter, err := pkrlib.ParseBoardTextureFilter(config.FlopTexture)
+		if err != nil {
+			// 失敗したらpanicを投げる
+			panic(fmt.Sprintf("Invalid flop texture: %v", err))
 		}
-		if !isInHeroHand {
-			remainingDeck = append(remainingDeck, card)
+		flop, err := pkrlib.RandomFlopWithTexture(rng, remainingDeck, filter)
+		if err != nil {
+			panic(fmt.Sprintf("Failed to generate flop: %v", err))
 		}
+		log.Printf("Generated flop: %s (%s)", pkrlib.GenerateBoardString(flop), filter)
+		return heroHand, opponentRange, flop
 	}
 
 	// 残りのカードからランダムに3枚選んでフロップとする
@@ -718,7 +955,7 @@ func generateHandsAndFlop(scenario Scenario, config *BatchConfig) (string, strin
 		if len(remainingDeck) == 0 {
 			break
 		}
-		idx := rand.Intn(len(remainingDeck))
+		idx := rng.Intn(len(remainingDeck))
 		flop = append(flop, remainingDeck[idx])
 		// 選んだカードを削除（重複を避けるため）
 		remainingDeck = append(remainingDeck[:idx], remainingDeck[idx+1:]...)
@@ -728,48 +965,62 @@ func generateHandsAndFlop(scenario Scenario, config *BatchConfig) (string, strin
 	return heroHand, opponentRange, flop
 }
 
-// equity計算を実行する
-func calculateEquity(heroHand string, opponentRange string, flop []poker.Card, config *BatchConfig) (map[string]float64, int, error) {
-	// ヒーローハンドをpoker.Card形式に変換
-	var yourHand []poker.Card
-	if len(heroHand) == 8 { // 4-card PLO
-		for j := 0; j < 8; j += 2 {
-			cardStr := strings.ToUpper(heroHand[j:j+1]) + strings.ToLower(heroHand[j+1:j+2])
-			tempCard := poker.NewCard(cardStr)
-			yourHand = append(yourHand, tempCard)
-		}
-	} else if len(heroHand) == 10 { // 5-card PLO
-		for j := 0; j < 10; j += 2 {
-			cardStr := strings.ToUpper(heroHand[j:j+1]) + strings.ToLower(heroHand[j+1:j+2])
-			tempCard := poker.NewCard(cardStr)
-			yourHand = append(yourHand, tempCard)
+// ダブルボードのシナリオの2つ目のフロップを生成する（通常のシナリオではnilを返す）
+// 1つ目のフロップの後にrngを使用するため、通常のシナリオの問題には影響しない
+func generateSecondFlop(scenario Scenario, heroHand string, flop []poker.Card, rng *rand.Rand) []poker.Card {
+	if !scenario.DoubleBoard {
+		return nil
+	}
+	heroCards, err := pkrlib.ParseHand(heroHand)
+	if err != nil {
+		// 失敗したらpanicを投げる
+		panic(fmt.Sprintf("Failed to parse hero hand: %v", err))
+	}
+
+	// ヒーローハンドと1つ目のフロップを除外したデッキから3枚選ぶ
+	remainingDeck := pkrlib.RemainingDeck(heroCards, flop)
+	secondFlop := []poker.Card{}
+	for i := 0; i < 3; i++ {
+		idx := rng.Intn(len(remainingDeck))
+		secondFlop = append(secondFlop, remainingDeck[idx])
+		remainingDeck = append(remainingDeck[:idx], remainingDeck[idx+1:]...)
+	}
+
+	log.Printf("Generated second flop: %s", pkrlib.GenerateBoardString(secondFlop))
+	return secondFlop
+}
+
+// レンジ計算の進捗を10%ごとにログ出力するコールバックを作成する
+func newProgressLogger(label string) pkrlib.ProgressFunc {
+	nextPercent := 10.0
+	return func(p pkrlib.Progress) {
+		if p.Percent() < nextPercent {
+			return
 		}
-	} else {
-		return nil, 0, fmt.Errorf("invalid hero hand format: %s", heroHand)
-	}
-
-	// Opponentレンジをpoker.Card形式に変換
-	opponentHands := strings.Split(opponentRange, ",")
-	var formattedOpponentHands [][]poker.Card
-	for _, hand := range opponentHands {
-		tmpHand := strings.Split(hand, "@")[0]
-		var tempArray []poker.Card
-		if len(tmpHand) == 8 { // 4-card PLO
-			for j := 0; j < 8; j += 2 {
-				cardStr := strings.ToUpper(tmpHand[j:j+1]) + strings.ToLower(tmpHand[j+1:j+2])
-				tempCard := poker.NewCard(cardStr)
-				tempArray = append(tempArray, tempCard)
-			}
-			formattedOpponentHands = append(formattedOpponentHands, tempArray)
-		} else if len(tmpHand) == 10 { // 5-card PLO
-			for j := 0; j < 10; j += 2 {
-				cardStr := strings.ToUpper(tmpHand[j:j+1]) + strings.ToLower(tmpHand[j+1:j+2])
-				tempCard := poker.NewCard(cardStr)
-				tempArray = append(tempArray, tempCard)
-			}
-			formattedOpponentHands = append(formattedOpponentHands, tempArray)
+		for nextPercent <= p.Percent() {
+			nextPercent += 10
 		}
+		log.Printf("%s: %d/%d hands (%.0f%%), elapsed %v, ETA %v",
+			label, p.Done, p.Total, p.Percent(), p.Elapsed.Round(time.Second), p.ETA.Round(time.Second))
+	}
+}
+
+// equity計算を実行する
+// 各相手ハンドのエクイティと、頻度で重み付けしたレンジ全体の結果（標準誤差・95%信頼区間を含む）を返す
+// Monte Carlo・Adaptive samplingの乱数にはrngを使用し、進捗はprogressに通知する
+// ctxの期限切れで計算が打ち切られた場合は、部分的な結果をクイズにしないためエラーを返す
+func calculateEquity(ctx context.Context, variant pkrlib.Variant, heroHand string, opponentRange pkrlib.WeightedRange, flop []poker.Card, config *BatchConfig, rng *rand.Rand, progress pkrlib.ProgressFunc) (map[string]float64, pkrlib.EquityResult, error) {
+	// ヒーローハンドをpoker.Card形式に変換
+	yourHand, err := pkrlib.ParseHand(heroHand)
+	if err != nil {
+		return nil, pkrlib.EquityResult{}, fmt.Errorf("invalid hero hand format: %v", err)
 	}
+	if err := variant.ValidateHand(yourHand); err != nil {
+		return nil, pkrlib.EquityResult{}, fmt.Errorf("invalid hero hand format: %s (%v)", heroHand, err)
+	}
+
+	// 頻度を除いたハンドのリスト（ログ出力用）
+	formattedOpponentHands := opponentRange.Hands()
 
 	// Adaptive Samplingを使用するかどうかで分岐
 	if config.UseAdaptiveSampling {
@@ -792,19 +1043,19 @@ func calculateEquity(heroHand string, opponentRange string, flop []poker.Card, c
 		}
 		
 		// Adaptive samplingで計算（個別のエクイティも取得）
-		equities, avgEquity, samplesUsed, err := pkrlib.CalculateHandVsRangeAdaptiveWithDetails(
-			yourHand, formattedOpponentHands, flop, adaptiveConfig,
+		equities, result, err := pkrlib.CalculateHandVsWeightedRangeAdaptiveWithDetailsContext(
+			ctx, yourHand, opponentRange, flop, nil, adaptiveConfig, rng, progress,
 		)
 		if err != nil {
-			return nil, 0, err
+			return nil, pkrlib.EquityResult{}, partialResultError(result, len(equities), err)
 		}
 		
-		log.Printf("Adaptive sampling completed: used %d samples out of %d hands (%.1f%%), average equity: %.2f%%, total equities calculated: %d",
-			samplesUsed, len(formattedOpponentHands), 
-			float64(samplesUsed)/float64(len(formattedOpponentHands))*100,
-			avgEquity, len(equities))
+		log.Printf("Adaptive sampling completed: used %d samples out of %d hands (%.1f%%), average equity: %.2f%% (±%.2f%%), total equities calculated: %d",
+			result.Samples, len(formattedOpponentHands), 
+			float64(result.Samples)/float64(len(formattedOpponentHands))*100,
+			result.Equity, result.Margin(), len(equities))
 		
-		return equities, samplesUsed, nil
+		return equities, result, nil
 	} else if config.UseMonteCarloEquity {
 		// Monte Carlo法を使用（各ハンドに対して個別に計算）
 		log.Printf("Using Monte Carlo equity calculation (mode: %s)", config.MonteCarloMode)
@@ -834,41 +1085,221 @@ func calculateEquity(heroHand string, opponentRange string, flop []poker.Card, c
 		
 		// 各相手ハンドに対してAdaptive計算を実行
 		equities := make(map[string]float64)
-		totalIterations := 0
+		var handResults []pkrlib.EquityResult
+		var handWeights []float64
 		
-		for _, opponentHand := range formattedOpponentHands {
-			if pkrlib.HasCardDuplicates(yourHand, opponentHand, flop) {
-				continue
+		var validRange pkrlib.WeightedRange
+		for _, opponentHand := range opponentRange {
+			if opponentHand.Weight > 0 && !pkrlib.HasCardDuplicates(yourHand, opponentHand.Cards, flop) {
+				validRange = append(validRange, opponentHand)
+			}
+		}
+		
+		startTime := time.Now()
+		for i, opponentHand := range validRange {
+			if err := ctx.Err(); err != nil {
+				return nil, pkrlib.EquityResult{}, partialResultError(pkrlib.EquityResult{Partial: true}, len(equities), err)
 			}
 			
 			villainHandStr := ""
-			for _, card := range opponentHand {
+			for _, card := range opponentHand.Cards {
 				villainHandStr += card.String()
 			}
 			
-			equity, iterations, err := pkrlib.CalculateHandVsHandEquityAdaptive(yourHand, opponentHand, flop, adaptiveConfig)
-			if err == nil && equity != -1 {
-				equities[villainHandStr] = equity
-				totalIterations += iterations
+			handResult, err := pkrlib.CalculateHandVsHandEquityAdaptive(yourHand, opponentHand.Cards, flop, adaptiveConfig, rng)
+			if err == nil {
+				equities[villainHandStr] = handResult.Equity
+				handResults = append(handResults, handResult)
+				handWeights = append(handWeights, opponentHand.Weight)
+			}
+			
+			if progress != nil {
+				elapsed := time.Since(startTime)
+				done := i + 1
+				progress(pkrlib.Progress{
+					Done:    done,
+					Total:   len(validRange),
+					Elapsed: elapsed,
+					ETA:     time.Duration(float64(elapsed) / float64(done) * float64(len(validRange)-done)),
+				})
 			}
 		}
 		
-		log.Printf("Monte Carlo calculation completed with average %d iterations per hand", totalIterations/len(equities))
-		return equities, 0, nil
+		if len(equities) == 0 {
+			return nil, pkrlib.EquityResult{}, fmt.Errorf("no valid equity calculations")
+		}
+		
+		// 頻度で重み付けした平均エクイティと、ハンドごとの推定誤差を合成
+		result := pkrlib.CombineEquityResults(handResults, handWeights)
+		log.Printf("Monte Carlo calculation completed with average %d iterations per hand, average equity: %.2f%% (±%.2f%%)",
+			result.Samples/len(equities), result.Equity, result.Margin())
+		return equities, result, nil
 	} else {
 		// 従来のExhaustive法を使用
 		if config.EnableParallelProcessing {
 			// 並列処理が有効な場合は並列計算関数を使用
 			log.Printf("Using exhaustive equity calculation with parallel processing")
-			equities, err := pkrlib.CalculateHandVsRangeEquityParallel(yourHand, formattedOpponentHands, flop)
-			return equities, 0, err
+			equities, result, err := pkrlib.CalculateHandVsWeightedRangeEquityParallelContext(ctx, yourHand, opponentRange, flop, nil, progress)
+			if err != nil {
+				return nil, pkrlib.EquityResult{}, partialResultError(result, len(equities), err)
+			}
+			return equities, result, nil
 		} else {
 			// 並列処理が無効な場合は非並列計算関数を使用
 			// 注: pkrlib.CalculateHandVsRangeEquityという非並列版の関数が存在しない場合は、
 			// 並列版の関数を使用します
 			log.Printf("Using exhaustive equity calculation")
-			equities, err := pkrlib.CalculateHandVsRangeEquityParallel(yourHand, formattedOpponentHands, flop)
-			return equities, 0, err
+			equities, result, err := pkrlib.CalculateHandVsWeightedRangeEquityParallelContext(ctx, yourHand, opponentRange, flop, nil, progress)
+			if err != nil {
+				return nil, pkrlib.EquityResult{}, partialResultError(result, len(equities), err)
+			}
+			return equities, result, nil
 		}
 	}
 }
+
+// calculateScenarioResult はシナリオの問題のエクイティを計算し、保存用の結果を作成する
+// ダブルボードのシナリオはcalculateDoubleBoardEquityで計算し、ハンドクラスの内訳とブロッカーの解説は作成しない（1つのフロップでは分類できないため）
+func calculateScenarioResult(ctx context.Context, scenario Scenario, heroHand string, opponentRange pkrlib.WeightedRange, flop []poker.Card, secondFlop []poker.Card, config *BatchConfig, rng *rand.Rand, progress pkrlib.ProgressFunc) (EquityResult, error) {
+	result := EquityResult{
+		Scenario:   scenario,
+		Variant:    scenario.Variant(),
+		HeroHand:   heroHand,
+		Flop:       flop,
+		SecondFlop: secondFlop,
+	}
+
+	var err error
+	if scenario.DoubleBoard {
+		result.Equities, result.Stats, err = calculateDoubleBoardEquity(ctx, scenario.Variant(), heroHand, opponentRange, flop, secondFlop, config, rng, progress)
+	} else {
+		result.Equities, result.Stats, err = calculateEquity(ctx, scenario.Variant(), heroHand, opponentRange, flop, config, rng, progress)
+	}
+	if err != nil {
+		return EquityResult{}, err
+	}
+
+	// 頻度で重み付けした平均エクイティ
+	result.AverageEquity = result.Stats.Equity
+	if !scenario.DoubleBoard {
+		result.HandClassBreakdown = calculateHandClassBreakdown(heroHand, opponentRange, flop, result.Equities)
+		result.BlockerInsight = calculateBlockerInsight(heroHand, opponentRange, flop)
+	}
+	return result, nil
+}
+
+// calculateDoubleBoardEquity はダブルボードのエクイティ（獲得ポットの期待値）を計算する
+// 2つのボードの全数計算は組み合わせが多すぎるため、相手ハンドごとにモンテカルロで計算する
+// イテレーション数はMonteCarloModeに従い、ctxの期限切れで打ち切られた場合はエラーを返す
+func calculateDoubleBoardEquity(ctx context.Context, variant pkrlib.Variant, heroHand string, opponentRange pkrlib.WeightedRange, flop []poker.Card, secondFlop []poker.Card, config *BatchConfig, rng *rand.Rand, progress pkrlib.ProgressFunc) (map[string]float64, pkrlib.EquityResult, error) {
+	yourHand, err := pkrlib.ParseHand(heroHand)
+	if err != nil {
+		return nil, pkrlib.EquityResult{}, fmt.Errorf("invalid hero hand format: %v", err)
+	}
+	if err := variant.ValidateHand(yourHand); err != nil {
+		return nil, pkrlib.EquityResult{}, fmt.Errorf("invalid hero hand format: %s (%v)", heroHand, err)
+	}
+
+	var iterations int
+	switch config.MonteCarloMode {
+	case "FAST":
+		iterations = pkrlib.FAST_ITERATIONS
+	case "ACCURATE":
+		iterations = pkrlib.ACCURATE_ITERATIONS
+	default:
+		iterations = pkrlib.NORMAL_ITERATIONS
+	}
+	log.Printf("Using double board Monte Carlo equity calculation (mode: %s)", config.MonteCarloMode)
+
+	equities, result, err := pkrlib.CalculateHandVsRangeDoubleBoardEquityMonteCarloContext(ctx, yourHand, opponentRange, flop, secondFlop, nil, iterations, rng, progress)
+	if err != nil {
+		return nil, pkrlib.EquityResult{}, partialResultError(result, len(equities), err)
+	}
+	return equities, result, nil
+}
+
+// calculateHandClassBreakdown は相手レンジをハンドクラスに分類し、クラスごとの割合とヒーローのエクイティを集計します
+// オマハ以外のゲームタイプや計算できなかった場合はnilを返します（クイズは内訳なしで保存する）
+func calculateHandClassBreakdown(heroHand string, opponentRange pkrlib.WeightedRange, flop []poker.Card, equities map[string]float64) []pkrlib.HandClassBreakdown {
+	yourHand, err := pkrlib.ParseHand(heroHand)
+	if err != nil {
+		log.Printf("Warning: Failed to parse hero hand for hand class breakdown: %v", err)
+		return nil
+	}
+	variant, err := pkrlib.DetectVariant(yourHand)
+	if err != nil || !variant.IsOmaha() {
+		return nil
+	}
+
+	breakdown, err := pkrlib.CalculateHandClassBreakdown(yourHand, opponentRange, flop, equities)
+	if err != nil {
+		log.Printf("Warning: Failed to calculate hand class breakdown: %v", err)
+		return nil
+	}
+	for _, entry := range breakdown {
+		log.Printf("  %-20s %5.1f%% of range, hero equity %.2f%%", entry.Class, entry.Frequency, entry.Equity)
+	}
+	return breakdown
+}
+
+// calculateBlockerInsight はヒーローのハンドによる相手レンジのブロッカーを分析し、クイズの解説に加える文を返します
+// オマハ以外のゲームタイプ、計算できなかった場合、特にブロックしている分類がない場合は空文字列を返します
+func calculateBlockerInsight(heroHand string, opponentRange pkrlib.WeightedRange, flop []poker.Card) string {
+	yourHand, err := pkrlib.ParseHand(heroHand)
+	if err != nil {
+		log.Printf("Warning: Failed to parse hero hand for blocker analysis: %v", err)
+		return ""
+	}
+	variant, err := pkrlib.DetectVariant(yourHand)
+	if err != nil || !variant.IsOmaha() {
+		return ""
+	}
+
+	analysis, err := pkrlib.CalculateBlockers(yourHand, opponentRange, flop)
+	if err != nil {
+		log.Printf("Warning: Failed to calculate blockers: %v", err)
+		return ""
+	}
+	insight := analysis.Insight()
+	if insight != "" {
+		log.Printf("  Blocker insight: %s", insight)
+	}
+	return insight
+}
+
+// scenarioPot はクイズのコール判断に使用するフロップのポットとエフェクティブスタック（BB）を返します
+// 100BBスタートで、オマハはポットサイズのオープン（3.5BB）と3ベット、Hold'emは2.5BBのオープンと11BBの3ベットを想定した代表値です
+func scenarioPot(scenario Scenario) (pot float64, stack float64) {
+	switch {
+	case strings.Contains(scenario.PresetName, "3BP"):
+		return 22.5, 89
+	case scenario.Variant() == pkrlib.VariantHoldem:
+		return 5.5, 97.5
+	default:
+		return 7.5, 96.5
+	}
+}
+
+// calculateCallDecision は「相手のポットサイズのベットにコールするかフォールドするか」のクイズの正解を作成します
+// 相手はレンジ全体でベットし、ヒーローはコール後にエクイティをすべて実現すると仮定します（レーキはプリセットのmidrake）
+// コール後もスタックが残るため、この仮定は近似です（結果のAllInがfalseになります）
+// 計算できなかった場合はnilを返します（クイズはコール判断なしで保存する）
+func calculateCallDecision(scenario Scenario, equity float64) *pkrlib.CallDecision {
+	pot, stack := scenarioPot(scenario)
+	decision, err := pkrlib.DecideCall(pkrlib.PotSizedBet(pot, stack, pkrlib.MidRake), equity)
+	if err != nil {
+		log.Printf("Warning: Failed to calculate call decision for scenario %s: %v", scenario.Name, err)
+		return nil
+	}
+	log.Printf("  Pot-sized bet (%.1fBB into %.1fBB): %s (call EV %+.2fBB, break-even equity %.2f%%)",
+		decision.Bet, decision.Pot, decision.Action, decision.CallEV, decision.BreakEvenEquity)
+	return &decision
+}
+
+// 計算が打ち切られた場合に、完了したハンド数をエラーメッセージに含める
+func partialResultError(result pkrlib.EquityResult, completedHands int, err error) error {
+	if !result.Partial {
+		return err
+	}
+	return fmt.Errorf("calculation stopped after %d hands: %w", completedHands, err)
+}
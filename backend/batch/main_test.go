@@ -1,13 +1,18 @@
 package main
 
 import (
+	"context"
+	"math"
 	"math/rand"
 	"runtime"
+	"strings"
 	"sync"
 	"testing"
 	"time"
 
 	"github.com/chehsunliu/poker"
+
+	pkrlib "equity-distribution-backend/pkg/poker"
 )
 
 // モックのBatchConfig
@@ -145,3 +150,308 @@ func TestScenarioProcessing(t *testing.T) {
 		t.Logf("Mock scenario: %s, Mock config: %v", mockScenario.Name, mockConfig)
 	})
 }
+
+// 同じシードから同じ問題が生成されることのテスト
+func TestSeededQuizGeneration(t *testing.T) {
+	config := &BatchConfig{DataDir: "../data", Seed: 12345}
+	date := time.Date(2024, 6, 5, 0, 0, 0, 0, time.UTC)
+	scenario := scenarios[0]
+
+	// テストケース1: シナリオごとのシードは決定的で、シナリオ名・日付によって異なる
+	t.Run("Scenario seeds", func(t *testing.T) {
+		seed := scenarioSeed(config.Seed, date, scenario.Name)
+		if seed != scenarioSeed(config.Seed, date, scenario.Name) {
+			t.Error("Expected the same seed for the same scenario")
+		}
+		if seed == scenarioSeed(config.Seed, date, scenarios[1].Name) {
+			t.Error("Expected different seeds for different scenarios")
+		}
+		if seed == scenarioSeed(config.Seed, date.AddDate(0, 0, 1), scenario.Name) {
+			t.Error("Expected different seeds for different dates")
+		}
+	})
+
+	// テストケース2: 同じシードからは同じヒーローハンド・フロップ・エクイティになる
+	t.Run("Regenerate quiz", func(t *testing.T) {
+		generate := func() (string, string, float64) {
+			rng := rand.New(rand.NewSource(scenarioSeed(config.Seed, date, scenario.Name)))
+			heroHand, opponentRange, flop := generateHandsAndFlop(scenario, config, rng)
+			// 計算時間を抑えるため、ヒーローハンド・フロップと重複しないハンドのうち先頭20ハンドのみを使用
+			heroCards, _ := pkrlib.ParseHand(heroHand)
+			var subset pkrlib.WeightedRange
+			for _, hand := range opponentRange {
+				if hand.Weight > 0 && !pkrlib.HasCardDuplicates(heroCards, hand.Cards, flop) && len(subset) < 20 {
+					subset = append(subset, hand)
+				}
+			}
+			_, stats, err := calculateEquity(context.Background(), scenario.Variant(), heroHand, subset, flop, config, rng, nil)
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			return heroHand, pkrlib.GenerateBoardString(flop), stats.Equity
+		}
+
+		heroHand, flop, equity := generate()
+		regeneratedHand, regeneratedFlop, regeneratedEquity := generate()
+		if heroHand != regeneratedHand || flop != regeneratedFlop || equity != regeneratedEquity {
+			t.Errorf("Expected identical quiz, got %s/%s/%.4f and %s/%s/%.4f",
+				heroHand, flop, equity, regeneratedHand, regeneratedFlop, regeneratedEquity)
+		}
+	})
+}
+
+// ハンドクラスの内訳がクイズと一緒に保存できる形で計算されることのテスト
+func TestCalculateHandClassBreakdown(t *testing.T) {
+	flop := []poker.Card{poker.NewCard("9c"), poker.NewCard("5h"), poker.NewCard("2d")}
+
+	// テストケース1: オマハのレンジはクラスごとの割合の合計が100%になる
+	t.Run("PLO range", func(t *testing.T) {
+		var opponentRange pkrlib.WeightedRange
+		for _, hand := range []struct {
+			cards  string
+			weight float64
+		}{{"9s9dKdQd", 1.0}, {"5s5dKcQh", 0.5}, {"KsJh8c4c", 1.0}} {
+			cards, err := pkrlib.ParseHand(hand.cards)
+			if err != nil {
+				t.Fatalf("Invalid hand: %v", err)
+			}
+			opponentRange = append(opponentRange, pkrlib.WeightedHand{Cards: cards, Weight: hand.weight})
+		}
+		equities := map[string]float64{"9s9dKdQd": 10, "5s5dKcQh": 16, "KsJh8c4c": 80}
+
+		breakdown := calculateHandClassBreakdown("AsAdKhQc", opponentRange, flop, equities)
+		if len(breakdown) != 2 {
+			t.Fatalf("Expected 2 classes, got %+v", breakdown)
+		}
+		total := 0.0
+		for _, entry := range breakdown {
+			total += entry.Frequency
+		}
+		if math.Abs(total-100) > 1e-9 {
+			t.Errorf("Expected frequencies to sum to 100%%, got %.4f%%", total)
+		}
+	})
+
+	// テストケース2: Hold'emは内訳なし
+	t.Run("Hold'em range", func(t *testing.T) {
+		opponentRange := pkrlib.NewUniformRange([][]poker.Card{{poker.NewCard("Ks"), poker.NewCard("Kd")}})
+		if breakdown := calculateHandClassBreakdown("AsAd", opponentRange, flop, map[string]float64{"KsKd": 10}); breakdown != nil {
+			t.Errorf("Expected no breakdown for Hold'em, got %+v", breakdown)
+		}
+	})
+}
+
+// ブロッカーの解説がクイズと一緒に保存できる形で作成されることのテスト
+func TestCalculateBlockerInsight(t *testing.T) {
+	flop := []poker.Card{poker.NewCard("9c"), poker.NewCard("5h"), poker.NewCard("2h")}
+
+	// テストケース1: ナッツフラッシュドローのAhを持つヒーローはナッツフラッシュドローを多くブロックする
+	t.Run("PLO range", func(t *testing.T) {
+		var opponentRange pkrlib.WeightedRange
+		for _, hand := range []string{"AhKhQsJs", "Ah3hTs8d", "KhQhTc8c", "9s9dKcQd", "Td8s7s6d", "JdJsTdTs"} {
+			cards, err := pkrlib.ParseHand(hand)
+			if err != nil {
+				t.Fatalf("Invalid hand: %v", err)
+			}
+			opponentRange = append(opponentRange, pkrlib.WeightedHand{Cards: cards, Weight: 1.0})
+		}
+
+		insight := calculateBlockerInsight("AhAsKdQc", opponentRange, flop)
+		if !strings.Contains(insight, "nut flush draws") {
+			t.Errorf("Expected an insight about nut flush draws, got %q", insight)
+		}
+	})
+
+	// テストケース2: Hold'emは解説なし
+	t.Run("Hold'em range", func(t *testing.T) {
+		opponentRange := pkrlib.NewUniformRange([][]poker.Card{{poker.NewCard("Ks"), poker.NewCard("Kd")}})
+		if insight := calculateBlockerInsight("AsAd", opponentRange, flop); insight != "" {
+			t.Errorf("Expected no insight for Hold'em, got %q", insight)
+		}
+	})
+}
+
+// ポットサイズのベットに対するコール判断がシナリオのポットで作成されることのテスト
+func TestCalculateCallDecision(t *testing.T) {
+	var srp, threeBet, holdem Scenario
+	for _, scenario := range scenarios {
+		switch scenario.Name {
+		case "SRP UTG vs BB":
+			srp = scenario
+		case "3BP BTN vs UTG":
+			threeBet = scenario
+		case "NLHE SRP UTG vs BB":
+			holdem = scenario
+		}
+	}
+	if srp.Name == "" || threeBet.Name == "" || holdem.Name == "" {
+		t.Fatalf("Scenarios not found")
+	}
+
+	// テストケース1: シングルレイズポットと3ベットポットでポットが異なる
+	if pot, _ := scenarioPot(srp); pot != 7.5 {
+		t.Errorf("Expected SRP pot 7.5BB, got %v", pot)
+	}
+	if pot, _ := scenarioPot(threeBet); pot != 22.5 {
+		t.Errorf("Expected 3BP pot 22.5BB, got %v", pot)
+	}
+	if pot, _ := scenarioPot(holdem); pot != 5.5 {
+		t.Errorf("Expected NLHE SRP pot 5.5BB, got %v", pot)
+	}
+
+	// テストケース2: ポットサイズのベットにはレーキ込みで約35%のエクイティが必要
+	for _, tt := range []struct {
+		equity float64
+		want   pkrlib.Action
+	}{{60, pkrlib.ActionCall}, {30, pkrlib.ActionFold}} {
+		decision := calculateCallDecision(srp, tt.equity)
+		if decision == nil {
+			t.Fatalf("Expected a call decision for equity %.1f%%", tt.equity)
+		}
+		if decision.Bet != decision.Pot || decision.Rake != pkrlib.MidRake {
+			t.Errorf("Expected a pot-sized bet with midrake, got %+v", decision.BetSpot)
+		}
+		// コール後もスタックが残るため、エクイティをすべて実現する仮定は近似
+		if decision.AllIn {
+			t.Errorf("Expected a pot-sized bet not to be an all-in, got %+v", decision)
+		}
+		if decision.Action != tt.want {
+			t.Errorf("Equity %.1f%%: expected %s, got %s", tt.equity, tt.want, decision.Action)
+		}
+	}
+
+	// テストケース3: 計算できない場合はnil
+	if decision := calculateCallDecision(srp, math.NaN()); decision != nil {
+		t.Errorf("Expected no decision for NaN equity, got %+v", decision)
+	}
+}
+
+// ダブルボードのシナリオの問題生成とエクイティ計算のテスト
+func TestDoubleBoardScenario(t *testing.T) {
+	config := &BatchConfig{DataDir: "../data", Seed: 12345, MonteCarloMode: "FAST"}
+	date := time.Date(2024, 6, 5, 0, 0, 0, 0, time.UTC)
+	var scenario Scenario
+	for _, s := range scenarios {
+		if s.DoubleBoard && s.Variant() == pkrlib.VariantPLO4 {
+			scenario = s
+		}
+	}
+	if scenario.Name == "" {
+		t.Fatal("Expected a PLO4 double board scenario")
+	}
+
+	// テストケース1: 無効な場合はダブルボードのシナリオを計算しない
+	t.Run("Disabled by default", func(t *testing.T) {
+		for _, s := range availableScenarios(config) {
+			if s.DoubleBoard {
+				t.Errorf("Expected %s to be skipped", s.Name)
+			}
+		}
+		if secondFlop := generateSecondFlop(scenarios[0], "AsAdKhQc", nil, rand.New(rand.NewSource(1))); secondFlop != nil {
+			t.Errorf("Expected no second flop for a single board scenario, got %v", secondFlop)
+		}
+	})
+
+	// テストケース2: 2つ目のフロップはヒーローハンド・1つ目のフロップと重複せず、獲得ポットの期待値を計算する
+	t.Run("Generate and calculate", func(t *testing.T) {
+		rng := rand.New(rand.NewSource(scenarioSeed(config.Seed, date, scenario.Name)))
+		heroHand, opponentRange, flop := generateHandsAndFlop(scenario, config, rng)
+		secondFlop := generateSecondFlop(scenario, heroHand, flop, rng)
+		heroCards, _ := pkrlib.ParseHand(heroHand)
+		if len(secondFlop) != 3 || pkrlib.HasCardDuplicates(heroCards, flop, secondFlop) {
+			t.Fatalf("Expected a distinct second flop, got %s / %s", pkrlib.GenerateBoardString(flop), pkrlib.GenerateBoardString(secondFlop))
+		}
+
+		// 計算時間を抑えるため、先頭5ハンドのみを使用
+		var subset pkrlib.WeightedRange
+		for _, hand := range opponentRange {
+			if hand.Weight > 0 && !pkrlib.HasCardDuplicates(heroCards, hand.Cards, flop, secondFlop) && len(subset) < 5 {
+				subset = append(subset, hand)
+			}
+		}
+		result, err := calculateScenarioResult(context.Background(), scenario, heroHand, subset, flop, secondFlop, config, rng, nil)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if len(result.Equities) != len(subset) || result.AverageEquity <= 0 || result.AverageEquity >= 100 {
+			t.Errorf("Expected equities for %d hands, got %+v", len(subset), result)
+		}
+		if result.HandClassBreakdown != nil {
+			t.Errorf("Expected no hand class breakdown for double board, got %+v", result.HandClassBreakdown)
+		}
+		if want := pkrlib.GenerateBoardString(flop) + " / " + pkrlib.GenerateBoardString(secondFlop); result.BoardString() != want {
+			t.Errorf("Expected board string %s, got %s", want, result.BoardString())
+		}
+	})
+}
+
+// テクスチャの条件を満たすフロップが生成されることのテスト
+func TestFlopTextureGeneration(t *testing.T) {
+	config := &BatchConfig{DataDir: "../data", Seed: 12345, FlopTexture: "monotone,connected"}
+	date := time.Date(2024, 6, 5, 0, 0, 0, 0, time.UTC)
+	scenario := scenarios[0]
+	filter, err := pkrlib.ParseBoardTextureFilter(config.FlopTexture)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	rng := rand.New(rand.NewSource(scenarioSeed(config.Seed, date, scenario.Name)))
+	heroHand, _, flop := generateHandsAndFlop(scenario, config, rng)
+	heroCards, _ := pkrlib.ParseHand(heroHand)
+	texture, err := pkrlib.ClassifyBoardTexture(flop)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if !filter.Matches(texture) || pkrlib.HasCardDuplicates(heroCards, flop) {
+		t.Errorf("Expected a monotone connected flop without hero cards, got %s (%s)", pkrlib.GenerateBoardString(flop), texture)
+	}
+
+	// 同じシードからは同じフロップになる
+	rng = rand.New(rand.NewSource(scenarioSeed(config.Seed, date, scenario.Name)))
+	_, _, regenerated := generateHandsAndFlop(scenario, config, rng)
+	if pkrlib.GenerateBoardString(regenerated) != pkrlib.GenerateBoardString(flop) {
+		t.Errorf("Expected identical flops, got %s and %s", pkrlib.GenerateBoardString(flop), pkrlib.GenerateBoardString(regenerated))
+	}
+}
+
+// Hold'emのシナリオの問題生成とエクイティ計算のテスト
+func TestHoldemScenario(t *testing.T) {
+	config := &BatchConfig{DataDir: "../data", Seed: 12345}
+	date := time.Date(2024, 6, 5, 0, 0, 0, 0, time.UTC)
+	var scenario Scenario
+	for _, s := range scenarios {
+		if s.Variant() == pkrlib.VariantHoldem {
+			scenario = s
+			break
+		}
+	}
+	if scenario.Name == "" {
+		t.Fatal("Expected a Hold'em scenario")
+	}
+
+	found := false
+	for _, s := range availableScenarios(config) {
+		found = found || s.Name == scenario.Name
+	}
+	if !found {
+		t.Fatalf("Expected %s to have range data", scenario.Name)
+	}
+
+	rng := rand.New(rand.NewSource(scenarioSeed(config.Seed, date, scenario.Name)))
+	heroHand, opponentRange, flop := generateHandsAndFlop(scenario, config, rng)
+	heroCards, err := pkrlib.ParseHand(heroHand)
+	if err != nil || len(heroCards) != 2 || pkrlib.HasCardDuplicates(heroCards, flop) {
+		t.Fatalf("Expected a Hold'em hand and a distinct flop, got %s / %s", heroHand, pkrlib.GenerateBoardString(flop))
+	}
+
+	result, err := calculateScenarioResult(context.Background(), scenario, heroHand, opponentRange, flop, nil, config, rng, nil)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if result.Variant != pkrlib.VariantHoldem || len(result.Equities) == 0 || result.AverageEquity <= 0 || result.AverageEquity >= 100 {
+		t.Errorf("Expected a Hold'em result, got %+v", result.Stats)
+	}
+	if result.HandClassBreakdown != nil {
+		t.Errorf("Expected no hand class breakdown for Hold'em, got %+v", result.HandClassBreakdown)
+	}
+}
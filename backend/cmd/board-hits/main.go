@@ -0,0 +1,110 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/chehsunliu/poker"
+
+	"equity-distribution-backend/pkg/fileio"
+	pkrlib "equity-distribution-backend/pkg/poker"
+)
+
+// プリセットのアグレッサーとディフェンダーのレンジについて、
+// フロップでセット以上・トップペア・ナッツフラッシュドローなどに該当する割合を並べて表示する
+// -heroを指定した場合は、ヒーローのハンドがディフェンダーのレンジの分類ごとのハンドをどれだけブロックするかを表示する
+func main() {
+	preset := flag.String("preset", "", "Preset name, e.g. \"SRP BB call vs UTG open\"")
+	dataDir := flag.String("data", "data", "Directory of the range CSV files")
+	flopStr := flag.String("flop", "", "Flop cards, e.g. \"Ks7d2c\"")
+	heroStr := flag.String("hero", "", "Hero hand for the blocker analysis against the defender range, e.g. \"AhAsKdQc\"")
+	jsonOutput := flag.Bool("json", false, "Print the result as JSON")
+	flag.Parse()
+
+	if *preset == "" || *flopStr == "" {
+		printUsage()
+		os.Exit(1)
+	}
+
+	flop, err := pkrlib.ParseBoard(*flopStr)
+	if err != nil {
+		log.Fatalf("Invalid flop: %v", err)
+	}
+	aggressorRange, err := fileio.LoadWeightedAggressorRangeFromPreset(*preset, *dataDir)
+	if err != nil {
+		log.Fatalf("Failed to load aggressor range: %v", err)
+	}
+	defenderRange, err := fileio.LoadWeightedOpponentRangeFromPreset(*preset, *dataDir)
+	if err != nil {
+		log.Fatalf("Failed to load defender range: %v", err)
+	}
+
+	if *heroStr != "" {
+		printBlockers(*heroStr, defenderRange, flop, *jsonOutput)
+		return
+	}
+
+	comparison, err := pkrlib.CompareBoardHits(aggressorRange, defenderRange, flop)
+	if err != nil {
+		log.Fatalf("Failed to calculate board hits: %v", err)
+	}
+
+	if *jsonOutput {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(comparison); err != nil {
+			log.Fatalf("Failed to encode result: %v", err)
+		}
+		return
+	}
+
+	fmt.Printf("%s on %s\n\n", *preset, pkrlib.GenerateBoardString(flop))
+	fmt.Printf("%-24s %10s %10s %8s\n", "hit", "aggressor", "defender", "diff")
+	for _, c := range comparison {
+		fmt.Printf("%-24s %9.2f%% %9.2f%% %+8.2f\n", c.Hit, c.Aggressor, c.Defender, c.Difference())
+	}
+}
+
+// ヒーローのハンドによるディフェンダーのレンジのブロッカーを表示する
+func printBlockers(heroStr string, defenderRange pkrlib.WeightedRange, flop []poker.Card, jsonOutput bool) {
+	hero, err := pkrlib.ParseHand(heroStr)
+	if err != nil {
+		log.Fatalf("Invalid hero hand: %v", err)
+	}
+	analysis, err := pkrlib.CalculateBlockers(hero, defenderRange, flop)
+	if err != nil {
+		log.Fatalf("Failed to calculate blockers: %v", err)
+	}
+
+	if jsonOutput {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(analysis); err != nil {
+			log.Fatalf("Failed to encode result: %v", err)
+		}
+		return
+	}
+
+	fmt.Printf("%s on %s: blocks %.2f%% of the defender range\n\n", pkrlib.GenerateBoardString(hero), pkrlib.GenerateBoardString(flop), analysis.BlockedPercent)
+	fmt.Printf("%-24s %10s %10s %10s\n", "hit", "blocked", "unblocked", "remaining")
+	for _, stat := range analysis.Stats {
+		fmt.Printf("%-24s %9.2f%% %9.2f%% %9.2f%%\n", stat.Hit, stat.BlockedPercent, stat.UnblockedFrequency, stat.Frequency)
+	}
+	if insight := analysis.Insight(); insight != "" {
+		fmt.Printf("\n%s\n", insight)
+	}
+}
+
+func printUsage() {
+	fmt.Println("Usage: go run cmd/board-hits/main.go -preset <preset> -flop <flop> [options]")
+	fmt.Println("")
+	fmt.Println("Options:")
+	flag.PrintDefaults()
+	fmt.Println("")
+	fmt.Println("Example:")
+	fmt.Println("  go run cmd/board-hits/main.go -preset \"SRP BB call vs UTG open\" -flop Ks7d2c")
+	fmt.Println("  go run cmd/board-hits/main.go -preset \"SRP BB call vs UTG open\" -flop 9c5h2h -hero AhAsKdQc")
+}
@@ -0,0 +1,144 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"equity-distribution-backend/pkg/db"
+	pkrlib "equity-distribution-backend/pkg/poker"
+)
+
+func main() {
+	if len(os.Args) < 2 {
+		printUsage()
+		os.Exit(1)
+	}
+
+	command := os.Args[1]
+
+	// 接続情報は環境変数（POSTGRES_*）から取得する
+	pgDB, err := db.GetPostgresConnection(db.PostgresConfig{
+		Host:     "localhost",
+		Port:     5432,
+		User:     "postgres",
+		Password: "postgres",
+		DBName:   "plo_equity",
+	})
+	if err != nil {
+		log.Fatalf("Failed to connect to PostgreSQL: %v", err)
+	}
+	defer pgDB.Close()
+
+	switch command {
+	case "stats":
+		summaries, err := db.GetEquityCacheSummary(pgDB)
+		if err != nil {
+			log.Fatalf("Failed to get equity cache stats: %v", err)
+		}
+		if len(summaries) == 0 {
+			fmt.Println("Equity cache is empty")
+			return
+		}
+
+		var total int64
+		fmt.Printf("%-12s %12s  %-25s  %-25s\n", "GAME TYPE", "ENTRIES", "OLDEST USE", "NEWEST USE")
+		for _, summary := range summaries {
+			fmt.Printf("%-12s %12d  %-25s  %-25s\n", summary.GameType, summary.Entries,
+				summary.OldestUsedAt.Format(time.RFC3339), summary.NewestUsedAt.Format(time.RFC3339))
+			total += summary.Entries
+		}
+		fmt.Printf("%-12s %12d\n", "TOTAL", total)
+
+	case "get":
+		if len(os.Args) < 5 {
+			fmt.Println("Please specify hero hand, villain hand and board")
+			printUsage()
+			os.Exit(1)
+		}
+		heroHand, err := pkrlib.ParseHand(os.Args[2])
+		if err != nil {
+			log.Fatalf("Invalid hero hand: %v", err)
+		}
+		villainHand, err := pkrlib.ParseHand(os.Args[3])
+		if err != nil {
+			log.Fatalf("Invalid villain hand: %v", err)
+		}
+		board, err := pkrlib.ParseBoard(os.Args[4])
+		if err != nil {
+			log.Fatalf("Invalid board: %v", err)
+		}
+		variant, err := pkrlib.DetectVariant(heroHand, villainHand)
+		if err != nil {
+			log.Fatalf("Invalid hands: %v", err)
+		}
+
+		key := pkrlib.EquityCacheKey(variant, heroHand, villainHand, board)
+		results, err := db.NewPostgresEquityCacheStore(pgDB).LoadEquities([]string{key})
+		if err != nil {
+			log.Fatalf("Failed to load equity cache: %v", err)
+		}
+		result, ok := results[key]
+		if !ok {
+			fmt.Printf("Not cached: %s\n", key)
+			os.Exit(1)
+		}
+		fmt.Printf("Key:    %s\n", key)
+		fmt.Printf("Equity: %.2f%% (wins %d, ties %d, losses %d)\n", result.Equity, result.Wins, result.Ties, result.Losses)
+
+	case "prune":
+		flags := flag.NewFlagSet("prune", flag.ExitOnError)
+		maxEntries := flags.Int("max-entries", 0, "Keep only the most recently used entries (0: no limit)")
+		unusedFor := flags.Duration("unused-for", 0, "Delete entries not used for this long, e.g. 720h (0: keep)")
+		flags.Parse(os.Args[2:])
+
+		if *maxEntries <= 0 && *unusedFor <= 0 {
+			fmt.Println("Please specify -max-entries or -unused-for")
+			printUsage()
+			os.Exit(1)
+		}
+
+		var unusedSince time.Time
+		if *unusedFor > 0 {
+			unusedSince = time.Now().Add(-*unusedFor)
+		}
+		deleted, err := db.PruneEquityCache(pgDB, *maxEntries, unusedSince)
+		if err != nil {
+			log.Fatalf("Failed to prune equity cache: %v", err)
+		}
+		fmt.Printf("Deleted %d entries\n", deleted)
+
+	case "clear":
+		deleted, err := db.ClearEquityCache(pgDB)
+		if err != nil {
+			log.Fatalf("Failed to clear equity cache: %v", err)
+		}
+		fmt.Printf("Deleted %d entries\n", deleted)
+
+	default:
+		fmt.Printf("Unknown command: %s\n", command)
+		printUsage()
+		os.Exit(1)
+	}
+}
+
+func printUsage() {
+	fmt.Println("Usage: go run cmd/equity-cache/main.go <command> [args]")
+	fmt.Println("")
+	fmt.Println("Commands:")
+	fmt.Println("  stats                              Show entry counts per game type")
+	fmt.Println("  get <hero> <villain> <board>       Show the cached result of a matchup")
+	fmt.Println("  prune [-max-entries n] [-unused-for d]")
+	fmt.Println("                                     Delete old entries")
+	fmt.Println("  clear                              Delete all entries")
+	fmt.Println("")
+	fmt.Println("Environment variables:")
+	fmt.Println("  POSTGRES_HOST     (default: localhost)")
+	fmt.Println("  POSTGRES_PORT     (default: 5432)")
+	fmt.Println("  POSTGRES_USER     (default: postgres)")
+	fmt.Println("  POSTGRES_PASSWORD (default: postgres)")
+	fmt.Println("  POSTGRES_DBNAME   (default: plo_equity)")
+	fmt.Println("  POSTGRES_SSLMODE  (default: disable)")
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"time"
+
+	"github.com/chehsunliu/poker"
+
+	"equity-distribution-backend/pkg/fileio"
+	pkrlib "equity-distribution-backend/pkg/poker"
+)
+
+// プリセットのアグレッサーのレンジ（utg_open.csvなど）の全ハンドについて、
+// 対応するディフェンダーのレンジに対するプリフロップのオールインのエクイティを事前計算し、CSVファイルに保存する
+func main() {
+	preset := flag.String("preset", "", "Preset name, e.g. \"SRP BB call vs UTG open\"")
+	dataDir := flag.String("data", "data", "Directory of the range CSV files")
+	outPath := flag.String("out", "", "Output CSV file")
+	targetMargin := flag.Float64("target-margin", pkrlib.DefaultPreflopEquityConfig().TargetMargin, "Target half-width of the 95% confidence interval per hand (%)")
+	maxIterations := flag.Int("max-iterations", pkrlib.DefaultPreflopEquityConfig().MaxIterations, "Maximum iterations per hand")
+	seed := flag.Int64("seed", 0, "Random seed (0: derived from the current time)")
+	flag.Parse()
+
+	if *preset == "" || *outPath == "" {
+		printUsage()
+		os.Exit(1)
+	}
+
+	aggressorRange, err := fileio.LoadWeightedAggressorRangeFromPreset(*preset, *dataDir)
+	if err != nil {
+		log.Fatalf("Failed to load aggressor range: %v", err)
+	}
+	defenderRange, err := fileio.LoadWeightedOpponentRangeFromPreset(*preset, *dataDir)
+	if err != nil {
+		log.Fatalf("Failed to load defender range: %v", err)
+	}
+
+	if *seed == 0 {
+		*seed = time.Now().UnixNano()
+	}
+	log.Printf("Using random seed: %d", *seed)
+
+	config := pkrlib.DefaultPreflopEquityConfig()
+	config.TargetMargin = *targetMargin
+	config.MaxIterations = *maxIterations
+
+	hands := make([][]poker.Card, len(aggressorRange))
+	for i, hand := range aggressorRange {
+		hands[i] = hand.Cards
+	}
+
+	// Ctrl+Cで中断した場合は保存しない
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	log.Printf("Calculating %d hands against %d defending hands (%s)", len(hands), len(defenderRange), *preset)
+	results, err := pkrlib.CalculatePreflopEquityTableContext(ctx, hands, defenderRange, config, pkrlib.NewRand(*seed), newProgressLogger())
+	if err != nil {
+		log.Fatalf("Failed to calculate preflop equity: %v", err)
+	}
+
+	entries := make([]fileio.PreflopTableEntry, len(hands))
+	for i, result := range results {
+		entries[i] = fileio.PreflopTableEntry{
+			Hand:     pkrlib.GenerateBoardString(hands[i]),
+			Equity:   result.Equity,
+			StdError: result.StdError,
+			Samples:  result.Samples,
+		}
+	}
+	if err := fileio.SavePreflopTable(*outPath, entries); err != nil {
+		log.Fatalf("Failed to save preflop table: %v", err)
+	}
+	log.Printf("Saved %d hands to %s", len(entries), *outPath)
+}
+
+// 進捗を10%ごとにログ出力するコールバックを作成する
+func newProgressLogger() pkrlib.ProgressFunc {
+	nextPercent := 10.0
+	return func(p pkrlib.Progress) {
+		if p.Percent() < nextPercent {
+			return
+		}
+		for nextPercent <= p.Percent() {
+			nextPercent += 10
+		}
+		log.Printf("%d/%d hands (%.0f%%), elapsed %v, ETA %v",
+			p.Done, p.Total, p.Percent(), p.Elapsed.Round(time.Second), p.ETA.Round(time.Second))
+	}
+}
+
+func printUsage() {
+	fmt.Println("Usage: go run cmd/preflop-table/main.go -preset <preset> -out <file> [options]")
+	fmt.Println("")
+	fmt.Println("Options:")
+	flag.PrintDefaults()
+	fmt.Println("")
+	fmt.Println("Example:")
+	fmt.Println("  go run cmd/preflop-table/main.go -preset \"SRP BB call vs UTG open\" -out utg_open_vs_bb_call.csv")
+}
@@ -7,6 +7,7 @@ import (
 
 	"github.com/chehsunliu/poker"
 	"equity-distribution-backend/pkg/image"
+	pkrlib "equity-distribution-backend/pkg/poker"
 )
 
 func main() {
@@ -25,7 +26,7 @@ func main() {
 		poker.NewCard("8h"),
 	}
 
-	err := image.GenerateDailyQuizImage(date, scenario4card, heroHand4card, flop4card)
+	err := image.GenerateDailyQuizImage(date, scenario4card, pkrlib.VariantPLO4, heroHand4card, flop4card)
 	if err != nil {
 		log.Fatalf("4-card PLO画像生成エラー: %v", err)
 	}
@@ -41,7 +42,7 @@ func main() {
 		poker.NewCard("7d"),
 	}
 
-	err = image.GenerateDailyQuizImage(date, scenario5card, heroHand5card, flop5card)
+	err = image.GenerateDailyQuizImage(date, scenario5card, pkrlib.VariantPLO5, heroHand5card, flop5card)
 	if err != nil {
 		log.Fatalf("5-card PLO画像生成エラー: %v", err)
 	}
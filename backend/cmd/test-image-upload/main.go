@@ -6,14 +6,13 @@ import (
 	"log"
 	"os"
 	"path/filepath"
-	"strings"
 	"time"
 
-	"github.com/chehsunliu/poker"
 	"github.com/joho/godotenv"
 
 	"equity-distribution-backend/pkg/db"
 	"equity-distribution-backend/pkg/image"
+	pkrlib "equity-distribution-backend/pkg/poker"
 	"equity-distribution-backend/pkg/storage"
 )
 
@@ -30,6 +29,7 @@ func main() {
 		scenario   string
 		heroHand   string
 		flopStr    string
+		gameType   string
 		logFile    string
 		pgHost     string
 		pgPort     int
@@ -43,6 +43,7 @@ func main() {
 	flag.StringVar(&scenario, "scenario", "PLO5 SRP UTG vs BB", "Scenario name (default: 'PLO5 SRP UTG vs BB')")
 	flag.StringVar(&heroHand, "hand", "AsKsQsJsTs", "Hero hand (default: 'AsKsQsJsTs' for 5-card PLO)")
 	flag.StringVar(&flopStr, "flop", "2c3d4h", "Flop cards (default: '2c3d4h')")
+	flag.StringVar(&gameType, "game-type", "", "Game type (e.g. '6card_plo', default: detect from hero hand)")
 	flag.StringVar(&logFile, "log", "", "Log file (empty for stdout)")
 	flag.StringVar(&pgHost, "pg-host", getEnvOrDefault("POSTGRES_HOST", "localhost"), "PostgreSQL host")
 	flag.IntVar(&pgPort, "pg-port", getEnvIntOrDefault("POSTGRES_PORT", 5432), "PostgreSQL port")
@@ -106,26 +107,36 @@ func main() {
 			if f, ok := result["flop"].(string); ok && f != "" {
 				flopStr = f
 			}
+			if g, ok := result["game_type"].(string); ok && g != "" {
+				gameType = g
+			}
 			log.Printf("Using data from database: scenario=%s, hero=%s, flop=%s", scenario, heroHand, flopStr)
 		}
 	}
 
 	// フロップをpoker.Card形式に変換
-	var flop []poker.Card
-	// フロップ文字列を解析（2文字ずつ処理）
-	for i := 0; i < len(flopStr); i += 2 {
-		if i+2 <= len(flopStr) {
-			cardStr := strings.ToUpper(flopStr[i:i+1]) + strings.ToLower(flopStr[i+1:i+2])
-			card := poker.NewCard(cardStr)
-			flop = append(flop, card)
-		}
+	flop, err := pkrlib.ParseBoard(flopStr)
+	if err != nil {
+		log.Fatalf("Invalid flop: %v", err)
 	}
 
-	// ゲームタイプを判定
-	gameTypeDir := "4card"
-	if len(heroHand) == 10 {
-		gameTypeDir = "5card"
+	// ヒーローハンドを解析
+	hand, err := pkrlib.ParseHand(heroHand)
+	if err != nil {
+		log.Fatalf("Invalid hero hand: %v", err)
+	}
+
+	// ゲームタイプを判定（DBのgame_typeがない場合はヒーローハンドの枚数から判定）
+	var variant pkrlib.Variant
+	if gameType != "" {
+		variant, err = pkrlib.VariantFromGameType(gameType)
+	} else {
+		variant, err = pkrlib.DetectVariant(hand)
+	}
+	if err != nil {
+		log.Fatalf("Error detecting game type: %v", err)
 	}
+	gameTypeDir := image.GameTypeDir(variant)
 
 	// 画像生成
 	log.Printf("Generating image...")
@@ -134,6 +145,7 @@ func main() {
 	err = image.GenerateDailyQuizImage(
 		targetDate,
 		scenario,
+		variant,
 		heroHand,
 		flop,
 	)
@@ -0,0 +1,5 @@
+-- 推定精度のカラムを削除
+ALTER TABLE daily_quiz_results DROP COLUMN exhaustive;
+ALTER TABLE daily_quiz_results DROP COLUMN samples_used;
+ALTER TABLE daily_quiz_results DROP COLUMN equity_margin;
+ALTER TABLE daily_quiz_results DROP COLUMN equity_std_error;
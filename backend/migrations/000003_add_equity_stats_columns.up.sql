@@ -0,0 +1,5 @@
+-- 平均エクイティの推定精度を保存するカラムを追加（既存データはNULL）
+ALTER TABLE daily_quiz_results ADD COLUMN equity_std_error DECIMAL(5,2);
+ALTER TABLE daily_quiz_results ADD COLUMN equity_margin DECIMAL(5,2);
+ALTER TABLE daily_quiz_results ADD COLUMN samples_used INTEGER;
+ALTER TABLE daily_quiz_results ADD COLUMN exhaustive BOOLEAN;
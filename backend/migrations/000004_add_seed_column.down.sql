@@ -0,0 +1,2 @@
+-- seedカラムを削除
+ALTER TABLE daily_quiz_results DROP COLUMN seed;
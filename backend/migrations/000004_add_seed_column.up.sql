@@ -0,0 +1,2 @@
+-- クイズの生成に使用した乱数シードを保存するカラムを追加（既存データはNULL）
+ALTER TABLE daily_quiz_results ADD COLUMN seed BIGINT;
@@ -0,0 +1,2 @@
+-- エクイティキャッシュテーブルを削除
+DROP TABLE IF EXISTS equity_cache;
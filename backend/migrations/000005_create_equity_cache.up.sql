@@ -0,0 +1,14 @@
+-- 全数計算したハンド同士のエクイティを保存するキャッシュテーブル
+-- キーはゲームタイプとスートを正準化した(ヒーロー, ボード, 相手)の組み合わせ
+CREATE TABLE IF NOT EXISTS equity_cache (
+    matchup_key VARCHAR(128) PRIMARY KEY,
+    game_type VARCHAR(20) NOT NULL,
+    wins INTEGER NOT NULL,
+    ties INTEGER NOT NULL,
+    losses INTEGER NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    last_used_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+-- 古いエントリを削除するためのインデックス
+CREATE INDEX IF NOT EXISTS idx_equity_cache_last_used_at ON equity_cache(last_used_at);
@@ -0,0 +1,2 @@
+-- hand_class_breakdownカラムを削除
+ALTER TABLE daily_quiz_results DROP COLUMN hand_class_breakdown;
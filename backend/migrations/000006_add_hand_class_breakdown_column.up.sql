@@ -0,0 +1,2 @@
+-- 相手レンジのハンドクラスごとの割合とヒーローのエクイティ（JSON）を保存するカラムを追加（既存データはNULL）
+ALTER TABLE daily_quiz_results ADD COLUMN hand_class_breakdown TEXT;
@@ -0,0 +1,2 @@
+-- second_flopカラムを削除
+ALTER TABLE daily_quiz_results DROP COLUMN second_flop;
@@ -0,0 +1,2 @@
+-- ダブルボードのクイズの2つ目のフロップを保存するカラムを追加（通常のクイズ・既存データはNULL）
+ALTER TABLE daily_quiz_results ADD COLUMN second_flop VARCHAR(255);
@@ -0,0 +1,2 @@
+-- board_textureカラムを削除
+ALTER TABLE daily_quiz_results DROP COLUMN board_texture;
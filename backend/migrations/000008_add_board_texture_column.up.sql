@@ -0,0 +1,2 @@
+-- フロップのテクスチャ（"two_tone,unpaired,connected,broadway"の形式）を保存するカラムを追加（既存データはNULL）
+ALTER TABLE daily_quiz_results ADD COLUMN board_texture VARCHAR(255);
@@ -0,0 +1,2 @@
+-- blocker_insightカラムを削除
+ALTER TABLE daily_quiz_results DROP COLUMN blocker_insight;
@@ -0,0 +1,2 @@
+-- ヒーローのハンドによる相手レンジのブロッカーの解説（"You block 38% of villain's nut flush draws ..."の形式）を保存するカラムを追加（既存データはNULL）
+ALTER TABLE daily_quiz_results ADD COLUMN blocker_insight TEXT;
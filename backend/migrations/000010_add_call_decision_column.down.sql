@@ -0,0 +1,2 @@
+-- call_decisionカラムを削除
+ALTER TABLE daily_quiz_results DROP COLUMN call_decision;
@@ -0,0 +1,2 @@
+-- 相手のポットサイズのベットにコールするかフォールドするかのクイズの正解（JSON）を保存するカラムを追加（既存データはNULL）
+ALTER TABLE daily_quiz_results ADD COLUMN call_decision TEXT;
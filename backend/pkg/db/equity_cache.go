@@ -0,0 +1,218 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+
+	pkrlib "equity-distribution-backend/pkg/poker"
+)
+
+// equityCacheBatchSize は1回のクエリで読み込むキャッシュキーの最大数です
+const equityCacheBatchSize = 1000
+
+// PostgresEquityCacheStore はequity_cacheテーブルに全数計算の結果を保存するpkrlib.EquityCacheStoreの実装です
+type PostgresEquityCacheStore struct {
+	db *sql.DB
+}
+
+// NewPostgresEquityCacheStore はequity_cacheテーブルを使うストアを作成します
+// テーブルのエントリ数は保存時には制限しないため、PruneEquityCacheで別途削除してください
+func NewPostgresEquityCacheStore(db *sql.DB) *PostgresEquityCacheStore {
+	return &PostgresEquityCacheStore{db: db}
+}
+
+// equityCacheGameType はキャッシュキーの先頭のgame_typeを取り出して検証します
+func equityCacheGameType(key string) (string, error) {
+	gameType, _, found := strings.Cut(key, ":")
+	if !found {
+		return "", fmt.Errorf("invalid equity cache key: %q", key)
+	}
+	if err := validateGameType(gameType); err != nil {
+		return "", err
+	}
+	return gameType, nil
+}
+
+// LoadEquities はキーに対応する保存済みの結果を読み込み、最後に使用された日時を更新します
+func (s *PostgresEquityCacheStore) LoadEquities(keys []string) (map[string]pkrlib.EquityResult, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	results := make(map[string]pkrlib.EquityResult)
+	for start := 0; start < len(keys); start += equityCacheBatchSize {
+		end := start + equityCacheBatchSize
+		if end > len(keys) {
+			end = len(keys)
+		}
+
+		rows, err := s.db.QueryContext(ctx, `
+			UPDATE equity_cache SET last_used_at = CURRENT_TIMESTAMP
+			WHERE matchup_key = ANY($1)
+			RETURNING matchup_key, wins, ties, losses
+		`, pq.Array(keys[start:end]))
+		if err != nil {
+			return nil, fmt.Errorf("failed to query equity cache: %v", err)
+		}
+
+		for rows.Next() {
+			var key string
+			var wins, ties, losses int
+			if err := rows.Scan(&key, &wins, &ties, &losses); err != nil {
+				rows.Close()
+				return nil, fmt.Errorf("failed to scan equity cache row: %v", err)
+			}
+			results[key] = pkrlib.NewExhaustiveEquityResult(wins, ties, losses)
+		}
+		err = rows.Err()
+		rows.Close()
+		if err != nil {
+			return nil, fmt.Errorf("error iterating equity cache rows: %v", err)
+		}
+	}
+	return results, nil
+}
+
+// SaveEquities は全数計算の結果を保存します（既に保存されているキーは更新しません）
+func (s *PostgresEquityCacheStore) SaveEquities(results map[string]pkrlib.EquityResult) error {
+	if len(results) == 0 {
+		return nil
+	}
+
+	// 保存順序を固定するためキーを並べる
+	keys := make([]string, 0, len(results))
+	for key := range results {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	gameTypes := make([]string, len(keys))
+	for i, key := range keys {
+		gameType, err := equityCacheGameType(key)
+		if err != nil {
+			return err
+		}
+		gameTypes[i] = gameType
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	// トランザクションを開始
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.PrepareContext(ctx, `
+		INSERT INTO equity_cache (matchup_key, game_type, wins, ties, losses)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (matchup_key) DO NOTHING
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to prepare statement: %v", err)
+	}
+	defer stmt.Close()
+
+	for i, key := range keys {
+		result := results[key]
+		if _, err := stmt.ExecContext(ctx, key, gameTypes[i], result.Wins, result.Ties, result.Losses); err != nil {
+			return fmt.Errorf("failed to insert equity cache entry %s: %v", key, err)
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %v", err)
+	}
+	return nil
+}
+
+// EquityCacheSummary はゲームタイプごとのエクイティキャッシュの統計情報です
+type EquityCacheSummary struct {
+	GameType     string
+	Entries      int64
+	OldestUsedAt time.Time // 最後に使用された日時が最も古いエントリの日時
+	NewestUsedAt time.Time // 最後に使用された日時が最も新しいエントリの日時
+}
+
+// GetEquityCacheSummary はゲームタイプごとのエントリ数と使用日時の範囲を取得します
+func GetEquityCacheSummary(db *sql.DB) ([]EquityCacheSummary, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	rows, err := db.QueryContext(ctx, `
+		SELECT game_type, COUNT(*), MIN(last_used_at), MAX(last_used_at)
+		FROM equity_cache
+		GROUP BY game_type
+		ORDER BY game_type
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query equity cache summary: %v", err)
+	}
+	defer rows.Close()
+
+	var summaries []EquityCacheSummary
+	for rows.Next() {
+		var summary EquityCacheSummary
+		if err := rows.Scan(&summary.GameType, &summary.Entries, &summary.OldestUsedAt, &summary.NewestUsedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan equity cache summary: %v", err)
+		}
+		summaries = append(summaries, summary)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating equity cache summary: %v", err)
+	}
+	return summaries, nil
+}
+
+// PruneEquityCache はエクイティキャッシュの古いエントリを削除し、削除した件数を返します
+// unusedSinceがゼロ値でない場合はそれより前から使用されていないエントリを削除し、
+// maxEntriesが正の場合は最後に使用された日時が新しい順にmaxEntries件だけを残します
+func PruneEquityCache(db *sql.DB, maxEntries int, unusedSince time.Time) (int64, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+
+	var deleted int64
+	if !unusedSince.IsZero() {
+		result, err := db.ExecContext(ctx, `DELETE FROM equity_cache WHERE last_used_at < $1`, unusedSince)
+		if err != nil {
+			return deleted, fmt.Errorf("failed to prune unused equity cache entries: %v", err)
+		}
+		n, _ := result.RowsAffected()
+		deleted += n
+	}
+
+	if maxEntries > 0 {
+		result, err := db.ExecContext(ctx, `
+			DELETE FROM equity_cache WHERE matchup_key IN (
+				SELECT matchup_key FROM equity_cache
+				ORDER BY last_used_at DESC, matchup_key
+				OFFSET $1
+			)
+		`, maxEntries)
+		if err != nil {
+			return deleted, fmt.Errorf("failed to prune equity cache to %d entries: %v", maxEntries, err)
+		}
+		n, _ := result.RowsAffected()
+		deleted += n
+	}
+	return deleted, nil
+}
+
+// ClearEquityCache はエクイティキャッシュの全エントリを削除し、削除した件数を返します
+func ClearEquityCache(db *sql.DB) (int64, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+
+	result, err := db.ExecContext(ctx, `DELETE FROM equity_cache`)
+	if err != nil {
+		return 0, fmt.Errorf("failed to clear equity cache: %v", err)
+	}
+	return result.RowsAffected()
+}
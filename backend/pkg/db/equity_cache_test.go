@@ -0,0 +1,92 @@
+package db
+
+import (
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+
+	pkrlib "equity-distribution-backend/pkg/poker"
+)
+
+func TestPostgresEquityCacheStore(t *testing.T) {
+	// SQLモックを作成
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	store := NewPostgresEquityCacheStore(db)
+
+	t.Run("保存済みの結果を読み込む", func(t *testing.T) {
+		rows := sqlmock.NewRows([]string{"matchup_key", "wins", "ties", "losses"}).
+			AddRow("holdem:KsAs:2s7hTd:JhQh", 700, 20, 270)
+		mock.ExpectQuery(`UPDATE equity_cache SET last_used_at = CURRENT_TIMESTAMP`).
+			WithArgs(sqlmock.AnyArg()).
+			WillReturnRows(rows)
+
+		results, err := store.LoadEquities([]string{"holdem:KsAs:2s7hTd:JhQh", "holdem:KsAs:2s7hTd:JcQc"})
+
+		// アサーション
+		assert.NoError(t, err)
+		assert.Len(t, results, 1)
+		assert.Equal(t, pkrlib.NewExhaustiveEquityResult(700, 20, 270), results["holdem:KsAs:2s7hTd:JhQh"])
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("結果を保存する", func(t *testing.T) {
+		mock.ExpectBegin()
+		prepare := mock.ExpectPrepare(`INSERT INTO equity_cache \(matchup_key, game_type, wins, ties, losses\)`)
+		prepare.ExpectExec().
+			WithArgs("4card_plo:a", "4card_plo", 10, 1, 5).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		prepare.ExpectExec().
+			WithArgs("holdem:b", "holdem", 3, 0, 7).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		err := store.SaveEquities(map[string]pkrlib.EquityResult{
+			"holdem:b":    pkrlib.NewExhaustiveEquityResult(3, 0, 7),
+			"4card_plo:a": pkrlib.NewExhaustiveEquityResult(10, 1, 5),
+		})
+
+		// アサーション
+		assert.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("不明なゲームタイプのキーはエラー", func(t *testing.T) {
+		err := store.SaveEquities(map[string]pkrlib.EquityResult{
+			"omaha:a": pkrlib.NewExhaustiveEquityResult(1, 0, 0),
+		})
+
+		// アサーション
+		assert.Error(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestPruneEquityCache(t *testing.T) {
+	// SQLモックを作成
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	unusedSince := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	t.Run("古いエントリと上限を超えたエントリを削除する", func(t *testing.T) {
+		mock.ExpectExec(`DELETE FROM equity_cache WHERE last_used_at < \$1`).
+			WithArgs(unusedSince).
+			WillReturnResult(sqlmock.NewResult(0, 3))
+		mock.ExpectExec(`DELETE FROM equity_cache WHERE matchup_key IN`).
+			WithArgs(500).
+			WillReturnResult(sqlmock.NewResult(0, 2))
+
+		deleted, err := PruneEquityCache(db, 500, unusedSince)
+
+		// アサーション
+		assert.NoError(t, err)
+		assert.Equal(t, int64(5), deleted)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
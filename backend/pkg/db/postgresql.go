@@ -11,6 +11,8 @@ import (
 	"time"
 
 	_ "github.com/lib/pq"
+
+	pkrlib "equity-distribution-backend/pkg/poker"
 )
 
 // PostgresConfig はPostgreSQL接続設定を表します
@@ -30,7 +32,63 @@ type DailyQuizResult struct {
 	Flop          string
 	Result        string
 	AverageEquity float64
-	GameType      string
+	GameType      string // pkrlib.Variant.GameType()の値（"4card_plo"など）
+	// Stats は平均エクイティの推定精度（標準誤差・95%信頼区間・サンプル数）です
+	// nilの場合は推定精度のカラムをNULLとして保存します
+	Stats *pkrlib.EquityResult
+	// Seed はクイズの生成に使用したバッチの乱数シードです（0の場合はNULLとして保存）
+	// 同じ日付・シードでバッチを実行すると同じクイズを再生成できます
+	Seed int64
+	// HandClassBreakdown は相手レンジのハンドクラスごとの割合とヒーローのエクイティです（nilの場合はNULLとして保存）
+	HandClassBreakdown []pkrlib.HandClassBreakdown
+	// SecondFlop はダブルボードのクイズの2つ目のフロップです（通常のクイズは空文字列で、NULLとして保存）
+	SecondFlop string
+	// BoardTexture はフロップのテクスチャです（pkrlib.BoardTexture.String()の値。空文字列はNULLとして保存）
+	BoardTexture string
+	// BlockerInsight はヒーローのハンドによる相手レンジのブロッカーの解説です（pkrlib.BlockerAnalysis.Insight()の値。空文字列はNULLとして保存）
+	BlockerInsight string
+	// CallDecision は相手のポットサイズのベットにコールするかフォールドするかのクイズの正解です（nilの場合はNULLとして保存）
+	CallDecision *pkrlib.CallDecision
+}
+
+// equityStatsArgs は推定精度のカラム（equity_std_error, equity_margin, samples_used, exhaustive）の値を返します
+func equityStatsArgs(stats *pkrlib.EquityResult) []interface{} {
+	if stats == nil {
+		return []interface{}{nil, nil, nil, nil}
+	}
+	return []interface{}{stats.StdError, stats.Margin(), stats.Samples, stats.Exhaustive}
+}
+
+// handClassBreakdownArg はhand_class_breakdownカラムに保存するJSON文字列を返します（nilの場合はNULL）
+func handClassBreakdownArg(breakdown []pkrlib.HandClassBreakdown) (interface{}, error) {
+	if breakdown == nil {
+		return nil, nil
+	}
+	data, err := json.Marshal(breakdown)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal hand class breakdown: %v", err)
+	}
+	return string(data), nil
+}
+
+// callDecisionArg はcall_decisionカラムに保存するJSON文字列を返します（nilの場合はNULL）
+func callDecisionArg(decision *pkrlib.CallDecision) (interface{}, error) {
+	if decision == nil {
+		return nil, nil
+	}
+	data, err := json.Marshal(decision)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal call decision: %v", err)
+	}
+	return string(data), nil
+}
+
+// validateGameType はgame_typeが定義済みのゲームタイプかを検証します
+func validateGameType(gameType string) error {
+	if _, err := pkrlib.VariantFromGameType(gameType); err != nil {
+		return fmt.Errorf("invalid game_type: %v", err)
+	}
+	return nil
 }
 
 // GetPostgresConnection はPostgreSQLへの接続を確立します
@@ -63,6 +121,10 @@ func GetPostgresConnection(config PostgresConfig) (*sql.DB, error) {
 
 // InsertDailyQuizResult は計算結果をPostgreSQLに保存します
 func InsertDailyQuizResult(db *sql.DB, date time.Time, scenario string, heroHand string, flop string, result string, averageEquity float64, gameType string) error {
+	if err := validateGameType(gameType); err != nil {
+		return err
+	}
+
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
@@ -106,7 +168,8 @@ func GetDailyQuizResultsByDate(db *sql.DB, date time.Time) ([]map[string]interfa
 	defer cancel()
 
 	query := `
-		SELECT id, date, scenario, hero_hand, flop, result, average_equity, created_at
+		SELECT id, date, scenario, hero_hand, flop, result, average_equity, game_type,
+			equity_std_error, equity_margin, samples_used, exhaustive, seed, hand_class_breakdown, second_flop, board_texture, blocker_insight, call_decision, created_at
 		FROM daily_quiz_results
 		WHERE date = $1
 		ORDER BY id
@@ -122,11 +185,16 @@ func GetDailyQuizResultsByDate(db *sql.DB, date time.Time) ([]map[string]interfa
 	for rows.Next() {
 		var id int
 		var date time.Time
-		var scenario, heroHand, flop, result string
+		var scenario, heroHand, flop, result, gameType string
 		var averageEquity float64
+		var stdError, margin sql.NullFloat64
+		var samplesUsed, seed sql.NullInt64
+		var exhaustive sql.NullBool
+		var breakdown, secondFlop, boardTexture, blockerInsight, callDecision sql.NullString
 		var createdAt time.Time
 
-		if err := rows.Scan(&id, &date, &scenario, &heroHand, &flop, &result, &averageEquity, &createdAt); err != nil {
+		if err := rows.Scan(&id, &date, &scenario, &heroHand, &flop, &result, &averageEquity, &gameType,
+			&stdError, &margin, &samplesUsed, &exhaustive, &seed, &breakdown, &secondFlop, &boardTexture, &blockerInsight, &callDecision, &createdAt); err != nil {
 			return nil, fmt.Errorf("failed to scan row: %v", err)
 		}
 
@@ -139,16 +207,43 @@ func GetDailyQuizResultsByDate(db *sql.DB, date time.Time) ([]map[string]interfa
 			}
 		}
 
-		// 結果をマップに格納
+		// ハンドクラスごとの内訳（保存されていない場合はnil）
+		var breakdownData interface{}
+		if breakdown.Valid && breakdown.String != "" {
+			if err := json.Unmarshal([]byte(breakdown.String), &breakdownData); err != nil {
+				log.Printf("Warning: Failed to parse hand class breakdown JSON: %v", err)
+			}
+		}
+
+		// コール判断（保存されていない場合はnil）
+		var callDecisionData interface{}
+		if callDecision.Valid && callDecision.String != "" {
+			if err := json.Unmarshal([]byte(callDecision.String), &callDecisionData); err != nil {
+				log.Printf("Warning: Failed to parse call decision JSON: %v", err)
+			}
+		}
+
+		// 結果をマップに格納（推定精度が保存されていない場合はnil）
 		item := map[string]interface{}{
-			"id":             id,
-			"date":           date.Format("2006-01-02"),
-			"scenario":       scenario,
-			"hero_hand":      heroHand,
-			"flop":           flop,
-			"result":         resultData,
-			"average_equity": averageEquity,
-			"created_at":     createdAt,
+			"id":                   id,
+			"date":                 date.Format("2006-01-02"),
+			"scenario":             scenario,
+			"hero_hand":            heroHand,
+			"flop":                 flop,
+			"result":               resultData,
+			"average_equity":       averageEquity,
+			"game_type":            gameType,
+			"equity_std_error":     nullableValue(stdError.Float64, stdError.Valid),
+			"equity_margin":        nullableValue(margin.Float64, margin.Valid),
+			"samples_used":         nullableValue(int(samplesUsed.Int64), samplesUsed.Valid),
+			"exhaustive":           nullableValue(exhaustive.Bool, exhaustive.Valid),
+			"seed":                 nullableValue(seed.Int64, seed.Valid),
+			"hand_class_breakdown": breakdownData,
+			"second_flop":          nullableValue(secondFlop.String, secondFlop.Valid),
+			"board_texture":        nullableValue(boardTexture.String, boardTexture.Valid),
+			"blocker_insight":      nullableValue(blockerInsight.String, blockerInsight.Valid),
+			"call_decision":        callDecisionData,
+			"created_at":           createdAt,
 		}
 		results = append(results, item)
 	}
@@ -160,6 +255,14 @@ func GetDailyQuizResultsByDate(db *sql.DB, date time.Time) ([]map[string]interfa
 	return results, nil
 }
 
+// nullableValue はNULLでない場合のみ値を返し、NULLの場合はnilを返します
+func nullableValue(value interface{}, valid bool) interface{} {
+	if !valid {
+		return nil
+	}
+	return value
+}
+
 // GetLatestDailyQuizResultDate はdaily_quiz_resultsテーブルの最新日付を取得します
 func GetLatestDailyQuizResultDate(db *sql.DB) (time.Time, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
@@ -180,6 +283,11 @@ func InsertDailyQuizResultsBatch(db *sql.DB, results []DailyQuizResult) error {
 	if len(results) == 0 {
 		return nil
 	}
+	for i, result := range results {
+		if err := validateGameType(result.GameType); err != nil {
+			return fmt.Errorf("record %d: %v", i+1, err)
+		}
+	}
 
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
@@ -193,8 +301,9 @@ func InsertDailyQuizResultsBatch(db *sql.DB, results []DailyQuizResult) error {
 
 	// バッチINSERT用のステートメントを準備
 	stmt, err := tx.PrepareContext(ctx, `
-		INSERT INTO daily_quiz_results (date, scenario, hero_hand, flop, result, average_equity, game_type)
-		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		INSERT INTO daily_quiz_results (date, scenario, hero_hand, flop, result, average_equity, game_type,
+			equity_std_error, equity_margin, samples_used, exhaustive, seed, hand_class_breakdown, second_flop, board_texture, blocker_insight, call_decision)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17)
 	`)
 	if err != nil {
 		return fmt.Errorf("failed to prepare statement: %v", err)
@@ -203,7 +312,22 @@ func InsertDailyQuizResultsBatch(db *sql.DB, results []DailyQuizResult) error {
 
 	// 各レコードを挿入
 	for i, result := range results {
-		_, err = stmt.ExecContext(ctx, result.Date, result.Scenario, result.HeroHand, result.Flop, result.Result, result.AverageEquity, result.GameType)
+		args := []interface{}{result.Date, result.Scenario, result.HeroHand, result.Flop, result.Result, result.AverageEquity, result.GameType}
+		args = append(args, equityStatsArgs(result.Stats)...)
+		args = append(args, nullableValue(result.Seed, result.Seed != 0))
+		breakdown, err := handClassBreakdownArg(result.HandClassBreakdown)
+		if err != nil {
+			return fmt.Errorf("record %d: %v", i+1, err)
+		}
+		args = append(args, breakdown, nullableValue(result.SecondFlop, result.SecondFlop != ""))
+		args = append(args, nullableValue(result.BoardTexture, result.BoardTexture != ""))
+		args = append(args, nullableValue(result.BlockerInsight, result.BlockerInsight != ""))
+		callDecision, err := callDecisionArg(result.CallDecision)
+		if err != nil {
+			return fmt.Errorf("record %d: %v", i+1, err)
+		}
+		args = append(args, callDecision)
+		_, err = stmt.ExecContext(ctx, args...)
 		if err != nil {
 			return fmt.Errorf("failed to insert record %d: %v", i+1, err)
 		}
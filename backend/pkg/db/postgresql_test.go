@@ -7,6 +7,8 @@ import (
 
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/stretchr/testify/assert"
+
+	pkrlib "equity-distribution-backend/pkg/poker"
 )
 
 func TestInsertDailyQuizResult(t *testing.T) {
@@ -68,6 +70,15 @@ func TestInsertDailyQuizResult(t *testing.T) {
 		assert.NoError(t, err)
 		assert.NoError(t, mock.ExpectationsWereMet())
 	})
+	t.Run("未定義のgame_typeはエラー", func(t *testing.T) {
+		// クエリは実行されない
+		err := InsertDailyQuizResult(db, testDate, scenario, heroHand, flop, result, averageEquity, "7card_plo")
+
+		// アサーション
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "invalid game_type")
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
 }
 
 func TestGetDailyQuizResultsByDate(t *testing.T) {
@@ -82,11 +93,11 @@ func TestGetDailyQuizResultsByDate(t *testing.T) {
 
 	t.Run("成功ケース", func(t *testing.T) {
 		// モックのレスポンスを設定
-		rows := sqlmock.NewRows([]string{"id", "date", "scenario", "hero_hand", "flop", "result", "average_equity", "created_at"}).
-			AddRow(1, testDate, "SRP UTG vs BB", "AhAsKdQc", "2d3cJc", `[{"villain_hand":"KsKcQdJh","equity":0.35}]`, 65.50, createdAt).
-			AddRow(2, testDate, "SRP BTN vs BB", "KhKsQdJc", "5h6s7c", `[{"villain_hand":"AhAsKdQc","equity":0.65}]`, 45.20, createdAt)
+		rows := sqlmock.NewRows([]string{"id", "date", "scenario", "hero_hand", "flop", "result", "average_equity", "game_type", "equity_std_error", "equity_margin", "samples_used", "exhaustive", "seed", "hand_class_breakdown", "second_flop", "board_texture", "blocker_insight", "call_decision", "created_at"}).
+			AddRow(1, testDate, "SRP UTG vs BB", "AhAsKdQc", "2d3cJc", `[{"villain_hand":"KsKcQdJh","equity":0.35}]`, 65.50, "4card_plo", 0.40, 0.78, 1200, false, 12345, `[{"class":"set","combos":12,"frequency":8.5,"equity":22.1,"samples":12}]`, "9c8h4d", "two_tone,unpaired,disconnected,broadway", "You block 38% of villain's nut flush draws (vs 12% of the whole range)", `{"pot":7.5,"bet":7.5,"stack":96.5,"rake":{"percent":5,"cap":3},"equity":65.5,"call_ev":6.93,"break_even_equity":35.09,"all_in":false,"action":"call"}`, createdAt).
+			AddRow(2, testDate, "SRP BTN vs BB", "KhKsQdJc", "5h6s7c", `[{"villain_hand":"AhAsKdQc","equity":0.65}]`, 45.20, "4card_plo", nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, createdAt)
 
-		mock.ExpectQuery(`SELECT id, date, scenario, hero_hand, flop, result, average_equity, created_at FROM daily_quiz_results WHERE date = \$1 ORDER BY id`).
+		mock.ExpectQuery(`SELECT id, date, scenario, hero_hand, flop, result, average_equity, game_type, equity_std_error, equity_margin, samples_used, exhaustive, seed, hand_class_breakdown, second_flop, board_texture, blocker_insight, call_decision, created_at FROM daily_quiz_results WHERE date = \$1 ORDER BY id`).
 			WithArgs(testDate).
 			WillReturnRows(rows)
 
@@ -104,15 +115,37 @@ func TestGetDailyQuizResultsByDate(t *testing.T) {
 		assert.Equal(t, "AhAsKdQc", results[0]["hero_hand"])
 		assert.Equal(t, "2d3cJc", results[0]["flop"])
 		assert.Equal(t, 65.50, results[0]["average_equity"])
+		assert.Equal(t, "4card_plo", results[0]["game_type"])
+		assert.Equal(t, 0.40, results[0]["equity_std_error"])
+		assert.Equal(t, 0.78, results[0]["equity_margin"])
+		assert.Equal(t, 1200, results[0]["samples_used"])
+		assert.Equal(t, false, results[0]["exhaustive"])
+		assert.Equal(t, int64(12345), results[0]["seed"])
+		assert.Equal(t, []interface{}{map[string]interface{}{"class": "set", "combos": 12.0, "frequency": 8.5, "equity": 22.1, "samples": 12.0}}, results[0]["hand_class_breakdown"])
+		assert.Equal(t, "9c8h4d", results[0]["second_flop"])
+		assert.Equal(t, "two_tone,unpaired,disconnected,broadway", results[0]["board_texture"])
+		assert.Equal(t, "You block 38% of villain's nut flush draws (vs 12% of the whole range)", results[0]["blocker_insight"])
+		assert.Equal(t, "call", results[0]["call_decision"].(map[string]interface{})["action"])
+		assert.Equal(t, 35.09, results[0]["call_decision"].(map[string]interface{})["break_even_equity"])
+
+		// 推定精度が保存されていないレコードはnil
+		assert.Nil(t, results[1]["equity_margin"])
+		assert.Nil(t, results[1]["exhaustive"])
+		assert.Nil(t, results[1]["seed"])
+		assert.Nil(t, results[1]["hand_class_breakdown"])
+		assert.Nil(t, results[1]["second_flop"])
+		assert.Nil(t, results[1]["board_texture"])
+		assert.Nil(t, results[1]["blocker_insight"])
+		assert.Nil(t, results[1]["call_decision"])
 
 		assert.NoError(t, mock.ExpectationsWereMet())
 	})
 
 	t.Run("結果が見つからない場合", func(t *testing.T) {
 		// 空の結果を返すモックを設定
-		rows := sqlmock.NewRows([]string{"id", "date", "scenario", "hero_hand", "flop", "result", "average_equity", "created_at"})
+		rows := sqlmock.NewRows([]string{"id", "date", "scenario", "hero_hand", "flop", "result", "average_equity", "game_type", "equity_std_error", "equity_margin", "samples_used", "exhaustive", "seed", "hand_class_breakdown", "second_flop", "board_texture", "blocker_insight", "call_decision", "created_at"})
 
-		mock.ExpectQuery(`SELECT id, date, scenario, hero_hand, flop, result, average_equity, created_at FROM daily_quiz_results WHERE date = \$1 ORDER BY id`).
+		mock.ExpectQuery(`SELECT id, date, scenario, hero_hand, flop, result, average_equity, game_type, equity_std_error, equity_margin, samples_used, exhaustive, seed, hand_class_breakdown, second_flop, board_texture, blocker_insight, call_decision, created_at FROM daily_quiz_results WHERE date = \$1 ORDER BY id`).
 			WithArgs(testDate).
 			WillReturnRows(rows)
 
@@ -127,7 +160,7 @@ func TestGetDailyQuizResultsByDate(t *testing.T) {
 
 	t.Run("データベースエラー", func(t *testing.T) {
 		// エラーを返すクエリを設定
-		mock.ExpectQuery(`SELECT id, date, scenario, hero_hand, flop, result, average_equity, created_at FROM daily_quiz_results WHERE date = \$1 ORDER BY id`).
+		mock.ExpectQuery(`SELECT id, date, scenario, hero_hand, flop, result, average_equity, game_type, equity_std_error, equity_margin, samples_used, exhaustive, seed, hand_class_breakdown, second_flop, board_texture, blocker_insight, call_decision, created_at FROM daily_quiz_results WHERE date = \$1 ORDER BY id`).
 			WithArgs(testDate).
 			WillReturnError(sql.ErrConnDone)
 
@@ -140,4 +173,40 @@ func TestGetDailyQuizResultsByDate(t *testing.T) {
 		assert.Contains(t, err.Error(), "failed to query data from PostgreSQL")
 		assert.NoError(t, mock.ExpectationsWereMet())
 	})
-}
\ No newline at end of file
+}
+func TestInsertDailyQuizResultsBatch(t *testing.T) {
+	// SQLモックを作成
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	testDate := time.Date(2024, 6, 5, 0, 0, 0, 0, time.UTC)
+	stats := &pkrlib.EquityResult{Equity: 65.5, StdError: 0.5, Samples: 1200}
+	results := []DailyQuizResult{
+		{Date: testDate, Scenario: "SRP UTG vs BB", HeroHand: "AhAsKdQc", Flop: "2d3cJc", Result: "[]", AverageEquity: 65.5, GameType: "4card_plo", Stats: stats, Seed: 12345,
+			HandClassBreakdown: []pkrlib.HandClassBreakdown{{Class: pkrlib.HandClassSet, Combos: 12, Frequency: 8.5, Equity: 22.1, Samples: 12}}, SecondFlop: "9c8h4d", BoardTexture: "two_tone,unpaired,disconnected,broadway",
+			BlockerInsight: "You block 38% of villain's nut flush draws (vs 12% of the whole range)",
+			CallDecision: &pkrlib.CallDecision{BetSpot: pkrlib.BetSpot{Pot: 10, Bet: 10, Stack: 100}, Equity: 40, CallEV: 2, BreakEvenEquity: 33.5, Action: pkrlib.ActionCall}},
+		{Date: testDate, Scenario: "SRP BTN vs BB", HeroHand: "KhKsQdJc", Flop: "5h6s7c", Result: "[]", AverageEquity: 45.2, GameType: "4card_plo"},
+	}
+
+	t.Run("推定精度・乱数シード・ハンドクラスの内訳・2つ目のフロップ・テクスチャ・ブロッカーの解説・コール判断が保存される", func(t *testing.T) {
+		mock.ExpectBegin()
+		prepare := mock.ExpectPrepare(`INSERT INTO daily_quiz_results \(date, scenario, hero_hand, flop, result, average_equity, game_type, equity_std_error, equity_margin, samples_used, exhaustive, seed, hand_class_breakdown, second_flop, board_texture, blocker_insight, call_decision\)`)
+		prepare.ExpectExec().
+			WithArgs(testDate, "SRP UTG vs BB", "AhAsKdQc", "2d3cJc", "[]", 65.5, "4card_plo", 0.5, stats.Margin(), 1200, false, int64(12345),
+				`[{"class":"set","combos":12,"frequency":8.5,"equity":22.1,"samples":12}]`, "9c8h4d", "two_tone,unpaired,disconnected,broadway", "You block 38% of villain's nut flush draws (vs 12% of the whole range)", `{"pot":10,"bet":10,"stack":100,"rake":{"percent":0,"cap":0},"equity":40,"call_ev":2,"break_even_equity":33.5,"all_in":false,"action":"call"}`).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		// 推定精度・シード・内訳・2つ目のフロップ・テクスチャ・ブロッカーの解説・コール判断がない場合はNULL
+		prepare.ExpectExec().
+			WithArgs(testDate, "SRP BTN vs BB", "KhKsQdJc", "5h6s7c", "[]", 45.2, "4card_plo", nil, nil, nil, nil, nil, nil, nil, nil, nil, nil).
+			WillReturnResult(sqlmock.NewResult(2, 1))
+		mock.ExpectCommit()
+
+		err := InsertDailyQuizResultsBatch(db, results)
+
+		// アサーション
+		assert.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
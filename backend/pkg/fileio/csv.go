@@ -4,7 +4,10 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"strconv"
 	"strings"
+
+	pkrlib "equity-distribution-backend/pkg/poker"
 )
 
 // LoadRangeFromCSV loads a range from a CSV file
@@ -50,72 +53,271 @@ func LoadRangeFromCSV(filePath string) (string, error) {
 	return strings.Join(hands, ","), nil
 }
 
+// LoadWeightedRangeFromCSV loads a range from a CSV file keeping each hand's frequency
+// "ACADAH2C@100" is loaded with weight 1.0, "ACADAH2C@1" with weight 0.01
+// Hands without a frequency are treated as 100%
+func LoadWeightedRangeFromCSV(filePath string) (pkrlib.WeightedRange, error) {
+	log.Printf("Loading weighted range from file: %s", filePath)
+
+	// CSVファイルを読み込む
+	content, err := os.ReadFile(filePath)
+	if err != nil {
+		log.Printf("Error reading CSV file: %v", err)
+		return nil, fmt.Errorf("failed to read CSV file: %v", err)
+	}
+
+	var weightedRange pkrlib.WeightedRange
+	for _, line := range strings.Split(string(content), "\n") {
+		for _, part := range strings.Split(line, ",") {
+			part = strings.TrimSpace(part)
+			if part == "" {
+				continue
+			}
+
+			weightedHand, err := parseWeightedHand(part)
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse hand %q in %s: %v", part, filePath, err)
+			}
+			weightedRange = append(weightedRange, weightedHand)
+		}
+	}
+
+	return weightedRange, nil
+}
+
+// LoadHoldemRangeFromFile loads a Hold'em range written in NLHE range notation ("QQ+,ATs+,AKo:0.5")
+// Lines starting with "#" are comments; the notation may span multiple lines
+func LoadHoldemRangeFromFile(filePath string) (pkrlib.WeightedRange, error) {
+	log.Printf("Loading Hold'em range from file: %s", filePath)
+
+	content, err := os.ReadFile(filePath)
+	if err != nil {
+		log.Printf("Error reading range file: %v", err)
+		return nil, fmt.Errorf("failed to read range file: %v", err)
+	}
+
+	// コメント行を除き、行をまたぐ表記をカンマでつなぐ
+	var lines []string
+	for _, line := range strings.Split(string(content), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		lines = append(lines, line)
+	}
+
+	weightedRange, err := pkrlib.ParseHoldemRange(strings.Join(lines, ","))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %v", filePath, err)
+	}
+	return weightedRange, nil
+}
+
+// parseWeightedHand は "ACADAH2C@100" 形式の文字列をWeightedHandに変換します
+func parseWeightedHand(s string) (pkrlib.WeightedHand, error) {
+	handStr := s
+	weight := 1.0
+
+	// @記号がある場合は、その後ろを頻度（%）として扱う
+	if idx := strings.Index(s, "@"); idx >= 0 {
+		handStr = s[:idx]
+		frequency, err := strconv.ParseFloat(s[idx+1:], 64)
+		if err != nil {
+			return pkrlib.WeightedHand{}, fmt.Errorf("invalid frequency: %v", err)
+		}
+		if frequency < 0 || frequency > 100 {
+			return pkrlib.WeightedHand{}, fmt.Errorf("frequency out of range: %v", frequency)
+		}
+		weight = frequency / 100
+	}
+
+	hand, err := pkrlib.ParseHand(handStr)
+	if err != nil {
+		return pkrlib.WeightedHand{}, err
+	}
+
+	return pkrlib.WeightedHand{Cards: hand, Weight: weight}, nil
+}
+
 // LoadOpponentRangeFromPreset loads opponent range from CSV file based on preset name
 func LoadOpponentRangeFromPreset(preset string, dataDir string) (string, error) {
-	var filePath string
-	var gameType string
-	
-	// プリセット名からゲームタイプを判定
-	if strings.HasPrefix(preset, "PLO5") {
-		gameType = "plo5"
-	} else {
-		gameType = "plo4"
+	filePath, err := opponentRangeFilePath(preset, dataDir)
+	if err != nil {
+		return "", err
 	}
-	
-	baseDir := fmt.Sprintf("%s/%s/six_handed_100bb_midrake", dataDir, gameType)
+	return loadPresetRangeString(preset, filePath)
+}
+
+// LoadWeightedOpponentRangeFromPreset loads opponent range with frequencies based on preset name
+// Every hand must match the preset's variant (e.g. 5 cards for "PLO5 ..." presets)
+func LoadWeightedOpponentRangeFromPreset(preset string, dataDir string) (pkrlib.WeightedRange, error) {
+	filePath, err := opponentRangeFilePath(preset, dataDir)
+	if err != nil {
+		return nil, err
+	}
+	return loadPresetRange(preset, filePath)
+}
+
+// holdemPresetPrefix はHold'emのプリセット名の接頭辞です
+const holdemPresetPrefix = "NLHE "
+
+// presetPrefix はゲームタイプのプリセット名の接頭辞を返します（4-card PLOは接頭辞なし）
+func presetPrefix(variant pkrlib.Variant) string {
+	switch variant {
+	case pkrlib.VariantPLO4:
+		return ""
+	case pkrlib.VariantHoldem:
+		return holdemPresetPrefix
+	}
+	return variant.String() + " "
+}
+
+// PresetVariant はプリセット名の接頭辞（"PLO5 ...", "PLO6 ...", "NLHE ..."）からゲームタイプを判定します
+// 接頭辞がない場合は4-card PLOとして扱います
+func PresetVariant(preset string) pkrlib.Variant {
+	for _, variant := range []pkrlib.Variant{pkrlib.VariantHoldem, pkrlib.VariantPLO5, pkrlib.VariantPLO6} {
+		if strings.HasPrefix(preset, presetPrefix(variant)) {
+			return variant
+		}
+	}
+	return pkrlib.VariantPLO4
+}
+
+// presetRangeFilePath はプリセットのゲームタイプのデータディレクトリにあるレンジファイルのパスを返します
+// nameは"srp/utg_open"のような拡張子なしの名前です。Hold'emのレンジ表記のファイルは拡張子なし、
+// ハンドを列挙したPLOのレンジは".csv"です
+func presetRangeFilePath(preset string, dataDir string, name string) string {
+	variant := PresetVariant(preset)
+	filePath := fmt.Sprintf("%s/%s/six_handed_100bb_midrake/%s", dataDir, variant.DataDir(), name)
+	if variant != pkrlib.VariantHoldem {
+		filePath += ".csv"
+	}
+	return filePath
+}
+
+// PresetDataExists はプリセットのOpponent・アグレッサー両方のレンジファイルが存在するかを返します
+func PresetDataExists(preset string, dataDir string) bool {
+	opponentPath, err := opponentRangeFilePath(preset, dataDir)
+	if err != nil {
+		return false
+	}
+	aggressorPath, err := aggressorRangeFilePath(preset, dataDir)
+	if err != nil {
+		return false
+	}
+	for _, path := range []string{opponentPath, aggressorPath} {
+		if _, err := os.Stat(path); err != nil {
+			return false
+		}
+	}
+	return true
+}
+
+// opponentRangeFilePath はプリセット名からOpponentレンジのファイルパスを返します
+// シナリオはゲームタイプの接頭辞を除いた名前で判定し、ファイルはゲームタイプのデータディレクトリから探します
+func opponentRangeFilePath(preset string, dataDir string) (string, error) {
+	var name string
 
 	// プリセット値に基づいてファイルパスを決定
-	switch preset {
-	case "SRP BB call vs UTG open", "PLO5 SRP BB call vs UTG open":
-		filePath = fmt.Sprintf("%s/srp/bb_call_vs_utg.csv", baseDir)
-	case "SRP BB call vs BTN open", "PLO5 SRP BB call vs BTN open":
-		filePath = fmt.Sprintf("%s/srp/bb_call_vs_btn.csv", baseDir)
-	case "SRP BTN call vs UTG open", "PLO5 SRP BTN call vs UTG open":
-		filePath = fmt.Sprintf("%s/srp/btn_call_vs_utg.csv", baseDir)
-	case "3BP UTG call vs BB 3bet", "PLO5 3BP UTG call vs BB 3bet":
-		filePath = fmt.Sprintf("%s/3bp/utg_call_vs_bb.csv", baseDir)
-	case "3BP UTG call vs BTN 3bet", "PLO5 3BP UTG call vs BTN 3bet":
-		filePath = fmt.Sprintf("%s/3bp/utg_call_vs_btn.csv", baseDir)
-	case "3BP BTN call vs BB 3bet", "PLO5 3BP BTN call vs BB 3bet":
-		filePath = fmt.Sprintf("%s/3bp/btn_call_vs_bb.csv", baseDir)
+	switch strings.TrimPrefix(preset, presetPrefix(PresetVariant(preset))) {
+	case "SRP BB call vs UTG open":
+		name = "srp/bb_call_vs_utg"
+	case "SRP BB call vs BTN open":
+		name = "srp/bb_call_vs_btn"
+	case "SRP BTN call vs UTG open":
+		name = "srp/btn_call_vs_utg"
+	case "3BP UTG call vs BB 3bet":
+		name = "3bp/utg_call_vs_bb"
+	case "3BP UTG call vs BTN 3bet":
+		name = "3bp/utg_call_vs_btn"
+	case "3BP BTN call vs BB 3bet":
+		name = "3bp/btn_call_vs_bb"
 	default:
 		return "", fmt.Errorf("unknown preset: %s", preset)
 	}
 
-	return LoadRangeFromCSV(filePath)
+	return presetRangeFilePath(preset, dataDir, name), nil
 }
 
 // LoadAggressorRangeFromPreset loads aggressor range from CSV file based on preset name
 func LoadAggressorRangeFromPreset(preset string, dataDir string) (string, error) {
-	var filePath string
-	var gameType string
-	
-	// プリセット名からゲームタイプを判定
-	if strings.HasPrefix(preset, "PLO5") {
-		gameType = "plo5"
+	filePath, err := aggressorRangeFilePath(preset, dataDir)
+	if err != nil {
+		return "", err
+	}
+	return loadPresetRangeString(preset, filePath)
+}
+
+// LoadWeightedAggressorRangeFromPreset loads aggressor range with frequencies based on preset name
+// Every hand must match the preset's variant (e.g. 5 cards for "PLO5 ..." presets)
+func LoadWeightedAggressorRangeFromPreset(preset string, dataDir string) (pkrlib.WeightedRange, error) {
+	filePath, err := aggressorRangeFilePath(preset, dataDir)
+	if err != nil {
+		return nil, err
+	}
+	return loadPresetRange(preset, filePath)
+}
+
+// loadPresetRange は頻度付きレンジを読み込み、全ハンドがプリセットのゲームタイプと一致するかを検証します
+// Hold'emのプリセットはNLHEのレンジ表記、それ以外はハンドを列挙したCSVとして読み込みます
+func loadPresetRange(preset string, filePath string) (pkrlib.WeightedRange, error) {
+	var weightedRange pkrlib.WeightedRange
+	var err error
+	if PresetVariant(preset) == pkrlib.VariantHoldem {
+		weightedRange, err = LoadHoldemRangeFromFile(filePath)
 	} else {
-		gameType = "plo4"
+		weightedRange, err = LoadWeightedRangeFromCSV(filePath)
+	}
+	if err != nil {
+		return nil, err
 	}
-	
-	baseDir := fmt.Sprintf("%s/%s/six_handed_100bb_midrake", dataDir, gameType)
+	if err := PresetVariant(preset).ValidateRange(weightedRange); err != nil {
+		return nil, fmt.Errorf("%s: %v", filePath, err)
+	}
+	return weightedRange, nil
+}
+
+// loadPresetRangeString はレンジをカンマ区切りのハンドの文字列として読み込みます
+// Hold'emのプリセットはレンジ表記を展開し、頻度が0のコンボを除いた "AsKd,AhKh,..." を返します
+func loadPresetRangeString(preset string, filePath string) (string, error) {
+	if PresetVariant(preset) != pkrlib.VariantHoldem {
+		return LoadRangeFromCSV(filePath)
+	}
+	weightedRange, err := loadPresetRange(preset, filePath)
+	if err != nil {
+		return "", err
+	}
+	hands := make([]string, 0, len(weightedRange))
+	for _, hand := range weightedRange {
+		if hand.Weight > 0 {
+			hands = append(hands, pkrlib.GenerateBoardString(hand.Cards))
+		}
+	}
+	return strings.Join(hands, ","), nil
+}
+
+// aggressorRangeFilePath はプリセット名からアグレッサー側レンジのファイルパスを返します
+// シナリオの判定とファイルの探し方はopponentRangeFilePathと同じです
+func aggressorRangeFilePath(preset string, dataDir string) (string, error) {
+	var name string
 
 	// プリセット値に基づいてアグレッサー側のレンジファイルパスを決定
-	switch preset {
-	case "SRP BB call vs UTG open", "PLO5 SRP BB call vs UTG open":
-		filePath = fmt.Sprintf("%s/srp/utg_open.csv", baseDir) // UTGがアグレッサー
-	case "SRP BB call vs BTN open", "PLO5 SRP BB call vs BTN open":
-		filePath = fmt.Sprintf("%s/srp/btn_open.csv", baseDir) // BTNがアグレッサー
-	case "SRP BTN call vs UTG open", "PLO5 SRP BTN call vs UTG open":
-		filePath = fmt.Sprintf("%s/srp/utg_open.csv", baseDir) // UTGがアグレッサー
-	case "3BP UTG call vs BB 3bet", "PLO5 3BP UTG call vs BB 3bet":
-		filePath = fmt.Sprintf("%s/3bp/bb_3b_vs_utg.csv", baseDir) // BBがアグレッサー
-	case "3BP UTG call vs BTN 3bet", "PLO5 3BP UTG call vs BTN 3bet":
-		filePath = fmt.Sprintf("%s/3bp/btn_3b_vs_utg.csv", baseDir) // BTNがアグレッサー
-	case "3BP BTN call vs BB 3bet", "PLO5 3BP BTN call vs BB 3bet":
-		filePath = fmt.Sprintf("%s/3bp/bb_3b_vs_btn.csv", baseDir) // BBがアグレッサー
+	switch strings.TrimPrefix(preset, presetPrefix(PresetVariant(preset))) {
+	case "SRP BB call vs UTG open":
+		name = "srp/utg_open" // UTGがアグレッサー
+	case "SRP BB call vs BTN open":
+		name = "srp/btn_open" // BTNがアグレッサー
+	case "SRP BTN call vs UTG open":
+		name = "srp/utg_open" // UTGがアグレッサー
+	case "3BP UTG call vs BB 3bet":
+		name = "3bp/bb_3b_vs_utg" // BBがアグレッサー
+	case "3BP UTG call vs BTN 3bet":
+		name = "3bp/btn_3b_vs_utg" // BTNがアグレッサー
+	case "3BP BTN call vs BB 3bet":
+		name = "3bp/bb_3b_vs_btn" // BBがアグレッサー
 	default:
 		return "", fmt.Errorf("unknown preset: %s", preset)
 	}
 
-	return LoadRangeFromCSV(filePath)
+	return presetRangeFilePath(preset, dataDir, name), nil
 }
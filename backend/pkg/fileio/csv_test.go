@@ -5,6 +5,8 @@ import (
 	"path/filepath"
 	"strings"
 	"testing"
+
+	pkrlib "equity-distribution-backend/pkg/poker"
 )
 
 func TestLoadRangeFromCSV(t *testing.T) {
@@ -103,7 +105,7 @@ func TestLoadRangeFromCSV(t *testing.T) {
 func TestLoadOpponentRangeFromPreset(t *testing.T) {
 	// テスト用のデータディレクトリを作成
 	tempDir := t.TempDir()
-	baseDir := filepath.Join(tempDir, "six_handed_100bb_midrake")
+	baseDir := filepath.Join(tempDir, "plo4", "six_handed_100bb_midrake")
 
 	// 必要なディレクトリ構造を作成
 	srDir := filepath.Join(baseDir, "srp")
@@ -182,7 +184,7 @@ func TestLoadOpponentRangeFromPreset(t *testing.T) {
 func TestLoadAggressorRangeFromPreset(t *testing.T) {
 	// テスト用のデータディレクトリを作成
 	tempDir := t.TempDir()
-	baseDir := filepath.Join(tempDir, "six_handed_100bb_midrake")
+	baseDir := filepath.Join(tempDir, "plo4", "six_handed_100bb_midrake")
 
 	// 必要なディレクトリ構造を作成
 	srDir := filepath.Join(baseDir, "srp")
@@ -256,3 +258,303 @@ func TestLoadAggressorRangeFromPreset(t *testing.T) {
 		}
 	})
 }
+
+func TestLoadWeightedRangeFromCSV(t *testing.T) {
+	// テストケース1: 頻度付きハンドの読み込み
+	t.Run("Load frequencies", func(t *testing.T) {
+		tempDir := t.TempDir()
+		tempFile := filepath.Join(tempDir, "weighted.csv")
+
+		csvContent := "ACADAH2C@100,KSKCQDJH@1\nQSQCJDTC"
+		err := os.WriteFile(tempFile, []byte(csvContent), 0644)
+		if err != nil {
+			t.Fatalf("Failed to create test CSV file: %v", err)
+		}
+
+		result, err := LoadWeightedRangeFromCSV(tempFile)
+		if err != nil {
+			t.Fatalf("Expected no error, got: %v", err)
+		}
+
+		if len(result) != 3 {
+			t.Fatalf("Expected 3 hands, got %d", len(result))
+		}
+
+		weights := result.Weights()
+		expectedWeights := map[string]float64{
+			"AcAdAh2c": 1.0,
+			"KsKcQdJh": 0.01,
+			"QsQcJdTc": 1.0, // 頻度なしは100%として扱う
+		}
+		for hand, expected := range expectedWeights {
+			if weights[hand] != expected {
+				t.Errorf("Expected weight %.2f for %s, got %.2f", expected, hand, weights[hand])
+			}
+		}
+	})
+
+	// テストケース2: 不正なハンド
+	t.Run("Invalid hand", func(t *testing.T) {
+		tempDir := t.TempDir()
+		tempFile := filepath.Join(tempDir, "invalid.csv")
+
+		err := os.WriteFile(tempFile, []byte("ACADAH2X@100"), 0644)
+		if err != nil {
+			t.Fatalf("Failed to create test CSV file: %v", err)
+		}
+
+		if _, err := LoadWeightedRangeFromCSV(tempFile); err == nil {
+			t.Error("Expected error for invalid card, got nil")
+		}
+	})
+
+	// テストケース3: 不正な頻度
+	t.Run("Invalid frequency", func(t *testing.T) {
+		tempDir := t.TempDir()
+		tempFile := filepath.Join(tempDir, "invalid_frequency.csv")
+
+		err := os.WriteFile(tempFile, []byte("ACADAH2C@abc"), 0644)
+		if err != nil {
+			t.Fatalf("Failed to create test CSV file: %v", err)
+		}
+
+		if _, err := LoadWeightedRangeFromCSV(tempFile); err == nil {
+			t.Error("Expected error for invalid frequency, got nil")
+		}
+	})
+
+	// テストケース4: 存在しないファイル
+	t.Run("Load non-existent file", func(t *testing.T) {
+		if _, err := LoadWeightedRangeFromCSV("/path/to/non/existent/file.csv"); err == nil {
+			t.Error("Expected error for non-existent file, got nil")
+		}
+	})
+}
+
+func TestPLO6PresetRanges(t *testing.T) {
+	// テスト用の一時ディレクトリを作成
+	tempDir := t.TempDir()
+	baseDir := filepath.Join(tempDir, "plo6", "six_handed_100bb_midrake")
+	srDir := filepath.Join(baseDir, "srp")
+
+	if err := os.MkdirAll(srDir, 0755); err != nil {
+		t.Fatalf("Failed to create srp directory: %v", err)
+	}
+
+	// テスト用のCSVファイルを作成（6枚のハンド）
+	testFiles := map[string]string{
+		filepath.Join(srDir, "bb_call_vs_utg.csv"): "ACADKCKD2H3H@50",
+		filepath.Join(srDir, "utg_open.csv"):       "ASAHKSKHQSQH",
+	}
+	for filePath, content := range testFiles {
+		if err := os.WriteFile(filePath, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to create test file %s: %v", filePath, err)
+		}
+	}
+
+	// テストケース1: PLO6プリセットはplo6ディレクトリから読み込む
+	t.Run("Load PLO6 presets", func(t *testing.T) {
+		opponentRange, err := LoadWeightedOpponentRangeFromPreset("PLO6 SRP BB call vs UTG open", tempDir)
+		if err != nil {
+			t.Fatalf("Expected no error, got: %v", err)
+		}
+		if len(opponentRange) != 1 || len(opponentRange[0].Cards) != 6 || opponentRange[0].Weight != 0.5 {
+			t.Errorf("Unexpected opponent range: %+v", opponentRange)
+		}
+
+		aggressorRange, err := LoadAggressorRangeFromPreset("PLO6 SRP BB call vs UTG open", tempDir)
+		if err != nil {
+			t.Fatalf("Expected no error, got: %v", err)
+		}
+		if aggressorRange != "ASAHKSKHQSQH" {
+			t.Errorf("Expected ASAHKSKHQSQH, got: %s", aggressorRange)
+		}
+	})
+
+	// テストケース2: レンジデータの存在確認
+	t.Run("Preset data exists", func(t *testing.T) {
+		if !PresetDataExists("PLO6 SRP BB call vs UTG open", tempDir) {
+			t.Errorf("Expected preset data to exist")
+		}
+		// Opponent側のファイルが存在しない
+		if PresetDataExists("PLO6 SRP BB call vs BTN open", tempDir) {
+			t.Errorf("Expected preset data not to exist for missing files")
+		}
+		// 他のゲームタイプのディレクトリは参照しない
+		if PresetDataExists("PLO5 SRP BB call vs UTG open", tempDir) {
+			t.Errorf("Expected preset data not to exist for plo5")
+		}
+		if PresetDataExists("Invalid preset name", tempDir) {
+			t.Errorf("Expected preset data not to exist for invalid preset")
+		}
+	})
+}
+
+func TestPresetVariant(t *testing.T) {
+	testCases := []struct {
+		preset   string
+		expected pkrlib.Variant
+	}{
+		{"SRP BB call vs UTG open", pkrlib.VariantPLO4},
+		{"PLO5 SRP BB call vs UTG open", pkrlib.VariantPLO5},
+		{"PLO6 3BP BTN call vs BB 3bet", pkrlib.VariantPLO6},
+		{"NLHE SRP BB call vs BTN open", pkrlib.VariantHoldem},
+	}
+
+	for _, tc := range testCases {
+		if variant := PresetVariant(tc.preset); variant != tc.expected {
+			t.Errorf("For preset %s, expected %s, got %s", tc.preset, tc.expected, variant)
+		}
+	}
+
+	// ゲームタイプの接頭辞を除いたシナリオ名で、ゲームタイプのデータディレクトリのファイルを探す
+	t.Run("File paths by variant", func(t *testing.T) {
+		pathCases := []struct {
+			preset    string
+			opponent  string
+			aggressor string
+		}{
+			{"SRP BB call vs UTG open", "data/plo4/six_handed_100bb_midrake/srp/bb_call_vs_utg.csv", "data/plo4/six_handed_100bb_midrake/srp/utg_open.csv"},
+			{"PLO6 3BP BTN call vs BB 3bet", "data/plo6/six_handed_100bb_midrake/3bp/btn_call_vs_bb.csv", "data/plo6/six_handed_100bb_midrake/3bp/bb_3b_vs_btn.csv"},
+			{"NLHE SRP BTN call vs UTG open", "data/nlhe/six_handed_100bb_midrake/srp/btn_call_vs_utg", "data/nlhe/six_handed_100bb_midrake/srp/utg_open"},
+		}
+		for _, tc := range pathCases {
+			opponent, err := opponentRangeFilePath(tc.preset, "data")
+			if err != nil || opponent != tc.opponent {
+				t.Errorf("For preset %s, expected opponent path %s, got %s (%v)", tc.preset, tc.opponent, opponent, err)
+			}
+			aggressor, err := aggressorRangeFilePath(tc.preset, "data")
+			if err != nil || aggressor != tc.aggressor {
+				t.Errorf("For preset %s, expected aggressor path %s, got %s (%v)", tc.preset, tc.aggressor, aggressor, err)
+			}
+		}
+
+		if _, err := opponentRangeFilePath("NLHE SRP SB call vs BTN open", "data"); err == nil {
+			t.Error("Expected error for unknown preset")
+		}
+	})
+
+	// プリセットのゲームタイプと枚数が異なるハンドを含むレンジはエラー
+	t.Run("Reject hands of another variant", func(t *testing.T) {
+		tempDir := t.TempDir()
+		srDir := filepath.Join(tempDir, "plo5", "six_handed_100bb_midrake", "srp")
+		if err := os.MkdirAll(srDir, 0755); err != nil {
+			t.Fatalf("Failed to create srp directory: %v", err)
+		}
+		if err := os.WriteFile(filepath.Join(srDir, "bb_call_vs_utg.csv"), []byte("ACADKCKD@100"), 0644); err != nil {
+			t.Fatalf("Failed to create test file: %v", err)
+		}
+
+		if _, err := LoadWeightedOpponentRangeFromPreset("PLO5 SRP BB call vs UTG open", tempDir); err == nil {
+			t.Error("Expected error for 4-card hand in PLO5 range")
+		}
+	})
+}
+
+func TestNLHEPresetRanges(t *testing.T) {
+	// テスト用の一時ディレクトリを作成
+	tempDir := t.TempDir()
+	srDir := filepath.Join(tempDir, "nlhe", "six_handed_100bb_midrake", "srp")
+	if err := os.MkdirAll(srDir, 0755); err != nil {
+		t.Fatalf("Failed to create srp directory: %v", err)
+	}
+
+	// レンジ表記のファイルを作成（コメント行と複数行の表記）
+	testFiles := map[string]string{
+		filepath.Join(srDir, "bb_call_vs_btn"): "# BB call vs BTN open\n22-JJ,AKo:0.5\nAsKs:0\n",
+		filepath.Join(srDir, "btn_open"):       "QQ+,AKs",
+	}
+	for filePath, content := range testFiles {
+		if err := os.WriteFile(filePath, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to create test file %s: %v", filePath, err)
+		}
+	}
+
+	// テストケース1: NLHEプリセットはnlheディレクトリのレンジ表記を展開する
+	t.Run("Load NLHE presets", func(t *testing.T) {
+		opponentRange, err := LoadWeightedOpponentRangeFromPreset("NLHE SRP BB call vs BTN open", tempDir)
+		if err != nil {
+			t.Fatalf("Expected no error, got: %v", err)
+		}
+		// 22-JJ（60コンボ）、AKo（12コンボ）、AsKs（頻度0）
+		if len(opponentRange) != 60+12+1 {
+			t.Errorf("Expected 73 combos, got %d", len(opponentRange))
+		}
+		if err := pkrlib.VariantHoldem.ValidateRange(opponentRange); err != nil {
+			t.Errorf("Expected Hold'em hands, got %v", err)
+		}
+
+		// ハンドの文字列では頻度が0のコンボを除く
+		aggressorRange, err := LoadAggressorRangeFromPreset("NLHE SRP BB call vs BTN open", tempDir)
+		if err != nil {
+			t.Fatalf("Expected no error, got: %v", err)
+		}
+		if hands := strings.Split(aggressorRange, ","); len(hands) != 18+4 {
+			t.Errorf("Expected 22 combos, got %d: %s", len(hands), aggressorRange)
+		}
+		opponentHands, err := LoadOpponentRangeFromPreset("NLHE SRP BB call vs BTN open", tempDir)
+		if err != nil {
+			t.Fatalf("Expected no error, got: %v", err)
+		}
+		if strings.Contains(opponentHands, "AsKs") {
+			t.Errorf("Expected the zero weight combo to be excluded, got %s", opponentHands)
+		}
+		if !PresetDataExists("NLHE SRP BB call vs BTN open", tempDir) {
+			t.Errorf("Expected preset data to exist")
+		}
+	})
+
+	// テストケース2: リポジトリのNLHEのレンジデータはすべて読み込める
+	t.Run("Repository data", func(t *testing.T) {
+		for _, preset := range []string{
+			"NLHE SRP BB call vs UTG open", "NLHE SRP BB call vs BTN open", "NLHE SRP BTN call vs UTG open",
+			"NLHE 3BP UTG call vs BB 3bet", "NLHE 3BP UTG call vs BTN 3bet", "NLHE 3BP BTN call vs BB 3bet",
+		} {
+			if _, err := LoadWeightedOpponentRangeFromPreset(preset, "../../data"); err != nil {
+				t.Errorf("%s: %v", preset, err)
+			}
+			if _, err := LoadWeightedAggressorRangeFromPreset(preset, "../../data"); err != nil {
+				t.Errorf("%s: %v", preset, err)
+			}
+		}
+	})
+
+	// テストケース3: 同じポジションのコールと3ベットの頻度の合計は各コンボで1以下、
+	// 3ベットに対するコールの頻度はオープンの頻度以下
+	t.Run("Repository data is consistent", func(t *testing.T) {
+		baseDir := "../../data/nlhe/six_handed_100bb_midrake"
+		load := func(name string) map[string]float64 {
+			weightedRange, err := LoadHoldemRangeFromFile(filepath.Join(baseDir, name))
+			if err != nil {
+				t.Fatalf("Expected no error, got: %v", err)
+			}
+			return weightedRange.Weights()
+		}
+
+		for _, pair := range [][2]string{
+			{"srp/bb_call_vs_utg", "3bp/bb_3b_vs_utg"},
+			{"srp/bb_call_vs_btn", "3bp/bb_3b_vs_btn"},
+			{"srp/btn_call_vs_utg", "3bp/btn_3b_vs_utg"},
+		} {
+			call, threeBet := load(pair[0]), load(pair[1])
+			for hand, weight := range call {
+				if total := weight + threeBet[hand]; total > 1+1e-9 {
+					t.Errorf("%s: call %.2f + 3bet %.2f exceeds 1 in %s and %s", hand, weight, threeBet[hand], pair[0], pair[1])
+				}
+			}
+		}
+
+		for _, pair := range [][2]string{
+			{"3bp/utg_call_vs_bb", "srp/utg_open"},
+			{"3bp/utg_call_vs_btn", "srp/utg_open"},
+			{"3bp/btn_call_vs_bb", "srp/btn_open"},
+		} {
+			call, open := load(pair[0]), load(pair[1])
+			for hand, weight := range call {
+				if weight > open[hand]+1e-9 {
+					t.Errorf("%s: call %.2f in %s exceeds open %.2f in %s", hand, weight, pair[0], open[hand], pair[1])
+				}
+			}
+		}
+	})
+}
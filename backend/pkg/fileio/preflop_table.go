@@ -0,0 +1,110 @@
+package fileio
+
+import (
+	"encoding/csv"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+
+	"github.com/chehsunliu/poker"
+
+	pkrlib "equity-distribution-backend/pkg/poker"
+)
+
+// preflopTableHeader はプリフロップのエクイティテーブル（CSV）のヘッダー行です
+var preflopTableHeader = []string{"hand", "equity", "std_error", "samples"}
+
+// PreflopTableEntry は事前計算した1ハンドのプリフロップのエクイティです
+type PreflopTableEntry struct {
+	Hand     string  // ハンド（アグレッサーのCSVの表記を正規化した "AcAdKhQs" 形式）
+	Equity   float64 // 相手レンジに対するエクイティ（%）
+	StdError float64 // エクイティの標準誤差（%）
+	Samples  int     // 計算に使用したイテレーション数
+}
+
+// PreflopTable はハンドからプリフロップのエクイティを引く参照テーブルです
+// キーはカードの並び順によらないため、"AcAdKhQs" と "KhAcQsAd" は同じエントリになります
+type PreflopTable map[string]PreflopTableEntry
+
+// Lookup はハンドのエントリを返します（テーブルにない場合はfalse）
+func (t PreflopTable) Lookup(hand []poker.Card) (PreflopTableEntry, bool) {
+	entry, ok := t[preflopTableKey(hand)]
+	return entry, ok
+}
+
+// preflopTableKey はカードを並べ替えたハンドの文字列を返します
+func preflopTableKey(hand []poker.Card) string {
+	sorted := append([]poker.Card{}, hand...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	return pkrlib.GenerateBoardString(sorted)
+}
+
+// SavePreflopTable はエントリを "hand,equity,std_error,samples" 形式のCSVファイルに保存します
+func SavePreflopTable(filePath string, entries []PreflopTableEntry) error {
+	f, err := os.Create(filePath)
+	if err != nil {
+		return fmt.Errorf("failed to create preflop table: %v", err)
+	}
+	defer f.Close()
+
+	w := csv.NewWriter(f)
+	if err := w.Write(preflopTableHeader); err != nil {
+		return fmt.Errorf("failed to write preflop table: %v", err)
+	}
+	for _, entry := range entries {
+		record := []string{
+			entry.Hand,
+			strconv.FormatFloat(entry.Equity, 'f', 4, 64),
+			strconv.FormatFloat(entry.StdError, 'f', 4, 64),
+			strconv.Itoa(entry.Samples),
+		}
+		if err := w.Write(record); err != nil {
+			return fmt.Errorf("failed to write preflop table: %v", err)
+		}
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return fmt.Errorf("failed to write preflop table: %v", err)
+	}
+	return f.Close()
+}
+
+// LoadPreflopTable はSavePreflopTableで保存したCSVファイルを読み込みます
+func LoadPreflopTable(filePath string) (PreflopTable, error) {
+	f, err := os.Open(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open preflop table: %v", err)
+	}
+	defer f.Close()
+
+	r := csv.NewReader(f)
+	r.FieldsPerRecord = len(preflopTableHeader)
+	records, err := r.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read preflop table %s: %v", filePath, err)
+	}
+	if len(records) == 0 || records[0][0] != preflopTableHeader[0] {
+		return nil, fmt.Errorf("invalid preflop table %s: missing header", filePath)
+	}
+
+	table := make(PreflopTable, len(records)-1)
+	for i, record := range records[1:] {
+		hand, err := pkrlib.ParseHand(record[0])
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %v", i+2, err)
+		}
+		entry := PreflopTableEntry{Hand: hand.String()}
+		if entry.Equity, err = strconv.ParseFloat(record[1], 64); err != nil {
+			return nil, fmt.Errorf("line %d: invalid equity: %v", i+2, err)
+		}
+		if entry.StdError, err = strconv.ParseFloat(record[2], 64); err != nil {
+			return nil, fmt.Errorf("line %d: invalid std error: %v", i+2, err)
+		}
+		if entry.Samples, err = strconv.Atoi(record[3]); err != nil {
+			return nil, fmt.Errorf("line %d: invalid samples: %v", i+2, err)
+		}
+		table[preflopTableKey(hand)] = entry
+	}
+	return table, nil
+}
@@ -0,0 +1,71 @@
+package fileio
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	pkrlib "equity-distribution-backend/pkg/poker"
+)
+
+func TestPreflopTable(t *testing.T) {
+	// テストケース1: 保存したテーブルを読み込み、カードの並び順によらずに引ける
+	t.Run("Save and load", func(t *testing.T) {
+		tempFile := filepath.Join(t.TempDir(), "preflop.csv")
+		entries := []PreflopTableEntry{
+			{Hand: "AsAdKhKc", Equity: 65.4321, StdError: 0.2345, Samples: 120000},
+			{Hand: "7s6s5h4h3c", Equity: 48.125, StdError: 0.25, Samples: 200000},
+		}
+		if err := SavePreflopTable(tempFile, entries); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		table, err := LoadPreflopTable(tempFile)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if len(table) != len(entries) {
+			t.Fatalf("Expected %d entries, got %d", len(entries), len(table))
+		}
+
+		hand, err := pkrlib.ParseHand("KcAsKhAd")
+		if err != nil {
+			t.Fatalf("Failed to parse hand: %v", err)
+		}
+		entry, ok := table.Lookup(hand)
+		if !ok {
+			t.Fatalf("Expected %s to be found", hand)
+		}
+		if entry != entries[0] {
+			t.Errorf("Expected %+v, got %+v", entries[0], entry)
+		}
+
+		missing, _ := pkrlib.ParseHand("QsQdJhJc")
+		if _, ok := table.Lookup(missing); ok {
+			t.Errorf("Expected %s not to be found", missing)
+		}
+	})
+
+	// テストケース2: 不正なファイルはエラー
+	t.Run("Invalid files", func(t *testing.T) {
+		tempDir := t.TempDir()
+		contents := map[string]string{
+			"no_header.csv":     "AsAdKhKc,65.0,0.2,1000\n",
+			"invalid_hand.csv":  "hand,equity,std_error,samples\nAsAs,65.0,0.2,1000\n",
+			"invalid_value.csv": "hand,equity,std_error,samples\nAsAdKhKc,abc,0.2,1000\n",
+		}
+		for name, content := range contents {
+			path := filepath.Join(tempDir, name)
+			if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+				t.Fatalf("Failed to create test CSV file: %v", err)
+			}
+			if _, err := LoadPreflopTable(path); err == nil {
+				t.Errorf("%s: expected error, got nil", name)
+			}
+		}
+
+		if _, err := LoadPreflopTable(filepath.Join(tempDir, "missing.csv")); err == nil {
+			t.Error("Expected error for a missing file, got nil")
+		}
+	})
+}
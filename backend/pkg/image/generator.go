@@ -16,6 +16,8 @@ import (
 	"github.com/chehsunliu/poker"
 	"github.com/fogleman/gg"
 	"github.com/nfnt/resize"
+
+	pkrlib "equity-distribution-backend/pkg/poker"
 )
 
 // カード画像キャッシュ
@@ -32,8 +34,27 @@ func extractPositionFromScenario(scenario string) string {
 	return strings.ToUpper(match)
 }
 
+// GameTypeDir はゲームタイプごとの画像の出力ディレクトリ名を返します（"4card", "5card", "6card", "holdem"）
+func GameTypeDir(variant pkrlib.Variant) string {
+	if !variant.IsOmaha() {
+		return "holdem"
+	}
+	return fmt.Sprintf("%dcard", variant.HandSize())
+}
+
 // GenerateDailyQuizImage は日毎のクイズ画像を生成します
-func GenerateDailyQuizImage(date time.Time, scenario string, heroHand string, flop []poker.Card) error {
+func GenerateDailyQuizImage(date time.Time, scenario string, variant pkrlib.Variant, heroHand string, flop []poker.Card) error {
+	// ヒーローハンドを解析し、枚数がゲームタイプと一致するか確認
+	hand, err := pkrlib.ParseHand(heroHand)
+	if err != nil {
+		return err
+	}
+	if err := variant.ValidateHand(hand); err != nil {
+		return err
+	}
+	// 描画には正規化された文字列（2文字で1枚のカード）を使用
+	heroHand = hand.String()
+
 	// シナリオからポジションを抽出
 	heroPosition := extractPositionFromScenario(scenario)
 	// 1. 適切なサイズでキャンバスを作成（X投稿に最適化）
@@ -42,12 +63,8 @@ func GenerateDailyQuizImage(date time.Time, scenario string, heroHand string, fl
 		height = 630
 	)
 
-	// 2. 出力ディレクトリの確保（4card/5cardで分ける）
-	gameTypeDir := "4card"
-	if len(heroHand) == 10 {
-		gameTypeDir = "5card"
-	}
-	outputDir := filepath.Join("./images/daily-quiz", gameTypeDir)
+	// 2. 出力ディレクトリの確保（ゲームタイプごとに分ける）
+	outputDir := filepath.Join("./images/daily-quiz", GameTypeDir(variant))
 	if err := os.MkdirAll(outputDir, 0755); err != nil {
 		return err
 	}
@@ -62,13 +79,9 @@ func GenerateDailyQuizImage(date time.Time, scenario string, heroHand string, fl
 	dc.SetRGB(1.0, 1.0, 1.0) // 白色
 	dc.Clear()
 
-	// 6. タイトルを描画（4-card or 5-card PLOを判定）
+	// 6. タイトルを描画（ゲームタイプの表示名を使用）
 	dateStr := date.Format("01/02")
-	gameType := "PLO"
-	if len(heroHand) == 10 {
-		gameType = "PLO5"
-	}
-	if err := drawTitle(dc, dateStr+" "+gameType+" EQ Quiz"); err != nil {
+	if err := drawTitle(dc, dateStr+" "+variant.String()+" EQ Quiz"); err != nil {
 		return err
 	}
 
@@ -78,13 +91,17 @@ func GenerateDailyQuizImage(date time.Time, scenario string, heroHand string, fl
 	}
 
 	// 8. ヒーローハンドを描画
-	// 5カードPLOの場合は位置を調整
+	// 5カード・6カードPLOの場合は位置を調整
 	heroX := 100.0
 	flopX := 700.0
-	if len(heroHand) == 10 {
+	if variant.HandSize() == 5 {
 		// 5カードPLOの場合、左右の余白を減らして中央のスペースを広げる
 		heroX = 50.0   // 左の余白を減らす
 		flopX = 750.0  // フロップを右に移動してスペースを確保
+	} else if variant.HandSize() == 6 {
+		// 6カードPLOの場合、さらに余白を減らしてフロップとの間隔を確保（フロップ右端は800 + 110×3 + 25×2 = 1180px）
+		heroX = 20.0
+		flopX = 800.0
 	}
 	if err := drawHeroHand(dc, heroHand, heroPosition, heroX, 330); err != nil {
 		return err
@@ -166,6 +183,10 @@ func drawHeroHand(dc *gg.Context, heroHand string, position string, x, y float64
 		// 利用可能スペース: 600px、カード幅: 110px × 5 = 550px
 		// 残りスペース: 50px を 4つの間隔で分割 = 12.5px
 		cardSpacing = 12
+	} else if numCards == 6 {
+		// 6カードの場合、利用可能スペース: 690px（20〜710px）、カード幅: 110px × 6 = 660px
+		// 残りスペース: 30px を 5つの間隔で分割 = 6px
+		cardSpacing = 6
 	} else {
 		cardSpacing = 25 // デフォルト
 	}
@@ -1,13 +1,14 @@
 package poker
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"math"
 	"math/rand"
 	"runtime"
+	"sort"
 	"sync"
-	"time"
 
 	"github.com/chehsunliu/poker"
 )
@@ -39,7 +40,50 @@ func CalculateHandVsRangeAdaptiveWithDetails(
 	opponentRange [][]poker.Card,
 	board []poker.Card,
 	config AdaptiveSamplingConfig,
-) (equities map[string]float64, avgEquity float64, samplesUsed int, err error) {
+	rng *rand.Rand,
+) (equities map[string]float64, result EquityResult, err error) {
+	return CalculateHandVsWeightedRangeAdaptiveWithDetails(yourHand, NewUniformRange(opponentRange), board, config, rng)
+}
+
+// CalculateHandVsWeightedRangeAdaptiveWithDetails は頻度付きレンジに対して動的サンプリングで
+// エクイティを計算します。平均エクイティはサンプリングされたハンドの頻度で重み付けされます
+// 結果のSamplesはサンプリングしたハンド数で、標準誤差はハンド間のエクイティの分散（有限母集団補正あり）から求めます
+// rngに同じシードの乱数生成器を渡すと同じハンドがサンプリングされます（nilの場合は現在時刻をシードとします）
+func CalculateHandVsWeightedRangeAdaptiveWithDetails(
+	yourHand []poker.Card,
+	opponentRange WeightedRange,
+	board []poker.Card,
+	config AdaptiveSamplingConfig,
+	rng *rand.Rand,
+) (equities map[string]float64, result EquityResult, err error) {
+	return CalculateHandVsWeightedRangeAdaptiveWithDetailsContext(context.Background(), yourHand, opponentRange, board, nil, config, rng, nil)
+}
+
+// CalculateHandVsWeightedRangeAdaptiveWithDetailsContext はctxのキャンセル・期限に対応した
+// CalculateHandVsWeightedRangeAdaptiveWithDetailsです
+// deadCards（nil可）はランアウトに配られず、デッドカードを含む相手ハンドはサンプリング対象から除外されます
+// progress（nil可）にはサンプリングしたハンドのエクイティ計算（Phase 3）の進捗を通知します
+// Phase 3の途中で中断された場合は計算できたハンドの結果（Partial=true）とctx.Err()を返します
+func CalculateHandVsWeightedRangeAdaptiveWithDetailsContext(
+	ctx context.Context,
+	yourHand []poker.Card,
+	opponentRange WeightedRange,
+	board []poker.Card,
+	deadCards []poker.Card,
+	config AdaptiveSamplingConfig,
+	rng *rand.Rand,
+	progress ProgressFunc,
+) (equities map[string]float64, result EquityResult, err error) {
+	if err := ValidateBoardSize(board); err != nil {
+		return nil, EquityResult{}, err
+	}
+	if HasCardDuplicates(yourHand, board, deadCards) {
+		return nil, EquityResult{}, fmt.Errorf("duplicate cards detected")
+	}
+	variant, err := detectRangeVariant(yourHand, opponentRange)
+	if err != nil {
+		return nil, EquityResult{}, err
+	}
 	
 	// 結果を格納するマップ
 	equities = make(map[string]float64)
@@ -47,55 +91,59 @@ func CalculateHandVsRangeAdaptiveWithDetails(
 	var wg sync.WaitGroup
 	
 	// 有効なレンジをフィルタリング
-	var validRange [][]poker.Card
-	handToString := make(map[string][]poker.Card) // ハンド文字列から実際のハンドへのマッピング
+	var validRange WeightedRange
 	
 	for _, oppHand := range opponentRange {
-		if !HasCardDuplicates(yourHand, oppHand, board) {
+		if oppHand.Weight > 0 && !HasCardDuplicates(yourHand, oppHand.Cards, board, deadCards) {
 			validRange = append(validRange, oppHand)
-			
-			// ハンド文字列を生成
-			handStr := ""
-			for _, card := range oppHand {
-				handStr += card.String()
-			}
-			handToString[handStr] = oppHand
 		}
 	}
 	
 	if len(validRange) == 0 {
-		return nil, 0, 0, fmt.Errorf("no valid opponent hands")
+		return nil, EquityResult{}, fmt.Errorf("no valid opponent hands")
 	}
 	
 	log.Printf("Valid range size: %d hands", len(validRange))
 	
 	// Phase 1: パイロットサンプリングで必要サンプル数を推定
-	rng := rand.New(rand.NewSource(time.Now().UnixNano()))
+	rng = randOrDefault(rng)
 	pilotSize := config.PilotSamples
 	if pilotSize > len(validRange) {
 		pilotSize = len(validRange)
 	}
 	
-	var pilotEquities []float64
-	var pilotSum, pilotSumSquares float64
+	var pilotWeightSum, pilotSum, pilotSumSquares float64
 	
-	// パイロットサンプルの実行
+	// パイロットサンプルの実行（頻度で重み付けした平均・分散を求める）
 	sampledIndices := make(map[int]bool)
 	for i := 0; i < pilotSize; i++ {
+		if err := ctx.Err(); err != nil {
+			return nil, EquityResult{}, err
+		}
 		idx := rng.Intn(len(validRange))
 		sampledIndices[idx] = true
 		oppHand := validRange[idx]
-		equity, _ := CalculateHandVsHandEquity(yourHand, oppHand, board)
+		handResult, err := CalculateHandVsHandEquityWithDeadCards(yourHand, oppHand.Cards, board, deadCards)
+		if err != nil {
+			continue
+		}
+		equity := handResult.Equity
 		
-		pilotEquities = append(pilotEquities, equity)
-		pilotSum += equity
-		pilotSumSquares += equity * equity
+		pilotWeightSum += oppHand.Weight
+		pilotSum += equity * oppHand.Weight
+		pilotSumSquares += equity * equity * oppHand.Weight
+	}
+	
+	if pilotWeightSum == 0 {
+		return nil, EquityResult{}, fmt.Errorf("no valid equity calculations")
 	}
 	
 	// 必要サンプル数の計算
-	n := float64(len(pilotEquities))
-	mean := pilotSum / n
-	variance := (pilotSumSquares / n) - (mean * mean)
+	mean := pilotSum / pilotWeightSum
+	variance := (pilotSumSquares / pilotWeightSum) - (mean * mean)
+	if variance < 0 {
+		variance = 0
+	}
 	stdDev := math.Sqrt(variance)
 	
 	// 必要サンプル数を計算（無限母集団）
@@ -147,47 +195,90 @@ func CalculateHandVsRangeAdaptiveWithDetails(
 	numCPU := runtime.NumCPU()
 	semaphore := make(chan struct{}, numCPU)
 	
-	totalEquity := 0.0
-	samplesUsed = 0
+	handResults := newIndexedResults(len(validRange))
+	tracker := newProgressTracker(progress, len(sampledIndices))
 	
+	// スートを入れ替えただけの相手ハンドはエクイティが同じため、同値類ごとに1回だけ計算する
+	sortedIndices := make([]int, 0, len(sampledIndices))
 	for idx := range sampledIndices {
+		sortedIndices = append(sortedIndices, idx)
+	}
+	sort.Ints(sortedIndices)
+	groups := NewSuitIsomorphism(yourHand, board, deadCards...).groupIsomorphicHands(validRange, sortedIndices)
+	if len(deadCards) == 0 {
+		prefetchRangeEquities(variant, yourHand, groupRepresentatives(validRange, groups), board)
+	}
+	
+	for _, group := range groups {
+		if !acquireSlot(ctx, semaphore) {
+			break
+		}
 		wg.Add(1)
-		semaphore <- struct{}{}
 		
-		go func(handIdx int) {
+		go func(handIndices []int) {
 			defer wg.Done()
 			defer func() { <-semaphore }()
 			
-			oppHand := validRange[handIdx]
-			
-			// ハンド文字列を生成
-			handStr := ""
-			for _, card := range oppHand {
-				handStr += card.String()
-			}
-			
-			// 全ターン・リバーでエクイティ計算（全数計算）
-			equity, _ := CalculateHandVsHandEquity(yourHand, oppHand, board)
+			// 全ターン・リバーでエクイティ計算（全数計算、代表ハンドのみ）
+			handResult, err := CalculateHandVsHandEquityWithDeadCards(yourHand, validRange[handIndices[0]].Cards, board, deadCards)
 			
-			if equity != -1 {
+			if err == nil {
 				mu.Lock()
-				equities[handStr] = equity
-				totalEquity += equity
-				samplesUsed++
+				for _, handIdx := range handIndices {
+					equities[GenerateBoardString(validRange[handIdx].Cards)] = handResult.Equity
+				}
 				mu.Unlock()
+				for _, handIdx := range handIndices {
+					handResults.set(handIdx, handResult, validRange[handIdx].Weight)
+				}
 			}
-		}(idx)
+			tracker.advance(len(handIndices))
+		}(group)
 	}
 	
 	wg.Wait()
 	
-	// 平均エクイティを計算
-	if samplesUsed > 0 {
-		avgEquity = totalEquity / float64(samplesUsed)
+	// ゴルーチンの完了順序に関係なくレンジの順序で集計する
+	sampledResults, sampledWeights := handResults.collect()
+	if len(sampledResults) == 0 {
+		if err := ctx.Err(); err != nil {
+			return nil, EquityResult{}, err
+		}
+		return nil, EquityResult{}, fmt.Errorf("no valid equity calculations")
 	}
 	
+	// 頻度で重み付けした平均エクイティと、ハンドのサンプリングによる誤差を計算
+	result = sampledRangeResult(sampledResults, sampledWeights, len(validRange))
+	
 	log.Printf("Adaptive sampling completed: sampled %d hands out of %d total hands (%.1f%%)",
-		samplesUsed, len(validRange), float64(samplesUsed)/float64(len(validRange))*100)
+		result.Samples, len(validRange), float64(result.Samples)/float64(len(validRange))*100)
+	
+	if err := ctx.Err(); err != nil {
+		return equities, result.asPartial(), err
+	}
+	return equities, result, nil
+}
+
+// sampledRangeResult はレンジからサンプリングしたハンドの全数計算結果をまとめます
+// 全ハンドを計算した場合は全数計算、それ以外はハンド間のエクイティの加重分散に
+// 有限母集団補正をかけて標準誤差を求めます
+func sampledRangeResult(handResults []EquityResult, handWeights []float64, populationSize int) EquityResult {
+	result := CombineEquityResults(handResults, handWeights)
+	result.Samples = len(handResults)
+	result.Exhaustive = len(handResults) >= populationSize
+	result.StdError = 0
+	
+	if !result.Exhaustive {
+		var weightSum, varianceSum float64
+		for i, handResult := range handResults {
+			weightSum += handWeights[i]
+			varianceSum += handWeights[i] * math.Pow(handResult.Equity-result.Equity, 2)
+		}
+		n := float64(len(handResults))
+		N := float64(populationSize)
+		fpc := (N - n) / (N - 1)
+		result.StdError = math.Sqrt(varianceSum / weightSum / n * fpc)
+	}
 	
-	return equities, avgEquity, samplesUsed, nil
+	return result.withConfidenceInterval()
 }
\ No newline at end of file
@@ -0,0 +1,128 @@
+package poker
+
+import (
+	"fmt"
+
+	"github.com/chehsunliu/poker"
+)
+
+// blockerInsightMinFrequency はBlockerInsightで取り上げる分類の相手レンジに占める最小の割合（%）です
+// 該当ハンドが少ない分類は、わずかなコンボのブロックでもブロック率が大きくなるため除外します
+const blockerInsightMinFrequency = 2.0
+
+// boardHitPhrases はBlockerInsightの文で使用する分類の名前（複数形）です
+var boardHitPhrases = map[BoardHit]string{
+	BoardHitSetPlus:              "sets or better",
+	BoardHitNutStraight:          "nut straights",
+	BoardHitTwoPair:              "two pairs",
+	BoardHitOverpair:             "overpairs",
+	BoardHitTopPair:              "top pairs",
+	BoardHitNutFlushDraw:         "nut flush draws",
+	BoardHitFlushDraw:            "flush draws",
+	BoardHitWrap13Plus:           "13+ out wraps",
+	BoardHitStraightDraw:         "straight draws",
+	BoardHitBackdoorFlushDraw:    "backdoor flush draws",
+	BoardHitBackdoorStraightDraw: "backdoor straight draws",
+}
+
+// BlockerStat は相手レンジの分類ごとに、ヒーローのカードによって除外されるハンドを表します
+type BlockerStat struct {
+	Hit                BoardHit `json:"hit"`
+	Combos             int      `json:"combos"`              // ヒーローのカードを除外しないレンジで該当するハンド数
+	BlockedCombos      int      `json:"blocked_combos"`      // そのうちヒーローのカードを含むハンド数
+	BlockedPercent     float64  `json:"blocked_percent"`     // 該当するハンドのうちヒーローがブロックする割合（%、頻度で重み付け）
+	UnblockedFrequency float64  `json:"unblocked_frequency"` // ヒーローのカードを除外しないレンジに占める割合（%）
+	Frequency          float64  `json:"frequency"`           // ヒーローのカードを除外したレンジに占める割合（%）
+}
+
+// BlockerAnalysis はヒーローのハンドによる相手レンジのカードリムーバルの分析結果です
+type BlockerAnalysis struct {
+	// BlockedPercent はレンジ全体のうちヒーローがブロックする割合（%）です
+	// 分類ごとのブロック率をこの値と比べることで、ヒーローが特にブロックしている分類がわかります
+	BlockedPercent float64       `json:"blocked_percent"`
+	Stats          []BlockerStat `json:"stats"` // BoardHitsの順にすべての分類を含みます
+}
+
+// CalculateBlockers はヒーローのハンドが相手レンジの分類ごとのハンドをどれだけブロックするかを計算します
+// 比較の基準はフロップとカードが重複するハンドのみを除外したレンジで、ヒーローのカードを含むハンドをブロックされたハンドとして扱います
+func CalculateBlockers(yourHand []poker.Card, opponentRange WeightedRange, flop []poker.Card) (BlockerAnalysis, error) {
+	if len(flop) != 3 {
+		return BlockerAnalysis{}, fmt.Errorf("flop must have 3 cards, got %d", len(flop))
+	}
+	if HasCardDuplicates(yourHand, flop) {
+		return BlockerAnalysis{}, fmt.Errorf("duplicate cards detected")
+	}
+	variant, err := detectRangeVariant(yourHand, opponentRange)
+	if err != nil {
+		return BlockerAnalysis{}, err
+	}
+	if !variant.IsOmaha() {
+		return BlockerAnalysis{}, fmt.Errorf("blocker analysis is only supported for Omaha, got %s", variant)
+	}
+
+	nutStraightHigh := nutStraightHigh(flop)
+	stats := make([]BlockerStat, len(BoardHits))
+	hitWeights := make([]float64, len(BoardHits))
+	blockedHitWeights := make([]float64, len(BoardHits))
+	var totalWeight, blockedWeight float64
+
+	for _, hand := range opponentRange {
+		if hand.Weight <= 0 || HasCardDuplicates(hand.Cards, flop) {
+			continue
+		}
+		blocked := HasCardDuplicates(yourHand, hand.Cards)
+		totalWeight += hand.Weight
+		if blocked {
+			blockedWeight += hand.Weight
+		}
+
+		hits := boardHitsOf(hand.Cards, flop, nutStraightHigh)
+		for i, hit := range BoardHits {
+			if hits&(1<<hit) == 0 {
+				continue
+			}
+			stats[i].Combos++
+			hitWeights[i] += hand.Weight
+			if blocked {
+				stats[i].BlockedCombos++
+				blockedHitWeights[i] += hand.Weight
+			}
+		}
+	}
+	if totalWeight == 0 {
+		return BlockerAnalysis{}, fmt.Errorf("no valid hands in opponent range")
+	}
+
+	analysis := BlockerAnalysis{BlockedPercent: blockedWeight / totalWeight * 100, Stats: stats}
+	for i, hit := range BoardHits {
+		stats[i].Hit = hit
+		stats[i].UnblockedFrequency = hitWeights[i] / totalWeight * 100
+		if hitWeights[i] > 0 {
+			stats[i].BlockedPercent = blockedHitWeights[i] / hitWeights[i] * 100
+		}
+		if remaining := totalWeight - blockedWeight; remaining > 0 {
+			stats[i].Frequency = (hitWeights[i] - blockedHitWeights[i]) / remaining * 100
+		}
+	}
+	return analysis, nil
+}
+
+// Insight はレンジ全体と比べて最も多くブロックしている分類を説明する文を返します
+// 例: "You block 38% of villain's nut flush draws (vs 12% of the whole range)"
+// レンジ全体より多くブロックしている分類がない場合は空文字列を返します
+func (a BlockerAnalysis) Insight() string {
+	best := -1
+	for i, stat := range a.Stats {
+		if stat.BlockedCombos == 0 || stat.UnblockedFrequency < blockerInsightMinFrequency || stat.BlockedPercent <= a.BlockedPercent {
+			continue
+		}
+		if best < 0 || stat.BlockedPercent-a.BlockedPercent > a.Stats[best].BlockedPercent-a.BlockedPercent {
+			best = i
+		}
+	}
+	if best < 0 {
+		return ""
+	}
+	stat := a.Stats[best]
+	return fmt.Sprintf("You block %.0f%% of villain's %s (vs %.0f%% of the whole range)", stat.BlockedPercent, boardHitPhrases[stat.Hit], a.BlockedPercent)
+}
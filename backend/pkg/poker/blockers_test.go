@@ -0,0 +1,103 @@
+package poker
+
+import (
+	"encoding/json"
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestCalculateBlockers(t *testing.T) {
+	flop := cards("9c", "5h", "2h")
+	opponentRange := WeightedRange{
+		{Cards: cards("Ah", "Kh", "Qs", "Js"), Weight: 1.0}, // ナッツフラッシュドロー（Ahをブロック）
+		{Cards: cards("Ah", "3h", "Ts", "8d"), Weight: 1.0}, // ナッツフラッシュドロー（Ahをブロック）
+		{Cards: cards("Kh", "Qh", "Tc", "8c"), Weight: 2.0}, // フラッシュドロー
+		{Cards: cards("9s", "9d", "Kc", "Qd"), Weight: 1.0}, // セット
+		{Cards: cards("Td", "8s", "7s", "6d"), Weight: 1.0}, // ラップ
+		{Cards: cards("9h", "8d", "7c", "6c"), Weight: 0.0}, // 頻度0のため除外
+	}
+	yourHand := cards("Ah", "As", "Kd", "Qd")
+
+	analysis, err := CalculateBlockers(yourHand, opponentRange, flop)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(analysis.Stats) != len(BoardHits) {
+		t.Fatalf("Expected %d stats, got %d", len(BoardHits), len(analysis.Stats))
+	}
+	// ヒーローのカードを含むのはAhの2ハンド（頻度の合計6.0のうち2.0）とQdのセット（1.0）
+	if math.Abs(analysis.BlockedPercent-50) > 1e-9 {
+		t.Errorf("Expected 50%% of the range to be blocked, got %.2f%%", analysis.BlockedPercent)
+	}
+
+	byHit := make(map[BoardHit]BlockerStat)
+	for _, stat := range analysis.Stats {
+		byHit[stat.Hit] = stat
+	}
+
+	nfd := byHit[BoardHitNutFlushDraw]
+	if nfd.Combos != 2 || nfd.BlockedCombos != 2 || nfd.BlockedPercent != 100 {
+		t.Errorf("Expected both nut flush draws to be blocked, got %+v", nfd)
+	}
+	if math.Abs(nfd.UnblockedFrequency-100.0/3) > 1e-9 || nfd.Frequency != 0 {
+		t.Errorf("Expected nut flush draws at 33.33%% unblocked and 0%% blocked, got %+v", nfd)
+	}
+
+	fd := byHit[BoardHitFlushDraw]
+	if fd.Combos != 3 || fd.BlockedCombos != 2 || math.Abs(fd.BlockedPercent-50) > 1e-9 {
+		t.Errorf("Expected 2 of 3 flush draws (50%% by weight) to be blocked, got %+v", fd)
+	}
+	// 残りのレンジ（頻度3.0）のうちフラッシュドローは2.0
+	if math.Abs(fd.Frequency-200.0/3) > 1e-9 {
+		t.Errorf("Expected flush draws at 66.67%% of the remaining range, got %.2f%%", fd.Frequency)
+	}
+
+	if wrap := byHit[BoardHitWrap13Plus]; wrap.BlockedCombos != 0 || wrap.BlockedPercent != 0 {
+		t.Errorf("Expected no wraps to be blocked, got %+v", wrap)
+	}
+
+	data, err := json.Marshal(analysis.Stats[0])
+	if err != nil {
+		t.Fatalf("Failed to marshal: %v", err)
+	}
+	if !strings.HasPrefix(string(data), `{"hit":"set_plus","combos":1,"blocked_combos":1,`) {
+		t.Errorf("Unexpected JSON: %s", data)
+	}
+
+	t.Run("Rejects invalid input", func(t *testing.T) {
+		if _, err := CalculateBlockers(yourHand, opponentRange, cards("9c", "5h")); err == nil {
+			t.Errorf("Expected an error for a 2 card flop")
+		}
+		if _, err := CalculateBlockers(cards("9c", "As", "Kd", "Qc"), opponentRange, flop); err == nil {
+			t.Errorf("Expected an error when the hero hand conflicts with the flop")
+		}
+		if _, err := CalculateBlockers(cards("Ah", "As"), WeightedRange{{Cards: cards("Kh", "Ks"), Weight: 1.0}}, flop); err == nil {
+			t.Errorf("Expected an error for a Hold'em hand")
+		}
+		if _, err := CalculateBlockers(yourHand, WeightedRange{{Cards: cards("9c", "8d", "7c", "6c"), Weight: 1.0}}, flop); err == nil {
+			t.Errorf("Expected an error when every hand conflicts with the flop")
+		}
+	})
+}
+
+func TestBlockerAnalysisInsight(t *testing.T) {
+	analysis := BlockerAnalysis{
+		BlockedPercent: 12,
+		Stats: []BlockerStat{
+			{Hit: BoardHitSetPlus, Combos: 10, BlockedCombos: 2, BlockedPercent: 20, UnblockedFrequency: 10},
+			{Hit: BoardHitNutStraight, Combos: 1, BlockedCombos: 1, BlockedPercent: 100, UnblockedFrequency: 0.5}, // 割合が小さいため除外
+			{Hit: BoardHitNutFlushDraw, Combos: 20, BlockedCombos: 8, BlockedPercent: 38, UnblockedFrequency: 15},
+		},
+	}
+	want := "You block 38% of villain's nut flush draws (vs 12% of the whole range)"
+	if got := analysis.Insight(); got != want {
+		t.Errorf("Expected %q, got %q", want, got)
+	}
+
+	analysis.Stats = analysis.Stats[:1]
+	analysis.BlockedPercent = 25
+	if got := analysis.Insight(); got != "" {
+		t.Errorf("Expected no insight when nothing is blocked more than the whole range, got %q", got)
+	}
+}
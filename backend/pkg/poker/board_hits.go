@@ -0,0 +1,344 @@
+package poker
+
+import (
+	"fmt"
+
+	"github.com/chehsunliu/poker"
+)
+
+// BoardHit はフロップでハンドが該当する役・ドローです
+// ハンドクラス（HandClass）と異なり、1つのハンドが複数に該当します（例: セットとナッツフラッシュドロー）
+// 役はevaluateOmahaHandと同じく、ハンドから2枚・ボードから3枚で判定します
+type BoardHit int
+
+const (
+	BoardHitSetPlus              BoardHit = iota // スリーカード以上（セット・トリップス・ストレート・フラッシュ・フルハウス以上）
+	BoardHitNutStraight                          // このフロップで可能な最も強いストレート
+	BoardHitTwoPair                              // ツーペア
+	BoardHitOverpair                             // オーバーペア（ワンペアのみ）
+	BoardHitTopPair                              // トップペア（ワンペアのみ）
+	BoardHitNutFlushDraw                         // ナッツフラッシュドロー（ボードにない最も高いカードを含む）
+	BoardHitFlushDraw                            // フラッシュドロー（ナッツを含む）
+	BoardHitWrap13Plus                           // ストレートのアウツが13枚以上のラップ（ストレート未完成）
+	BoardHitStraightDraw                         // ストレートのアウツが8枚以上（ラップを含む、ストレート未完成）
+	BoardHitBackdoorFlushDraw                    // バックドアフラッシュドロー
+	BoardHitBackdoorStraightDraw                 // バックドアストレートドロー（ストレートドローがない場合のみ）
+)
+
+// BoardHits は全分類を表示順に並べたものです
+var BoardHits = []BoardHit{
+	BoardHitSetPlus, BoardHitNutStraight, BoardHitTwoPair, BoardHitOverpair, BoardHitTopPair,
+	BoardHitNutFlushDraw, BoardHitFlushDraw, BoardHitWrap13Plus, BoardHitStraightDraw,
+	BoardHitBackdoorFlushDraw, BoardHitBackdoorStraightDraw,
+}
+
+// boardHitNames はJSONやログに出力する名前です
+var boardHitNames = map[BoardHit]string{
+	BoardHitSetPlus:              "set_plus",
+	BoardHitNutStraight:          "nut_straight",
+	BoardHitTwoPair:              "two_pair",
+	BoardHitOverpair:             "overpair",
+	BoardHitTopPair:              "top_pair",
+	BoardHitNutFlushDraw:         "nut_flush_draw",
+	BoardHitFlushDraw:            "flush_draw",
+	BoardHitWrap13Plus:           "wrap_13_plus",
+	BoardHitStraightDraw:         "straight_draw",
+	BoardHitBackdoorFlushDraw:    "backdoor_flush_draw",
+	BoardHitBackdoorStraightDraw: "backdoor_straight_draw",
+}
+
+// wrap13MinOuts はBoardHitWrap13Plusとみなすストレートのアウツの最小枚数です
+const wrap13MinOuts = 13
+
+// boardHitStraightDrawMinOuts はBoardHitStraightDrawとみなすストレートのアウツの最小枚数です（オープンエンド）
+const boardHitStraightDrawMinOuts = 8
+
+// String は名前を返します（"set_plus", "nut_flush_draw"など）
+func (h BoardHit) String() string {
+	if name, ok := boardHitNames[h]; ok {
+		return name
+	}
+	return fmt.Sprintf("BoardHit(%d)", int(h))
+}
+
+// MarshalText はJSONに名前を出力します
+func (h BoardHit) MarshalText() ([]byte, error) {
+	if _, ok := boardHitNames[h]; !ok {
+		return nil, fmt.Errorf("unknown board hit: %d", int(h))
+	}
+	return []byte(h.String()), nil
+}
+
+// BoardHitStat はレンジのうち分類に該当するハンドの割合です
+type BoardHitStat struct {
+	Hit       BoardHit `json:"hit"`
+	Combos    int      `json:"combos"`    // 該当するハンド数
+	Frequency float64  `json:"frequency"` // レンジ全体に占める割合（%、頻度で重み付け）
+}
+
+// BoardHitComparison はアグレッサーとディフェンダーのレンジの分類ごとの割合（%）です
+type BoardHitComparison struct {
+	Hit       BoardHit `json:"hit"`
+	Aggressor float64  `json:"aggressor"`
+	Defender  float64  `json:"defender"`
+}
+
+// Difference はアグレッサーとディフェンダーの割合の差（%ポイント）を返します
+func (c BoardHitComparison) Difference() float64 {
+	return c.Aggressor - c.Defender
+}
+
+// CalculateBoardHits はレンジをフロップで分類し、分類ごとのハンド数と割合を返します
+// フロップとカードが重複するハンド、頻度0のハンドは除外し、結果はBoardHitsの順にすべての分類を含みます
+func CalculateBoardHits(weightedRange WeightedRange, flop []poker.Card) ([]BoardHitStat, error) {
+	if len(flop) != 3 {
+		return nil, fmt.Errorf("flop must have 3 cards, got %d", len(flop))
+	}
+	if HasCardDuplicates(flop) {
+		return nil, fmt.Errorf("duplicate cards detected")
+	}
+	if len(weightedRange) == 0 {
+		return nil, fmt.Errorf("empty range")
+	}
+	variant, err := detectRangeVariant(weightedRange[0].Cards, weightedRange)
+	if err != nil {
+		return nil, err
+	}
+	if !variant.IsOmaha() {
+		return nil, fmt.Errorf("board hits are only supported for Omaha, got %s", variant)
+	}
+
+	nutStraightHigh := nutStraightHigh(flop)
+	stats := make([]BoardHitStat, len(BoardHits))
+	weights := make([]float64, len(BoardHits))
+	var totalWeight float64
+
+	for _, hand := range weightedRange {
+		if hand.Weight <= 0 || HasCardDuplicates(hand.Cards, flop) {
+			continue
+		}
+		totalWeight += hand.Weight
+		hits := boardHitsOf(hand.Cards, flop, nutStraightHigh)
+		for i, hit := range BoardHits {
+			if hits&(1<<hit) != 0 {
+				stats[i].Combos++
+				weights[i] += hand.Weight
+			}
+		}
+	}
+	if totalWeight == 0 {
+		return nil, fmt.Errorf("no valid hands in range")
+	}
+
+	for i, hit := range BoardHits {
+		stats[i].Hit = hit
+		stats[i].Frequency = weights[i] / totalWeight * 100
+	}
+	return stats, nil
+}
+
+// CompareBoardHits はアグレッサーとディフェンダーのレンジの分類ごとの割合を並べて返します
+// 割合の差からレンジアドバンテージ（トップペア以上など）やナッツアドバンテージ（セット以上・ナッツストレート）を比較できます
+func CompareBoardHits(aggressorRange WeightedRange, defenderRange WeightedRange, flop []poker.Card) ([]BoardHitComparison, error) {
+	aggressorStats, err := CalculateBoardHits(aggressorRange, flop)
+	if err != nil {
+		return nil, fmt.Errorf("aggressor range: %v", err)
+	}
+	defenderStats, err := CalculateBoardHits(defenderRange, flop)
+	if err != nil {
+		return nil, fmt.Errorf("defender range: %v", err)
+	}
+
+	comparison := make([]BoardHitComparison, len(BoardHits))
+	for i, hit := range BoardHits {
+		comparison[i] = BoardHitComparison{
+			Hit:       hit,
+			Aggressor: aggressorStats[i].Frequency,
+			Defender:  defenderStats[i].Frequency,
+		}
+	}
+	return comparison, nil
+}
+
+// boardHitsOf はハンドが該当する分類をビットで返します
+func boardHitsOf(hand []poker.Card, flop []poker.Card, nutStraightHigh int32) uint32 {
+	var hits uint32
+	add := func(hit BoardHit) { hits |= 1 << hit }
+
+	// 完成した役
+	rankClass := poker.RankClass(evaluateOmahaHand(hand, flop))
+	madeStraight := rankClass <= 5
+	switch {
+	case rankClass <= 6:
+		add(BoardHitSetPlus)
+	case rankClass == 7:
+		add(BoardHitTwoPair)
+	case rankClass == 8:
+		topBoardRank := int32(0)
+		for _, card := range flop {
+			if card.Rank() > topBoardRank {
+				topBoardRank = card.Rank()
+			}
+		}
+		var handRanks [13]int
+		for _, card := range hand {
+			handRanks[card.Rank()]++
+		}
+		if handRanks[topBoardRank] > 0 {
+			add(BoardHitTopPair)
+		}
+		for rank := topBoardRank + 1; rank < 13; rank++ {
+			if handRanks[rank] >= 2 {
+				add(BoardHitOverpair)
+			}
+		}
+	}
+	if nutStraightHigh >= 0 && bestStraightHigh(hand, flop) == nutStraightHigh {
+		add(BoardHitNutStraight)
+	}
+
+	// フラッシュドロー（ボードに2枚あるスート）とバックドア（ボードに1枚あるスート）
+	var boardSuits, handSuits [16]int
+	for _, card := range flop {
+		boardSuits[card.Suit()]++
+	}
+	for _, card := range hand {
+		handSuits[card.Suit()]++
+	}
+	for suit := range boardSuits {
+		if handSuits[suit] < 2 {
+			continue
+		}
+		switch boardSuits[suit] {
+		case 2:
+			add(BoardHitFlushDraw)
+			if holdsNutFlushCard(hand, flop, int32(suit)) {
+				add(BoardHitNutFlushDraw)
+			}
+		case 1:
+			add(BoardHitBackdoorFlushDraw)
+		}
+	}
+
+	// ストレートドロー（ストレートが完成していない場合のみ）
+	if !madeStraight {
+		outs := countStraightOuts(hand, flop)
+		switch {
+		case outs >= wrap13MinOuts:
+			add(BoardHitWrap13Plus)
+			add(BoardHitStraightDraw)
+		case outs >= boardHitStraightDrawMinOuts:
+			add(BoardHitStraightDraw)
+		case outs < straightDrawMinOuts && hasBackdoorStraightDraw(hand, flop):
+			add(BoardHitBackdoorStraightDraw)
+		}
+	}
+	return hits
+}
+
+// holdsNutFlushCard はハンドがスートのうちボードにない最も高いカードを持っているかを返します
+func holdsNutFlushCard(hand []poker.Card, flop []poker.Card, suit int32) bool {
+	for rank := int32(12); rank >= 0; rank-- {
+		onBoard := false
+		for _, card := range flop {
+			if card.Suit() == suit && card.Rank() == rank {
+				onBoard = true
+			}
+		}
+		if onBoard {
+			continue
+		}
+		for _, card := range hand {
+			if card.Suit() == suit && card.Rank() == rank {
+				return true
+			}
+		}
+		return false
+	}
+	return false
+}
+
+// straightHigh は5枚のランクがストレートの場合に最も高いランクを返します（A-2-3-4-5は5のランク、ストレートでない場合は-1）
+func straightHigh(ranks ...int32) int32 {
+	if !isStraightRanks(ranks...) {
+		return -1
+	}
+	high, hasAce, hasTwo := int32(-1), false, false
+	for _, rank := range ranks {
+		if rank > high {
+			high = rank
+		}
+		hasAce = hasAce || rank == 12
+		hasTwo = hasTwo || rank == 0
+	}
+	if hasAce && hasTwo {
+		return 3 // A-2-3-4-5
+	}
+	return high
+}
+
+// nutStraightHigh はフロップで2枚のランクを加えてできる最も強いストレートの最高ランクを返します（ストレートができない場合は-1）
+func nutStraightHigh(flop []poker.Card) int32 {
+	best := int32(-1)
+	for r1 := int32(0); r1 < 13; r1++ {
+		for r2 := r1 + 1; r2 < 13; r2++ {
+			if high := straightHigh(r1, r2, flop[0].Rank(), flop[1].Rank(), flop[2].Rank()); high > best {
+				best = high
+			}
+		}
+	}
+	return best
+}
+
+// bestStraightHigh はハンドの2枚とフロップでできる最も強いストレートの最高ランクを返します（ストレートでない場合は-1）
+func bestStraightHigh(hand []poker.Card, flop []poker.Card) int32 {
+	best := int32(-1)
+	for i := 0; i < len(hand); i++ {
+		for j := i + 1; j < len(hand); j++ {
+			if high := straightHigh(hand[i].Rank(), hand[j].Rank(), flop[0].Rank(), flop[1].Rank(), flop[2].Rank()); high > best {
+				best = high
+			}
+		}
+	}
+	return best
+}
+
+// hasBackdoorStraightDraw はターンとリバーの2枚でストレートが完成する可能性があるかを返します
+// ハンドの異なる2ランクとボードの1ランクが連続する5ランクの範囲に収まれば、残りの2ランクをターンとリバーで補えます
+func hasBackdoorStraightDraw(hand []poker.Card, flop []poker.Card) bool {
+	for i := 0; i < len(hand); i++ {
+		for j := i + 1; j < len(hand); j++ {
+			for _, card := range flop {
+				if fitsStraightWindow(hand[i].Rank(), hand[j].Rank(), card.Rank()) {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// fitsStraightWindow は異なるランクがすべて連続する5ランクの範囲に収まるかを返します（Aはローとしても扱う）
+func fitsStraightWindow(ranks ...int32) bool {
+	// ビット0をローのA、ビット1〜13を2〜Aとする
+	var high, low int
+	for _, rank := range ranks {
+		if high&(1<<(rank+1)) != 0 {
+			return false
+		}
+		high |= 1 << (rank + 1)
+		if rank == 12 {
+			low |= 1
+		} else {
+			low |= 1 << (rank + 1)
+		}
+	}
+	for _, bits := range []int{high, low} {
+		for start := 0; start <= 9; start++ {
+			if bits&^(0x1f<<start) == 0 {
+				return true
+			}
+		}
+	}
+	return false
+}
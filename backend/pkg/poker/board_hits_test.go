@@ -0,0 +1,166 @@
+package poker
+
+import (
+	"encoding/json"
+	"math"
+	"testing"
+)
+
+func TestBoardHitsOf(t *testing.T) {
+	tests := []struct {
+		name string
+		hand string
+		flop string
+		want []BoardHit
+	}{
+		{"Set with nut flush draw", "9s9dAhKh", "9c5h2h", []BoardHit{BoardHitSetPlus, BoardHitNutFlushDraw, BoardHitFlushDraw}},
+		{"Nut straight", "JhTd4c3c", "9s8d7h", []BoardHit{BoardHitSetPlus, BoardHitNutStraight}},
+		{"Non-nut straight", "6s5d4s3c", "9s8d7h", []BoardHit{BoardHitSetPlus, BoardHitBackdoorFlushDraw}},
+		{"Two pair", "Ks9dQh4c", "Kc9h2d", []BoardHit{BoardHitTwoPair, BoardHitBackdoorStraightDraw}},
+		{"Overpair", "AsAdKhQc", "Ts7d2c", []BoardHit{BoardHitOverpair, BoardHitBackdoorStraightDraw}},
+		{"Top pair", "Ts9d4h3c", "Th6s2c", []BoardHit{BoardHitTopPair}},
+		{"Non-nut flush draw", "KsQs7d4c", "Js8s2h", []BoardHit{BoardHitFlushDraw, BoardHitBackdoorStraightDraw}},
+		{"13 out wrap", "JsTd8h7c", "9s6d2c", []BoardHit{BoardHitWrap13Plus, BoardHitStraightDraw}},
+		{"Backdoors only", "AsKsQd3c", "Js6d2h", []BoardHit{BoardHitBackdoorFlushDraw, BoardHitBackdoorStraightDraw}},
+		{"Underpair", "2h2d3c3d", "KsTs8s", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hand, err := ParseHand(tt.hand)
+			if err != nil {
+				t.Fatalf("Invalid hand: %v", err)
+			}
+			flop, err := ParseBoard(tt.flop)
+			if err != nil {
+				t.Fatalf("Invalid flop: %v", err)
+			}
+
+			hits := boardHitsOf(hand, flop, nutStraightHigh(flop))
+			var want uint32
+			for _, hit := range tt.want {
+				want |= 1 << hit
+			}
+			for _, hit := range BoardHits {
+				if got := hits&(1<<hit) != 0; got != (want&(1<<hit) != 0) {
+					t.Errorf("%s: expected %v, got %v", hit, !got, got)
+				}
+			}
+		})
+	}
+}
+
+func TestCalculateBoardHits(t *testing.T) {
+	flop := cards("9c", "5h", "2h")
+	weightedRange := WeightedRange{
+		{Cards: cards("9s", "9d", "Ah", "Kh"), Weight: 1.0}, // セット+ナッツフラッシュドロー
+		{Cards: cards("As", "Ad", "Kc", "Qc"), Weight: 0.5}, // オーバーペア
+		{Cards: cards("Ks", "Qd", "Jc", "Tc"), Weight: 0.5}, // 該当なし
+		{Cards: cards("9c", "8d", "7c", "6c"), Weight: 1.0}, // フロップと重複するため除外
+		{Cards: cards("Kd", "Qs", "Jd", "Th"), Weight: 0.0}, // 頻度0のため除外
+	}
+
+	stats, err := CalculateBoardHits(weightedRange, flop)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(stats) != len(BoardHits) {
+		t.Fatalf("Expected %d stats, got %d", len(BoardHits), len(stats))
+	}
+
+	byHit := make(map[BoardHit]BoardHitStat)
+	for i, stat := range stats {
+		if stat.Hit != BoardHits[i] {
+			t.Errorf("Expected stats in BoardHits order, got %s at %d", stat.Hit, i)
+		}
+		byHit[stat.Hit] = stat
+	}
+	// 有効な頻度の合計は2.0
+	if got := byHit[BoardHitSetPlus]; got.Combos != 1 || math.Abs(got.Frequency-50) > 1e-9 {
+		t.Errorf("Expected set_plus 1 combo at 50%%, got %d combos at %.2f%%", got.Combos, got.Frequency)
+	}
+	if got := byHit[BoardHitOverpair]; got.Combos != 1 || math.Abs(got.Frequency-25) > 1e-9 {
+		t.Errorf("Expected overpair 1 combo at 25%%, got %d combos at %.2f%%", got.Combos, got.Frequency)
+	}
+	if got := byHit[BoardHitNutFlushDraw]; math.Abs(got.Frequency-50) > 1e-9 {
+		t.Errorf("Expected nut_flush_draw at 50%%, got %.2f%%", got.Frequency)
+	}
+	if got := byHit[BoardHitTwoPair]; got.Combos != 0 || got.Frequency != 0 {
+		t.Errorf("Expected no two_pair, got %d combos at %.2f%%", got.Combos, got.Frequency)
+	}
+
+	data, err := json.Marshal(stats[0])
+	if err != nil {
+		t.Fatalf("Failed to marshal: %v", err)
+	}
+	if want := `{"hit":"set_plus","combos":1,"frequency":50}`; string(data) != want {
+		t.Errorf("Expected %s, got %s", want, data)
+	}
+
+	t.Run("Rejects invalid input", func(t *testing.T) {
+		if _, err := CalculateBoardHits(weightedRange, cards("9c", "5h")); err == nil {
+			t.Errorf("Expected an error for a 2 card flop")
+		}
+		if _, err := CalculateBoardHits(nil, flop); err == nil {
+			t.Errorf("Expected an error for an empty range")
+		}
+		if _, err := CalculateBoardHits(WeightedRange{{Cards: cards("As", "Ad"), Weight: 1.0}}, flop); err == nil {
+			t.Errorf("Expected an error for a Hold'em range")
+		}
+		if _, err := CalculateBoardHits(WeightedRange{{Cards: cards("9c", "8d", "7c", "6c"), Weight: 1.0}}, flop); err == nil {
+			t.Errorf("Expected an error when every hand conflicts with the flop")
+		}
+	})
+}
+
+func TestCompareBoardHits(t *testing.T) {
+	flop := cards("Ac", "Kd", "7h")
+	aggressorRange := WeightedRange{
+		{Cards: cards("As", "Ad", "Qc", "Jc"), Weight: 1.0}, // セット
+		{Cards: cards("Ks", "Kh", "9c", "8c"), Weight: 1.0}, // セット
+	}
+	defenderRange := WeightedRange{
+		{Cards: cards("8s", "6d", "5c", "4c"), Weight: 1.0},
+		{Cards: cards("Ah", "Qd", "Js", "9c"), Weight: 1.0}, // トップペア
+	}
+
+	comparison, err := CompareBoardHits(aggressorRange, defenderRange, flop)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	for _, c := range comparison {
+		switch c.Hit {
+		case BoardHitSetPlus:
+			if c.Aggressor != 100 || c.Defender != 0 || c.Difference() != 100 {
+				t.Errorf("Expected set_plus 100%% vs 0%%, got %.2f%% vs %.2f%%", c.Aggressor, c.Defender)
+			}
+		case BoardHitTopPair:
+			if c.Aggressor != 0 || c.Defender != 50 || c.Difference() != -50 {
+				t.Errorf("Expected top_pair 0%% vs 50%%, got %.2f%% vs %.2f%%", c.Aggressor, c.Defender)
+			}
+		}
+	}
+
+	if _, err := CompareBoardHits(aggressorRange, nil, flop); err == nil {
+		t.Errorf("Expected an error for an empty defender range")
+	}
+}
+
+func TestFitsStraightWindow(t *testing.T) {
+	tests := []struct {
+		ranks []int32
+		want  bool
+	}{
+		{[]int32{8, 7, 4}, true},   // T-9-6
+		{[]int32{8, 7, 3}, false},  // T-9-5
+		{[]int32{12, 0, 3}, true},  // A-2-5
+		{[]int32{12, 11, 8}, true}, // A-K-T
+		{[]int32{12, 1, 10}, false},
+		{[]int32{8, 8, 7}, false}, // 同じランクを含む
+	}
+	for _, tt := range tests {
+		if got := fitsStraightWindow(tt.ranks...); got != tt.want {
+			t.Errorf("fitsStraightWindow(%v): expected %v, got %v", tt.ranks, tt.want, got)
+		}
+	}
+}
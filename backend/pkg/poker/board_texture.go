@@ -0,0 +1,282 @@
+package poker
+
+import (
+	"fmt"
+	"math/rand"
+	"strings"
+
+	"github.com/chehsunliu/poker"
+)
+
+// TextureTag はフロップのテクスチャの分類です
+// 分類はスート・ペア・コネクト・ハイカードの4つのカテゴリに分かれ、1つのフロップは各カテゴリで1つずつのタグを持ちます
+type TextureTag int
+
+const (
+	TextureMonotone     TextureTag = iota // 3枚が同じスート
+	TextureTwoTone                        // 2枚が同じスート
+	TextureRainbow                        // 3枚が異なるスート
+	TextureUnpaired                       // ペアなし
+	TexturePaired                         // ペアボード
+	TextureTrips                          // 3枚が同じランク
+	TextureConnected                      // 3枚が異なるランクで、5ランクの範囲に収まる（ストレートが可能）
+	TextureDisconnected                   // ストレートが不可能
+	TextureAceHigh                        // 最も高いカードがA
+	TextureBroadway                       // 最も高いカードがK〜T
+	TextureMiddle                         // 最も高いカードが9〜7
+	TextureLow                            // 最も高いカードが6以下
+)
+
+// テクスチャのカテゴリ
+const (
+	textureCategorySuits = iota
+	textureCategoryPairing
+	textureCategoryConnectedness
+	textureCategoryHighCard
+	textureCategoryCount
+)
+
+// textureTagNames はコマンドラインやDBで使用するテクスチャの名前です
+var textureTagNames = map[TextureTag]string{
+	TextureMonotone:     "monotone",
+	TextureTwoTone:      "two_tone",
+	TextureRainbow:      "rainbow",
+	TextureUnpaired:     "unpaired",
+	TexturePaired:       "paired",
+	TextureTrips:        "trips",
+	TextureConnected:    "connected",
+	TextureDisconnected: "disconnected",
+	TextureAceHigh:      "ace_high",
+	TextureBroadway:     "broadway",
+	TextureMiddle:       "middle",
+	TextureLow:          "low",
+}
+
+// String はテクスチャの名前を返します（"monotone", "two_tone"など）
+func (t TextureTag) String() string {
+	if name, ok := textureTagNames[t]; ok {
+		return name
+	}
+	return fmt.Sprintf("TextureTag(%d)", int(t))
+}
+
+// category はテクスチャのカテゴリを返します
+func (t TextureTag) category() int {
+	switch {
+	case t <= TextureRainbow:
+		return textureCategorySuits
+	case t <= TextureTrips:
+		return textureCategoryPairing
+	case t <= TextureDisconnected:
+		return textureCategoryConnectedness
+	default:
+		return textureCategoryHighCard
+	}
+}
+
+// BoardTexture はフロップのテクスチャです
+type BoardTexture struct {
+	Suits         TextureTag // monotone / two_tone / rainbow
+	Pairing       TextureTag // unpaired / paired / trips
+	Connectedness TextureTag // connected / disconnected
+	HighCard      TextureTag // ace_high / broadway / middle / low
+}
+
+// Tags はカテゴリの順にテクスチャを返します
+func (t BoardTexture) Tags() []TextureTag {
+	return []TextureTag{t.Suits, t.Pairing, t.Connectedness, t.HighCard}
+}
+
+// String はテクスチャをカンマ区切りで返します（"two_tone,unpaired,connected,broadway"の形式）
+func (t BoardTexture) String() string {
+	names := make([]string, 0, textureCategoryCount)
+	for _, tag := range t.Tags() {
+		names = append(names, tag.String())
+	}
+	return strings.Join(names, ",")
+}
+
+// ClassifyBoardTexture はフロップのテクスチャを分類します
+func ClassifyBoardTexture(flop []poker.Card) (BoardTexture, error) {
+	if len(flop) != 3 {
+		return BoardTexture{}, fmt.Errorf("flop must have 3 cards, got %d", len(flop))
+	}
+	if HasCardDuplicates(flop) {
+		return BoardTexture{}, fmt.Errorf("duplicate cards detected")
+	}
+	return classifyBoardTexture(flop), nil
+}
+
+// classifyBoardTexture はClassifyBoardTextureの本体です（入力は検証済み）
+func classifyBoardTexture(flop []poker.Card) BoardTexture {
+	var texture BoardTexture
+
+	// スート
+	suits := make(map[int32]bool, 3)
+	for _, card := range flop {
+		suits[card.Suit()] = true
+	}
+	switch len(suits) {
+	case 1:
+		texture.Suits = TextureMonotone
+	case 2:
+		texture.Suits = TextureTwoTone
+	default:
+		texture.Suits = TextureRainbow
+	}
+
+	// ペア（ランクのビットで異なるランクの数を数える）
+	var rankBits int32
+	highRank := int32(0)
+	for _, card := range flop {
+		rankBits |= 1 << card.Rank()
+		if card.Rank() > highRank {
+			highRank = card.Rank()
+		}
+	}
+	distinctRanks := 0
+	for bits := rankBits; bits != 0; bits &= bits - 1 {
+		distinctRanks++
+	}
+	switch distinctRanks {
+	case 3:
+		texture.Pairing = TextureUnpaired
+	case 2:
+		texture.Pairing = TexturePaired
+	default:
+		texture.Pairing = TextureTrips
+	}
+
+	// コネクト（Aはローとしても扱い、3ランクが連続する5ランクの範囲に収まるか）
+	texture.Connectedness = TextureDisconnected
+	if distinctRanks == 3 {
+		candidates := []int32{rankBits}
+		if rankBits&(1<<12) != 0 {
+			// Aをローとして扱う（A-2-3-4-5）場合のランクのビット
+			candidates = append(candidates, (rankBits&^(1<<12))<<1|1)
+		}
+		for _, bits := range candidates {
+			for low := 0; low <= 9; low++ {
+				if bits&^(0x1f<<low) == 0 {
+					texture.Connectedness = TextureConnected
+				}
+			}
+		}
+	}
+
+	// ハイカード（ランクは2=0〜A=12）
+	switch {
+	case highRank == 12:
+		texture.HighCard = TextureAceHigh
+	case highRank >= 8:
+		texture.HighCard = TextureBroadway
+	case highRank >= 5:
+		texture.HighCard = TextureMiddle
+	default:
+		texture.HighCard = TextureLow
+	}
+	return texture
+}
+
+// BoardTextureFilter はフロップのテクスチャの条件です
+// 同じカテゴリのタグはいずれか（OR）、異なるカテゴリのタグはすべて（AND）を満たすフロップが一致します
+type BoardTextureFilter struct {
+	allowed [textureCategoryCount][]TextureTag
+}
+
+// ParseBoardTextureFilter はカンマ区切りのテクスチャ（"monotone,connected"など）を解析します
+// 空文字列はすべてのフロップに一致する条件になります
+func ParseBoardTextureFilter(s string) (BoardTextureFilter, error) {
+	var filter BoardTextureFilter
+	for _, name := range strings.Split(s, ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+		tag, ok := textureTagByName(name)
+		if !ok {
+			return BoardTextureFilter{}, fmt.Errorf("unknown board texture: %s", name)
+		}
+		category := tag.category()
+		if !containsTextureTag(filter.allowed[category], tag) {
+			filter.allowed[category] = append(filter.allowed[category], tag)
+		}
+	}
+	return filter, nil
+}
+
+// textureTagByName は名前からテクスチャを返します
+func textureTagByName(name string) (TextureTag, bool) {
+	for tag, tagName := range textureTagNames {
+		if tagName == name {
+			return tag, true
+		}
+	}
+	return 0, false
+}
+
+// containsTextureTag はtagsにtagが含まれるかを返します
+func containsTextureTag(tags []TextureTag, tag TextureTag) bool {
+	for _, t := range tags {
+		if t == tag {
+			return true
+		}
+	}
+	return false
+}
+
+// IsEmpty は条件が指定されていないかを返します
+func (f BoardTextureFilter) IsEmpty() bool {
+	for _, tags := range f.allowed {
+		if len(tags) > 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// Matches はテクスチャが条件を満たすかを返します
+func (f BoardTextureFilter) Matches(texture BoardTexture) bool {
+	for category, tag := range texture.Tags() {
+		allowed := f.allowed[category]
+		if len(allowed) > 0 && !containsTextureTag(allowed, tag) {
+			return false
+		}
+	}
+	return true
+}
+
+// String は条件をカンマ区切りで返します
+func (f BoardTextureFilter) String() string {
+	var names []string
+	for _, tags := range f.allowed {
+		for _, tag := range tags {
+			names = append(names, tag.String())
+		}
+	}
+	return strings.Join(names, ",")
+}
+
+// RandomFlopWithTexture はdeckから条件を満たすフロップを一様にランダムに選びます
+// 条件を満たすフロップをすべて列挙してから選ぶため、deckの順序とrngが同じであれば同じフロップになります
+// 条件を満たすフロップがない場合（"trips,connected"など）はエラーを返します
+func RandomFlopWithTexture(rng *rand.Rand, deck []poker.Card, filter BoardTextureFilter) ([]poker.Card, error) {
+	var candidates [][3]poker.Card
+	flop := make([]poker.Card, 3)
+	for i := 0; i < len(deck); i++ {
+		for j := i + 1; j < len(deck); j++ {
+			for k := j + 1; k < len(deck); k++ {
+				flop[0], flop[1], flop[2] = deck[i], deck[j], deck[k]
+				if filter.Matches(classifyBoardTexture(flop)) {
+					candidates = append(candidates, [3]poker.Card{deck[i], deck[j], deck[k]})
+				}
+			}
+		}
+	}
+	if len(candidates) == 0 {
+		return nil, fmt.Errorf("no flop matches board texture %q", filter)
+	}
+
+	selected := candidates[randOrDefault(rng).Intn(len(candidates))]
+	return selected[:], nil
+}
@@ -0,0 +1,107 @@
+package poker
+
+import (
+	"testing"
+)
+
+func TestClassifyBoardTexture(t *testing.T) {
+	testCases := []struct {
+		flop     []string
+		expected string
+	}{
+		{[]string{"Ah", "Kh", "Qh"}, "monotone,unpaired,connected,ace_high"},
+		{[]string{"As", "2d", "3c"}, "rainbow,unpaired,connected,ace_high"}, // Aはローとしても扱う
+		{[]string{"Ks", "9s", "2d"}, "two_tone,unpaired,disconnected,broadway"},
+		{[]string{"9c", "8d", "5h"}, "rainbow,unpaired,connected,middle"},
+		{[]string{"9c", "8d", "4h"}, "rainbow,unpaired,disconnected,middle"},
+		{[]string{"6c", "6d", "5c"}, "two_tone,paired,disconnected,low"},
+		{[]string{"7c", "7d", "7h"}, "rainbow,trips,disconnected,middle"},
+	}
+
+	for _, tc := range testCases {
+		texture, err := ClassifyBoardTexture(cards(tc.flop...))
+		if err != nil {
+			t.Fatalf("%v: expected no error, got %v", tc.flop, err)
+		}
+		if texture.String() != tc.expected {
+			t.Errorf("%v: expected %s, got %s", tc.flop, tc.expected, texture)
+		}
+	}
+
+	if _, err := ClassifyBoardTexture(cards("Ah", "Kh", "Qh", "Jh")); err == nil {
+		t.Error("Expected error for a 4-card board, got nil")
+	}
+}
+
+func TestBoardTextureFilter(t *testing.T) {
+	// テストケース1: 同じカテゴリはOR、異なるカテゴリはANDで判定する
+	t.Run("Matches", func(t *testing.T) {
+		filter, err := ParseBoardTextureFilter("Monotone, two_tone,connected")
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if filter.String() != "monotone,two_tone,connected" || filter.IsEmpty() {
+			t.Errorf("Unexpected filter: %s", filter)
+		}
+		for flop, expected := range map[[3]string]bool{
+			{"Ah", "Kh", "Qh"}: true,
+			{"9c", "8c", "5h"}: true,
+			{"9c", "8d", "5h"}: false, // レインボー
+			{"Kh", "9h", "2h"}: false, // コネクトしていない
+		} {
+			texture, _ := ClassifyBoardTexture(cards(flop[:]...))
+			if filter.Matches(texture) != expected {
+				t.Errorf("%v (%s): expected %v", flop, texture, expected)
+			}
+		}
+
+		empty, err := ParseBoardTextureFilter("")
+		if err != nil || !empty.IsEmpty() {
+			t.Errorf("Expected an empty filter, got %s (%v)", empty, err)
+		}
+	})
+
+	// テストケース2: 不明なテクスチャはエラー
+	t.Run("Unknown texture", func(t *testing.T) {
+		if _, err := ParseBoardTextureFilter("monotone,wet"); err == nil {
+			t.Error("Expected error for an unknown texture, got nil")
+		}
+	})
+}
+
+func TestRandomFlopWithTexture(t *testing.T) {
+	hand := cards("As", "Ad", "Kh", "Qc")
+	deck := RemainingDeck(hand)
+	filter, err := ParseBoardTextureFilter("monotone,connected")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	rng := NewRand(1)
+	for i := 0; i < 20; i++ {
+		flop, err := RandomFlopWithTexture(rng, deck, filter)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		texture, err := ClassifyBoardTexture(flop)
+		if err != nil || !filter.Matches(texture) {
+			t.Errorf("Expected a monotone connected flop, got %s (%s)", GenerateBoardString(flop), texture)
+		}
+		if HasCardDuplicates(hand, flop) {
+			t.Errorf("Expected a flop without hero cards, got %s", GenerateBoardString(flop))
+		}
+	}
+
+	// 同じシードからは同じフロップになる
+	first, _ := RandomFlopWithTexture(NewRand(7), deck, filter)
+	second, _ := RandomFlopWithTexture(NewRand(7), deck, filter)
+	if GenerateBoardString(first) != GenerateBoardString(second) {
+		t.Errorf("Expected identical flops for the same seed, got %s and %s", GenerateBoardString(first), GenerateBoardString(second))
+	}
+
+	// 条件を満たすフロップがない場合はエラー
+	impossible, _ := ParseBoardTextureFilter("trips,connected")
+	if _, err := RandomFlopWithTexture(NewRand(1), deck, impossible); err == nil {
+		t.Error("Expected error for an impossible texture, got nil")
+	}
+}
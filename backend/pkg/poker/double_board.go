@@ -0,0 +1,358 @@
+package poker
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math"
+	"math/rand"
+	"runtime"
+	"sync"
+
+	"github.com/chehsunliu/poker"
+)
+
+// DoubleBoardShowdown はダブルボード（ボムポットなど）のショーダウン結果を表します
+// ポットは2つのボードで半分ずつ分け合い、各ボードの半分をそのボードの勝者が獲得します
+type DoubleBoardShowdown struct {
+	Winners   [2]string // 各ボードの勝者（"yourHand", "opponentHand", "tie"）
+	YourShare float64   // yourHandが獲得するポット全体の割合（0, 0.25, 0.5, 0.75, 1）
+}
+
+// PotShares はポットの獲得割合の内訳を表します（すべて%）
+type PotShares struct {
+	Equity float64 `json:"equity"` // 獲得ポットの期待値
+	Scoop  float64 `json:"scoop"`  // ポット全体を単独で獲得する割合
+	Half   float64 `json:"half"`   // ポットの一部を獲得する割合（片方のボードのみの獲得・チョップを含む）
+	Lose   float64 `json:"lose"`   // ポットを獲得できない割合
+}
+
+// DoubleBoardEquity はダブルボードのエクイティを表します
+// Totalはポット全体、Boardsは各ボードのポット（全体の半分）に対する内訳です
+type DoubleBoardEquity struct {
+	Total      PotShares    `json:"total"`
+	Boards     [2]PotShares `json:"boards"`
+	StdError   float64      `json:"std_error"`  // 獲得ポットの期待値の標準誤差（%）。全数計算の場合は0
+	Samples    int          `json:"samples"`    // 計算に使用したランアウト（2つのボードの組）の数
+	Exhaustive bool         `json:"exhaustive"` // サンプリングせずに全数計算した結果か
+}
+
+// EquityResult は獲得ポットの期待値を通常のエクイティ計算の結果として返します
+// レンジ全体の集計（CombineEquityResults）に使用するため、勝敗数は含みません
+func (e DoubleBoardEquity) EquityResult() EquityResult {
+	return EquityResult{
+		Equity:     e.Total.Equity,
+		StdError:   e.StdError,
+		Samples:    e.Samples,
+		Exhaustive: e.Exhaustive,
+	}.withConfidenceInterval()
+}
+
+// doubleBoardJudge はダブルボードで使用するゲームタイプの勝敗判定関数を返します（PLO4・PLO5のみ）
+func doubleBoardJudge(variant Variant) (func(yourHand []poker.Card, opponentHand []poker.Card, board []poker.Card) string, error) {
+	switch variant {
+	case VariantPLO4:
+		return JudgeWinnerPLO, nil
+	case VariantPLO5:
+		return JudgeWinnerPLO5, nil
+	default:
+		return nil, fmt.Errorf("double board supports PLO4 and PLO5, got %s", variant)
+	}
+}
+
+// JudgeWinnerDoubleBoard はPLO4・PLO5のダブルボードの勝敗を判定します
+// 各ボードはJudgeWinnerPLO・JudgeWinnerPLO5で判定し、ボードごとにポットの半分を分け合います
+func JudgeWinnerDoubleBoard(yourHand []poker.Card, opponentHand []poker.Card, firstBoard []poker.Card, secondBoard []poker.Card) (DoubleBoardShowdown, error) {
+	variant, err := DetectVariant(yourHand, opponentHand)
+	if err != nil {
+		return DoubleBoardShowdown{}, err
+	}
+	judge, err := doubleBoardJudge(variant)
+	if err != nil {
+		return DoubleBoardShowdown{}, err
+	}
+	return judgeDoubleBoard(judge, yourHand, opponentHand, firstBoard, secondBoard), nil
+}
+
+func judgeDoubleBoard(judge func([]poker.Card, []poker.Card, []poker.Card) string, yourHand []poker.Card, opponentHand []poker.Card, firstBoard []poker.Card, secondBoard []poker.Card) DoubleBoardShowdown {
+	showdown := DoubleBoardShowdown{
+		Winners: [2]string{
+			judge(yourHand, opponentHand, firstBoard),
+			judge(yourHand, opponentHand, secondBoard),
+		},
+	}
+	showdown.YourShare = potShare(showdown.Winners[0])/2 + potShare(showdown.Winners[1])/2
+	return showdown
+}
+
+// potShareCounter はポットの獲得割合を集計します
+type potShareCounter struct {
+	share, shareSquares, scoop, half, lose, total float64
+}
+
+func (c *potShareCounter) add(share float64) {
+	c.total++
+	c.share += share
+	c.shareSquares += share * share
+	switch {
+	case share == 1:
+		c.scoop++
+	case share > 0:
+		c.half++
+	default:
+		c.lose++
+	}
+}
+
+func (c potShareCounter) result() PotShares {
+	if c.total == 0 {
+		return PotShares{}
+	}
+	return PotShares{
+		Equity: c.share / c.total * 100,
+		Scoop:  c.scoop / c.total * 100,
+		Half:   c.half / c.total * 100,
+		Lose:   c.lose / c.total * 100,
+	}
+}
+
+// doubleBoardAccumulator はランアウトごとのダブルボードのショーダウン結果を集計します
+type doubleBoardAccumulator struct {
+	total  potShareCounter
+	boards [2]potShareCounter
+}
+
+func (a *doubleBoardAccumulator) add(showdown DoubleBoardShowdown) {
+	a.total.add(showdown.YourShare)
+	for i, winner := range showdown.Winners {
+		a.boards[i].add(potShare(winner))
+	}
+}
+
+// result は集計した結果を返します
+// サンプリングの場合は1回あたりの獲得割合の分散から標準誤差を求めます
+func (a *doubleBoardAccumulator) result(exhaustive bool) DoubleBoardEquity {
+	result := DoubleBoardEquity{
+		Total:      a.total.result(),
+		Boards:     [2]PotShares{a.boards[0].result(), a.boards[1].result()},
+		Samples:    int(a.total.total),
+		Exhaustive: exhaustive,
+	}
+	if !exhaustive && a.total.total > 0 {
+		n := a.total.total
+		mean := a.total.share / n
+		variance := math.Max(0, a.total.shareSquares/n-mean*mean)
+		result.StdError = math.Sqrt(variance/n) * 100
+	}
+	return result
+}
+
+// validateDoubleBoardHands はダブルボードのエクイティ計算の入力を検証し、勝敗判定関数を返します
+// 2つのボードは同じデッキから配られるため、ボード間でもカードが重複してはいけません
+func validateDoubleBoardHands(yourHand []poker.Card, opponentHand []poker.Card, firstBoard []poker.Card, secondBoard []poker.Card, deadCards []poker.Card) (func([]poker.Card, []poker.Card, []poker.Card) string, error) {
+	variant, err := DetectVariant(yourHand, opponentHand)
+	if err != nil {
+		return nil, err
+	}
+	judge, err := doubleBoardJudge(variant)
+	if err != nil {
+		return nil, err
+	}
+	if HasCardDuplicates(yourHand, opponentHand, firstBoard, secondBoard, deadCards) {
+		return nil, fmt.Errorf("duplicate cards detected")
+	}
+	if err := ValidateBoardSize(firstBoard); err != nil {
+		return nil, err
+	}
+	if err := ValidateBoardSize(secondBoard); err != nil {
+		return nil, err
+	}
+	return judge, nil
+}
+
+// CalculateHandVsHandDoubleBoardEquity はPLO4・PLO5のダブルボードのエクイティを全数計算し、
+// ポット全体と各ボードのスクープ・ハーフ・負けの内訳を返します
+// 組み合わせ数が非常に多くなるため、全数計算は2つのボードともフロップ以降の場合のみ対応します
+func CalculateHandVsHandDoubleBoardEquity(yourHand []poker.Card, opponentHand []poker.Card, firstBoard []poker.Card, secondBoard []poker.Card) (DoubleBoardEquity, error) {
+	return CalculateHandVsHandDoubleBoardEquityWithDeadCards(yourHand, opponentHand, firstBoard, secondBoard, nil)
+}
+
+// CalculateHandVsHandDoubleBoardEquityWithDeadCards はデッドカードを考慮したCalculateHandVsHandDoubleBoardEquityです
+// デッドカード（フォールドしたハンドや見えたカード）はデッキから除かれ、どちらのボードにも配られません
+func CalculateHandVsHandDoubleBoardEquityWithDeadCards(yourHand []poker.Card, opponentHand []poker.Card, firstBoard []poker.Card, secondBoard []poker.Card, deadCards []poker.Card) (DoubleBoardEquity, error) {
+	judge, err := validateDoubleBoardHands(yourHand, opponentHand, firstBoard, secondBoard, deadCards)
+	if err != nil {
+		return DoubleBoardEquity{}, err
+	}
+	if len(firstBoard) < 3 || len(secondBoard) < 3 {
+		return DoubleBoardEquity{}, fmt.Errorf("exhaustive double board equity requires at least a flop on both boards, use Monte Carlo instead")
+	}
+
+	remainingDeck := RemainingDeck(yourHand, opponentHand, firstBoard, secondBoard, deadCards)
+	if len(remainingDeck) < CardsToComplete(firstBoard)+CardsToComplete(secondBoard) {
+		return DoubleBoardEquity{}, fmt.Errorf("insufficient remaining cards")
+	}
+	secondDeck := make([]poker.Card, 0, len(remainingDeck))
+
+	var accumulator doubleBoardAccumulator
+	forEachRunout(firstBoard, remainingDeck, func(finalFirstBoard []poker.Card) {
+		// 1つ目のボードに配ったカードを除いたデッキから2つ目のボードを配る
+		dealt := finalFirstBoard[len(firstBoard):]
+		secondDeck = secondDeck[:0]
+		for _, card := range remainingDeck {
+			if !containsCard(dealt, card) {
+				secondDeck = append(secondDeck, card)
+			}
+		}
+		forEachRunout(secondBoard, secondDeck, func(finalSecondBoard []poker.Card) {
+			accumulator.add(judgeDoubleBoard(judge, yourHand, opponentHand, finalFirstBoard, finalSecondBoard))
+		})
+	})
+
+	return accumulator.result(true), nil
+}
+
+// containsCard はcardsにcardが含まれるかを返します
+func containsCard(cards []poker.Card, card poker.Card) bool {
+	for _, c := range cards {
+		if c == card {
+			return true
+		}
+	}
+	return false
+}
+
+// CalculateHandVsHandDoubleBoardEquityMonteCarlo はPLO4・PLO5のダブルボードのエクイティをモンテカルロシミュレーションで計算します
+// 2つのボードの不足しているカードは同じデッキから重複なく配ります
+// rngに同じシードの乱数生成器を渡すと同じ結果を返します（nilの場合は現在時刻をシードとします）
+func CalculateHandVsHandDoubleBoardEquityMonteCarlo(yourHand []poker.Card, opponentHand []poker.Card, firstBoard []poker.Card, secondBoard []poker.Card, iterations int, rng *rand.Rand) (DoubleBoardEquity, error) {
+	return CalculateHandVsHandDoubleBoardEquityMonteCarloWithDeadCards(yourHand, opponentHand, firstBoard, secondBoard, nil, iterations, rng)
+}
+
+// CalculateHandVsHandDoubleBoardEquityMonteCarloWithDeadCards はデッドカードを考慮したCalculateHandVsHandDoubleBoardEquityMonteCarloです
+func CalculateHandVsHandDoubleBoardEquityMonteCarloWithDeadCards(yourHand []poker.Card, opponentHand []poker.Card, firstBoard []poker.Card, secondBoard []poker.Card, deadCards []poker.Card, iterations int, rng *rand.Rand) (DoubleBoardEquity, error) {
+	judge, err := validateDoubleBoardHands(yourHand, opponentHand, firstBoard, secondBoard, deadCards)
+	if err != nil {
+		return DoubleBoardEquity{}, err
+	}
+	if iterations <= 0 {
+		return DoubleBoardEquity{}, fmt.Errorf("iterations must be positive, got %d", iterations)
+	}
+
+	remainingDeck := RemainingDeck(yourHand, opponentHand, firstBoard, secondBoard, deadCards)
+	firstMissing := CardsToComplete(firstBoard)
+	if len(remainingDeck) < firstMissing+CardsToComplete(secondBoard) {
+		return DoubleBoardEquity{}, fmt.Errorf("insufficient remaining cards")
+	}
+
+	// 乱数生成器を初期化（nilの場合は現在時刻をシードとする）
+	rng = randOrDefault(rng)
+
+	finalFirstBoard := make([]poker.Card, len(firstBoard)+firstMissing)
+	copy(finalFirstBoard, firstBoard)
+	finalSecondBoard := make([]poker.Card, len(secondBoard)+CardsToComplete(secondBoard))
+	copy(finalSecondBoard, secondBoard)
+
+	var accumulator doubleBoardAccumulator
+	for i := 0; i < iterations; i++ {
+		// 1つ目のボードに配ったカードはデッキの先頭に移動するため、2つ目のボードは残りから配る
+		dealRandomRunout(rng, len(firstBoard), remainingDeck, finalFirstBoard)
+		dealRandomRunout(rng, len(secondBoard), remainingDeck[firstMissing:], finalSecondBoard)
+		accumulator.add(judgeDoubleBoard(judge, yourHand, opponentHand, finalFirstBoard, finalSecondBoard))
+	}
+
+	return accumulator.result(false), nil
+}
+
+// CalculateHandVsRangeDoubleBoardEquityMonteCarlo は頻度付きレンジに対するダブルボードのエクイティを
+// ハンドごとのモンテカルロシミュレーションで並列計算します
+// 各ハンドの獲得ポットの期待値と、頻度で重み付けしたレンジ全体の結果を返します
+func CalculateHandVsRangeDoubleBoardEquityMonteCarlo(yourHand []poker.Card, opponentRange WeightedRange, firstBoard []poker.Card, secondBoard []poker.Card, iterations int, rng *rand.Rand) (map[string]float64, EquityResult, error) {
+	return CalculateHandVsRangeDoubleBoardEquityMonteCarloContext(context.Background(), yourHand, opponentRange, firstBoard, secondBoard, nil, iterations, rng, nil)
+}
+
+// CalculateHandVsRangeDoubleBoardEquityMonteCarloWithDeadCards はデッドカードを考慮したCalculateHandVsRangeDoubleBoardEquityMonteCarloです
+// デッドカードはどちらのボードにも配られず、デッドカードを含む相手ハンドはレンジから除外されます
+func CalculateHandVsRangeDoubleBoardEquityMonteCarloWithDeadCards(yourHand []poker.Card, opponentRange WeightedRange, firstBoard []poker.Card, secondBoard []poker.Card, deadCards []poker.Card, iterations int, rng *rand.Rand) (map[string]float64, EquityResult, error) {
+	return CalculateHandVsRangeDoubleBoardEquityMonteCarloContext(context.Background(), yourHand, opponentRange, firstBoard, secondBoard, deadCards, iterations, rng, nil)
+}
+
+// CalculateHandVsRangeDoubleBoardEquityMonteCarloContext はctxのキャンセル・期限に対応したCalculateHandVsRangeDoubleBoardEquityMonteCarloです
+// 各ハンドのシードはrngから入力順に生成するため、同じシードのrngからは並列実行でも同じ結果になります
+// deadCards（nil可）の扱いはCalculateHandVsRangeDoubleBoardEquityMonteCarloWithDeadCardsと同じです
+// 進捗の通知と中断時の結果はCalculateHandVsWeightedRangeEquityParallelContextと同じです
+func CalculateHandVsRangeDoubleBoardEquityMonteCarloContext(ctx context.Context, yourHand []poker.Card, opponentRange WeightedRange, firstBoard []poker.Card, secondBoard []poker.Card, deadCards []poker.Card, iterations int, rng *rand.Rand, progress ProgressFunc) (map[string]float64, EquityResult, error) {
+	if err := ValidateBoardSize(firstBoard); err != nil {
+		return nil, EquityResult{}, err
+	}
+	if err := ValidateBoardSize(secondBoard); err != nil {
+		return nil, EquityResult{}, err
+	}
+	if HasCardDuplicates(yourHand, firstBoard, secondBoard, deadCards) {
+		return nil, EquityResult{}, fmt.Errorf("duplicate cards detected")
+	}
+	variant, err := detectRangeVariant(yourHand, opponentRange)
+	if err != nil {
+		return nil, EquityResult{}, err
+	}
+	if _, err := doubleBoardJudge(variant); err != nil {
+		return nil, EquityResult{}, err
+	}
+
+	equities := make(map[string]float64)
+	handResults := newIndexedResults(len(opponentRange))
+	seeds := deriveSeeds(randOrDefault(rng), len(opponentRange))
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+
+	numCPU := runtime.NumCPU()
+	log.Printf("Using %d CPUs for double board Monte Carlo (%d iterations) against %d opponent hands", numCPU, iterations, len(opponentRange))
+	semaphore := make(chan struct{}, numCPU)
+
+	// カード重複チェック（2つのボード・デッドカードを含む）・頻度0のハンドは除外
+	var targets []int
+	for i, opponentHand := range opponentRange {
+		if opponentHand.Weight > 0 && !HasCardDuplicates(yourHand, opponentHand.Cards, firstBoard, secondBoard, deadCards) {
+			targets = append(targets, i)
+		}
+	}
+	tracker := newProgressTracker(progress, len(targets))
+
+	for _, i := range targets {
+		if !acquireSlot(ctx, semaphore) {
+			break
+		}
+		wg.Add(1)
+
+		go func(handIdx int) {
+			defer wg.Done()
+			defer func() { <-semaphore }()
+
+			opponentHand := opponentRange[handIdx]
+			handRng := rand.New(rand.NewSource(seeds[handIdx]))
+			equity, err := CalculateHandVsHandDoubleBoardEquityMonteCarloWithDeadCards(yourHand, opponentHand.Cards, firstBoard, secondBoard, deadCards, iterations, handRng)
+			if err == nil {
+				mu.Lock()
+				equities[GenerateBoardString(opponentHand.Cards)] = equity.Total.Equity
+				mu.Unlock()
+				handResults.set(handIdx, equity.EquityResult(), opponentHand.Weight)
+			}
+			tracker.advance(1)
+		}(i)
+	}
+
+	wg.Wait()
+
+	if len(equities) == 0 {
+		if err := ctx.Err(); err != nil {
+			return nil, EquityResult{}, err
+		}
+		return nil, EquityResult{}, fmt.Errorf("no valid equity calculations")
+	}
+
+	result := CombineEquityResults(handResults.collect())
+	if err := ctx.Err(); err != nil {
+		return equities, result.asPartial(), err
+	}
+	return equities, result, nil
+}
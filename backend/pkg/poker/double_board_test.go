@@ -0,0 +1,203 @@
+package poker
+
+import (
+	"math"
+	"testing"
+
+	"github.com/chehsunliu/poker"
+)
+
+func TestJudgeWinnerDoubleBoard(t *testing.T) {
+	yourHand := cards("As", "Ad", "Kh", "Qc")
+	opponentHand := cards("9s", "9d", "Jh", "Tc")
+
+	// テストケース1: ボードを1つずつ獲得するとポットの半分
+	t.Run("Split boards", func(t *testing.T) {
+		showdown, err := JudgeWinnerDoubleBoard(yourHand, opponentHand,
+			cards("Ac", "7d", "2h", "5s", "3c"), // Aのセット
+			cards("9c", "8h", "4d", "6s", "2c")) // 9のセット
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if showdown.Winners != [2]string{"yourHand", "opponentHand"} || showdown.YourShare != 0.5 {
+			t.Errorf("Expected a half pot, got %+v", showdown)
+		}
+	})
+
+	// テストケース2: PLO4・PLO5以外は対応しない
+	t.Run("Unsupported variant", func(t *testing.T) {
+		_, err := JudgeWinnerDoubleBoard(cards("As", "Ad"), cards("Ks", "Kd"),
+			cards("2c", "7d", "Th", "5s", "3c"), cards("9c", "8h", "4d", "6s", "Jc"))
+		if err == nil {
+			t.Error("Expected error for Hold'em hands, got nil")
+		}
+	})
+}
+
+func TestCalculateHandVsHandDoubleBoardEquity(t *testing.T) {
+	yourHand := cards("As", "Ad", "Kh", "Qc")
+	opponentHand := cards("9s", "9d", "Jh", "Tc")
+	firstBoard := cards("Ac", "7d", "2h", "5s")
+	secondBoard := cards("9c", "8h", "4d", "6s")
+
+	// テストケース1: 各ボードのエクイティは、もう一方のボードをデッドカードとした通常のエクイティと一致する
+	t.Run("Exhaustive turn boards", func(t *testing.T) {
+		result, err := CalculateHandVsHandDoubleBoardEquity(yourHand, opponentHand, firstBoard, secondBoard)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		// 残り36枚から1枚ずつ配る
+		if result.Samples != 36*35 || !result.Exhaustive || result.StdError != 0 {
+			t.Errorf("Expected %d exhaustive runouts, got %+v", 36*35, result)
+		}
+
+		boards := [2][]poker.Card{firstBoard, secondBoard}
+		for i := range boards {
+			single, err := CalculateHandVsHandEquityWithDeadCards(yourHand, opponentHand, boards[i], boards[1-i])
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if math.Abs(result.Boards[i].Equity-single.Equity) > 1e-9 {
+				t.Errorf("Board %d: expected equity %.4f%%, got %.4f%%", i+1, single.Equity, result.Boards[i].Equity)
+			}
+		}
+		if math.Abs(result.Total.Equity-(result.Boards[0].Equity+result.Boards[1].Equity)/2) > 1e-9 {
+			t.Errorf("Expected the total equity to be the average of both boards, got %+v", result)
+		}
+
+		for _, shares := range []PotShares{result.Total, result.Boards[0], result.Boards[1]} {
+			if math.Abs(shares.Scoop+shares.Half+shares.Lose-100) > 1e-9 {
+				t.Errorf("Expected shares to sum to 100%%, got %+v", shares)
+			}
+		}
+		// ヒーローはAのセットで1つ目のボード、相手は9のセットで2つ目のボードをほぼ獲得する
+		if result.Total.Half < 50 {
+			t.Errorf("Expected mostly half pots, got %+v", result.Total)
+		}
+	})
+
+	// テストケース2: モンテカルロは全数計算と近い値になり、同じシードで同じ結果になる
+	t.Run("Monte Carlo matches exhaustive", func(t *testing.T) {
+		expected, err := CalculateHandVsHandDoubleBoardEquity(yourHand, opponentHand, firstBoard, secondBoard)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		result, err := CalculateHandVsHandDoubleBoardEquityMonteCarlo(yourHand, opponentHand, firstBoard, secondBoard, ACCURATE_ITERATIONS, NewRand(1))
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if math.Abs(result.Total.Equity-expected.Total.Equity) > 2 || result.StdError <= 0 || result.Exhaustive {
+			t.Errorf("Expected equity around %.2f%%, got %+v", expected.Total.Equity, result)
+		}
+
+		again, _ := CalculateHandVsHandDoubleBoardEquityMonteCarlo(yourHand, opponentHand, firstBoard, secondBoard, ACCURATE_ITERATIONS, NewRand(1))
+		if again != result {
+			t.Errorf("Expected identical results for the same seed, got %+v and %+v", result, again)
+		}
+	})
+
+	// テストケース3: プリフロップ（ボムポット）のPLO5はモンテカルロのみ対応する
+	t.Run("Preflop PLO5 bomb pot", func(t *testing.T) {
+		yourHand := cards("As", "Ad", "Kh", "Kc", "2d")
+		opponentHand := cards("7s", "6s", "5h", "4h", "3c")
+
+		if _, err := CalculateHandVsHandDoubleBoardEquity(yourHand, opponentHand, nil, nil); err == nil {
+			t.Error("Expected error for exhaustive preflop double board, got nil")
+		}
+		result, err := CalculateHandVsHandDoubleBoardEquityMonteCarlo(yourHand, opponentHand, nil, nil, FAST_ITERATIONS, NewRand(1))
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if result.Samples != FAST_ITERATIONS || result.Total.Equity <= 0 || result.Total.Equity >= 100 {
+			t.Errorf("Expected a preflop equity between 0 and 100, got %+v", result)
+		}
+	})
+
+	// テストケース4: 2つのボードは同じデッキから配られるため、ボード間の重複はエラー
+	t.Run("Duplicate cards across boards", func(t *testing.T) {
+		if _, err := CalculateHandVsHandDoubleBoardEquity(yourHand, opponentHand, firstBoard, cards("Ac", "8h", "4d")); err == nil {
+			t.Error("Expected error for a card on both boards, got nil")
+		}
+		if _, err := CalculateHandVsHandDoubleBoardEquityMonteCarlo(yourHand, opponentHand, firstBoard, secondBoard, 0, nil); err == nil {
+			t.Error("Expected error for zero iterations, got nil")
+		}
+	})
+
+	// テストケース5: デッドカードはどちらのボードにも配られない
+	t.Run("Dead cards", func(t *testing.T) {
+		deadCards := cards("Ks", "Kd")
+		result, err := CalculateHandVsHandDoubleBoardEquityWithDeadCards(yourHand, opponentHand, firstBoard, secondBoard, deadCards)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		// 残り34枚から1枚ずつ配る
+		if result.Samples != 34*33 {
+			t.Errorf("Expected %d runouts, got %+v", 34*33, result)
+		}
+		single, err := CalculateHandVsHandEquityWithDeadCards(yourHand, opponentHand, firstBoard, append(cards("Ks", "Kd"), secondBoard...))
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if math.Abs(result.Boards[0].Equity-single.Equity) > 1e-9 {
+			t.Errorf("Expected first board equity %.4f%%, got %.4f%%", single.Equity, result.Boards[0].Equity)
+		}
+
+		mcResult, err := CalculateHandVsHandDoubleBoardEquityMonteCarloWithDeadCards(yourHand, opponentHand, firstBoard, secondBoard, deadCards, ACCURATE_ITERATIONS, NewRand(1))
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if math.Abs(mcResult.Total.Equity-result.Total.Equity) > 2 {
+			t.Errorf("Expected Monte Carlo equity around %.2f%%, got %+v", result.Total.Equity, mcResult)
+		}
+
+		if _, err := CalculateHandVsHandDoubleBoardEquityWithDeadCards(yourHand, opponentHand, firstBoard, secondBoard, cards("9c")); err == nil {
+			t.Error("Expected error for a dead card on the second board, got nil")
+		}
+	})
+}
+
+func TestCalculateHandVsRangeDoubleBoardEquityMonteCarlo(t *testing.T) {
+	yourHand := cards("As", "Ad", "Kh", "Qc")
+	firstBoard := cards("Ac", "7d", "2h")
+	secondBoard := cards("9c", "8h", "4d")
+	opponentRange := WeightedRange{
+		{Cards: cards("9s", "9d", "Jh", "Tc"), Weight: 1.0},
+		{Cards: cards("Ks", "Kd", "Jc", "Td"), Weight: 0.5},
+		{Cards: cards("8s", "8d", "Js", "Th"), Weight: 1.0},
+		{Cards: cards("8h", "7c", "Jd", "Ts"), Weight: 1.0}, // 2つ目のボードと重複するため除外
+	}
+
+	equities, result, err := CalculateHandVsRangeDoubleBoardEquityMonteCarlo(yourHand, opponentRange, firstBoard, secondBoard, FAST_ITERATIONS, NewRand(1))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(equities) != 3 {
+		t.Fatalf("Expected 3 hands, got %v", equities)
+	}
+	if _, ok := equities["8h7cJdTs"]; ok {
+		t.Errorf("Expected the hand overlapping the second board to be excluded, got %v", equities)
+	}
+	if result.Samples != 3*FAST_ITERATIONS || result.Equity <= 0 || result.Equity >= 100 {
+		t.Errorf("Expected a combined result over 3 hands, got %+v", result)
+	}
+
+	// 同じシードからは同じ結果になる
+	regenerated, _, err := CalculateHandVsRangeDoubleBoardEquityMonteCarlo(yourHand, opponentRange, firstBoard, secondBoard, FAST_ITERATIONS, NewRand(1))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	for hand, equity := range equities {
+		if regenerated[hand] != equity {
+			t.Errorf("%s: expected %.4f%%, got %.4f%%", hand, equity, regenerated[hand])
+		}
+	}
+
+	// デッドカードを含む相手ハンドは除外される
+	deadEquities, _, err := CalculateHandVsRangeDoubleBoardEquityMonteCarloWithDeadCards(yourHand, opponentRange, firstBoard, secondBoard, cards("Kd"), FAST_ITERATIONS, NewRand(1))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if _, ok := deadEquities["KsKdJcTd"]; ok || len(deadEquities) != 2 {
+		t.Errorf("Expected the hand with a dead card to be excluded, got %v", deadEquities)
+	}
+}
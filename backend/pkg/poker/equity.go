@@ -1,6 +1,7 @@
 package poker
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"runtime"
@@ -10,59 +11,125 @@ import (
 )
 
 // CalculateHandVsHandEquity calculates the equity between two hands
-// Returns equity value and whether it was a cache hit
-func CalculateHandVsHandEquity(yourHand []poker.Card, opponentHand []poker.Card, board []poker.Card) (float64, bool) {
+// The board may have 0 (preflop), 3 (flop), 4 (turn) or 5 (river) cards; only the missing cards are dealt
+// Returns the win/tie/loss counts over every runout; the result is exhaustive, so it has no standard error
+// When an equity cache is set with SetEquityCache, results are looked up by canonical matchup before enumerating
+func CalculateHandVsHandEquity(yourHand []poker.Card, opponentHand []poker.Card, board []poker.Card) (EquityResult, error) {
+	return CalculateHandVsHandEquityWithDeadCards(yourHand, opponentHand, board, nil)
+}
+
+// CalculateHandVsHandEquityWithDeadCards is CalculateHandVsHandEquity with dead cards
+// Dead cards (folded or exposed cards) are removed from the deck and never dealt to the board
+// The equity cache is keyed without dead cards, so it is only used when deadCards is empty
+func CalculateHandVsHandEquityWithDeadCards(yourHand []poker.Card, opponentHand []poker.Card, board []poker.Card, deadCards []poker.Card) (EquityResult, error) {
 	// Check for duplicate cards
-	if HasCardDuplicates(yourHand, opponentHand, board) {
-		return -1, false // Return -1 to indicate invalid hand due to duplicate cards
+	if HasCardDuplicates(yourHand, opponentHand, board, deadCards) {
+		return EquityResult{}, fmt.Errorf("duplicate cards detected")
 	}
 
-	// Generate the full deck
-	deck := poker.NewDeck()
-	fullDeck := deck.Draw(52) // Draw all 52 cards from the deck
+	// Check the board size (0, 3, 4 or 5 cards)
+	if err := ValidateBoardSize(board); err != nil {
+		return EquityResult{}, err
+	}
 
-	usedCards := append(yourHand, opponentHand...)
-	usedCards = append(usedCards, board...)
+	// Both hands must belong to the same variant
+	variant, err := DetectVariant(yourHand, opponentHand)
+	if err != nil {
+		return EquityResult{}, err
+	}
 
-	remainingDeck := []poker.Card{}
-	for _, card := range fullDeck {
-		used := false
-		for _, usedCard := range usedCards {
-			if card == usedCard {
-				used = true
-				break
-			}
-		}
-		if !used {
-			remainingDeck = append(remainingDeck, card)
+	// Reuse a cached result for this matchup or any suit-isomorphic one
+	cache := CurrentEquityCache()
+	if len(deadCards) > 0 {
+		cache = nil
+	}
+	var cacheKey string
+	if cache != nil {
+		cacheKey = EquityCacheKey(variant, yourHand, opponentHand, board)
+		if result, ok := cache.Get(cacheKey); ok {
+			return result, nil
 		}
 	}
 
-	// Calculate equity since it wasn't found in DynamoDB
-	totalOutcomes := 0.0
-	winCount := 0.0
+	// Build the deck without the known and dead cards
+	remainingDeck := RemainingDeck(yourHand, opponentHand, board, deadCards)
+	if len(remainingDeck) < CardsToComplete(board) {
+		return EquityResult{}, fmt.Errorf("insufficient remaining cards")
+	}
 
-	for i := 0; i < len(remainingDeck); i++ {
-		for j := i + 1; j < len(remainingDeck); j++ {
-			finalBoard := append(board, remainingDeck[i], remainingDeck[j])
-			winner := JudgeWinner(yourHand, opponentHand, finalBoard)
-			if winner == "yourHand" {
-				winCount += 1
-			} else if winner == "tie" {
-				winCount += 0.5
-			}
-			totalOutcomes += 1
-		}
+	// Calculate equity by enumerating every runout
+	// The evaluator scores the known board once and only the new combos for each turn and river card
+	var outcomes outcomeCounter
+	evaluator := NewShowdownEvaluator(variant, yourHand, opponentHand)
+	evaluator.SetBoard(board)
+	evaluator.EnumerateRunouts(remainingDeck, outcomes.add)
+
+	result := outcomes.result(true)
+	if cache != nil {
+		cache.Put(cacheKey, result)
 	}
+	return result, nil
+}
 
-	calculatedEquity := (winCount / totalOutcomes) * 100
-	return calculatedEquity, false
+// forEachRunout は残りデッキから不足しているボードカードを全通り配り、完成したボードごとにfnを呼び出します
+// fnに渡されるスライスは呼び出しごとに再利用されるため、保持する場合はコピーしてください
+func forEachRunout(board []poker.Card, remainingDeck []poker.Card, fn func(finalBoard []poker.Card)) {
+	missing := CardsToComplete(board)
+	finalBoard := make([]poker.Card, len(board)+missing)
+	copy(finalBoard, board)
+
+	var deal func(start int, pos int)
+	deal = func(start int, pos int) {
+		if pos == len(finalBoard) {
+			fn(finalBoard)
+			return
+		}
+		for i := start; i <= len(remainingDeck)-(len(finalBoard)-pos); i++ {
+			finalBoard[pos] = remainingDeck[i]
+			deal(i+1, pos+1)
+		}
+	}
+	deal(0, len(board))
 }
 
 // CalculateHandVsRangeEquityParallel は、1つのハンドと複数のハンドのレンジに対してエクイティを並列計算する
-func CalculateHandVsRangeEquityParallel(yourHand []poker.Card, opponentHands [][]poker.Card, board []poker.Card) (map[string]float64, error) {
+func CalculateHandVsRangeEquityParallel(yourHand []poker.Card, opponentHands [][]poker.Card, board []poker.Card) (map[string]float64, EquityResult, error) {
+	return CalculateHandVsWeightedRangeEquityParallel(yourHand, NewUniformRange(opponentHands), board)
+}
+
+// CalculateHandVsWeightedRangeEquityParallel は、頻度付きレンジに対してエクイティを並列計算し、
+// 各ハンドのエクイティとレンジ全体の結果（頻度で重み付けした平均エクイティ・勝敗数）を返す
+func CalculateHandVsWeightedRangeEquityParallel(yourHand []poker.Card, opponentRange WeightedRange, board []poker.Card) (map[string]float64, EquityResult, error) {
+	return CalculateHandVsWeightedRangeEquityParallelContext(context.Background(), yourHand, opponentRange, board, nil, nil)
+}
+
+// CalculateHandVsWeightedRangeEquityParallelWithDeadCards はデッドカードを考慮したCalculateHandVsWeightedRangeEquityParallelです
+// デッドカードの扱いはCalculateHandVsWeightedRangeEquityParallelContextと同じです
+func CalculateHandVsWeightedRangeEquityParallelWithDeadCards(yourHand []poker.Card, opponentRange WeightedRange, board []poker.Card, deadCards []poker.Card) (map[string]float64, EquityResult, error) {
+	return CalculateHandVsWeightedRangeEquityParallelContext(context.Background(), yourHand, opponentRange, board, deadCards, nil)
+}
+
+// CalculateHandVsWeightedRangeEquityParallelContext はctxのキャンセル・期限に対応したCalculateHandVsWeightedRangeEquityParallelです
+// deadCards（nil可）はランアウトに配られず、デッドカードを含む相手ハンドはレンジから除外されます
+// ハンドが1つ完了するたびにprogress（nil可）に進捗を通知します
+// ctxが中断された場合は新しいハンドの計算を開始せず、実行中のハンドの完了を待って
+// それまでに計算できたハンドの結果（Partial=true）とctx.Err()を返します
+func CalculateHandVsWeightedRangeEquityParallelContext(ctx context.Context, yourHand []poker.Card, opponentRange WeightedRange, board []poker.Card, deadCards []poker.Card, progress ProgressFunc) (map[string]float64, EquityResult, error) {
+	if err := ValidateBoardSize(board); err != nil {
+		return nil, EquityResult{}, err
+	}
+	if HasCardDuplicates(yourHand, board, deadCards) {
+		return nil, EquityResult{}, fmt.Errorf("duplicate cards detected")
+	}
+	// レンジ内のハンドはヒーローと同じゲームタイプである必要がある
+	variant, err := detectRangeVariant(yourHand, opponentRange)
+	if err != nil {
+		return nil, EquityResult{}, err
+	}
+
 	// 結果を格納するマップ
 	equities := make(map[string]float64)
+	handResults := newIndexedResults(len(opponentRange))
 	var mu sync.Mutex // 結果マップへのアクセスを保護するためのMutex
 	var wg sync.WaitGroup
 
@@ -70,42 +137,67 @@ func CalculateHandVsRangeEquityParallel(yourHand []poker.Card, opponentHands [][
 	log.Printf("Using %d CPUs for parallel execution in CalculateHandVsRangeEquityParallel", numCPU)
 	semaphore := make(chan struct{}, numCPU) // 同時実行数をCPUコア数に制限
 
-	// 各Opponentハンドに対してequity計算を並列で実行
-	for _, opponentHand := range opponentHands {
-		// カード重複チェック
-		if HasCardDuplicates(yourHand, opponentHand, board) {
-			continue
+	// カード重複チェック（デッドカードを含む）・頻度0のハンドは除外
+	var targets []int
+	for i, opponentHand := range opponentRange {
+		if opponentHand.Weight > 0 && !HasCardDuplicates(yourHand, opponentHand.Cards, board, deadCards) {
+			targets = append(targets, i)
 		}
+	}
+	tracker := newProgressTracker(progress, len(targets))
 
-		wg.Add(1)               // WaitGroupのカウンタをインクリメント
-		semaphore <- struct{}{} // セマフォを取得（空きができるまでブロック）
+	// スートを入れ替えただけの相手ハンドはエクイティが同じため、同値類ごとに1回だけ計算する
+	groups := NewSuitIsomorphism(yourHand, board, deadCards...).groupIsomorphicHands(opponentRange, targets)
+	if len(groups) < len(targets) {
+		log.Printf("Suit isomorphism: %d opponent hands reduced to %d unique matchups", len(targets), len(groups))
+	}
+	if len(deadCards) == 0 {
+		prefetchRangeEquities(variant, yourHand, groupRepresentatives(opponentRange, groups), board)
+	}
+
+	// 各同値類の代表ハンドに対してequity計算を並列で実行
+	for _, group := range groups {
+		// セマフォを取得（空きができるまでブロック、中断された場合は以降のハンドを計算しない）
+		if !acquireSlot(ctx, semaphore) {
+			break
+		}
+		wg.Add(1) // WaitGroupのカウンタをインクリメント
 
 		// goroutineでequity計算を実行
-		go func(currentOpponentHand []poker.Card) {
+		go func(handIndices []int) {
 			defer wg.Done()                // ゴルーチン完了時にカウンタをデクリメント
 			defer func() { <-semaphore }() // セマフォを解放
 
-			// ハンド文字列の生成
-			villainHandStr := ""
-			for _, card := range currentOpponentHand {
-				villainHandStr += card.String()
-			}
-
-			// equity計算
-			equity, _ := CalculateHandVsHandEquity(yourHand, currentOpponentHand, board)
-			if equity != -1 {
+			// equity計算（代表ハンドのみ）
+			result, err := CalculateHandVsHandEquityWithDeadCards(yourHand, opponentRange[handIndices[0]].Cards, board, deadCards)
+			if err == nil {
+				// 同値類の全ハンドに結果を展開する
 				mu.Lock() // Mutexをロックしてequitiesマップを保護
-				equities[villainHandStr] = equity
+				for _, handIdx := range handIndices {
+					equities[GenerateBoardString(opponentRange[handIdx].Cards)] = result.Equity
+				}
 				mu.Unlock() // Mutexをアンロック
+				for _, handIdx := range handIndices {
+					handResults.set(handIdx, result, opponentRange[handIdx].Weight)
+				}
 			}
-		}(opponentHand)
+			tracker.advance(len(handIndices))
+		}(group)
 	}
 
 	wg.Wait() // すべてのゴルーチンが完了するのを待つ
 
 	if len(equities) == 0 {
-		return nil, fmt.Errorf("no valid equity calculations")
+		if err := ctx.Err(); err != nil {
+			return nil, EquityResult{}, err
+		}
+		return nil, EquityResult{}, fmt.Errorf("no valid equity calculations")
 	}
 
-	return equities, nil
+	// ゴルーチンの完了順序に関係なく入力順に集計する
+	result := CombineEquityResults(handResults.collect())
+	if err := ctx.Err(); err != nil {
+		return equities, result.asPartial(), err
+	}
+	return equities, result, nil
 }
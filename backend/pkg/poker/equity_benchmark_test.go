@@ -38,7 +38,7 @@ func TestEquityCalculationPerformance(t *testing.T) {
 
 	t.Run("Exhaustive", func(t *testing.T) {
 		start := time.Now()
-		result, err := CalculateHandVsRangeEquityParallel(yourHand, opponentHands, board)
+		result, _, err := CalculateHandVsRangeEquityParallel(yourHand, opponentHands, board)
 		duration := time.Since(start)
 		
 		if err != nil {
@@ -59,7 +59,7 @@ func TestEquityCalculationPerformance(t *testing.T) {
 
 	t.Run("MonteCarlo", func(t *testing.T) {
 		start := time.Now()
-		result, err := CalculateHandVsRangeEquityMonteCarloParallel(yourHand, opponentHands, board, "NORMAL")
+		result, _, err := CalculateHandVsRangeEquityMonteCarloParallel(yourHand, opponentHands, board, "NORMAL", nil)
 		duration := time.Since(start)
 		
 		if err != nil {
@@ -77,4 +77,67 @@ func TestEquityCalculationPerformance(t *testing.T) {
 		avgEquity := totalEquity / float64(len(result))
 		t.Logf("Average equity: %.2f%%", avgEquity)
 	})
-}
\ No newline at end of file
+}
+
+// benchmarkHands はベンチマーク用のハンドとボード（フロップ）です
+var benchmarkHands = map[Variant][2][]poker.Card{
+	VariantHoldem: {cards("As", "Ad"), cards("Kh", "Qh")},
+	VariantPLO4:   {cards("As", "Ad", "Kh", "Kd"), cards("Qs", "Qd", "Jh", "Jd")},
+	VariantPLO5:   {cards("As", "Ad", "Kh", "Kd", "Qc"), cards("Qs", "Qd", "Jh", "Jd", "Tc")},
+}
+
+var benchmarkBoard = cards("2c", "7s", "Js")
+
+// BenchmarkCalculateHandVsHandEquity はフロップからの全数計算の速度とメモリ割り当てを計測します
+func BenchmarkCalculateHandVsHandEquity(b *testing.B) {
+	for _, variant := range []Variant{VariantHoldem, VariantPLO4, VariantPLO5} {
+		hands := benchmarkHands[variant]
+		b.Run(variant.String(), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				if _, err := CalculateHandVsHandEquity(hands[0], hands[1], benchmarkBoard); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+// BenchmarkRunoutEvaluation はランアウトごとにJudgeで評価し直す方法と、ShowdownEvaluatorの差分評価を比較します
+func BenchmarkRunoutEvaluation(b *testing.B) {
+	for _, variant := range []Variant{VariantPLO4, VariantPLO5} {
+		hands := benchmarkHands[variant]
+		remainingDeck := RemainingDeck(hands[0], hands[1], benchmarkBoard)
+
+		b.Run(variant.String()+"/Judge", func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				var outcomes outcomeCounter
+				forEachRunout(benchmarkBoard, remainingDeck, func(finalBoard []poker.Card) {
+					outcomes.add(variant.Judge(hands[0], hands[1], finalBoard))
+				})
+			}
+		})
+
+		b.Run(variant.String()+"/ShowdownEvaluator", func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				var outcomes outcomeCounter
+				evaluator := NewShowdownEvaluator(variant, hands[0], hands[1])
+				evaluator.SetBoard(benchmarkBoard)
+				evaluator.EnumerateRunouts(remainingDeck, outcomes.add)
+			}
+		})
+	}
+}
+
+// BenchmarkCalculateHandVsHandEquityMonteCarlo はモンテカルロの1万イテレーションを計測します
+func BenchmarkCalculateHandVsHandEquityMonteCarlo(b *testing.B) {
+	hands := benchmarkHands[VariantPLO4]
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := CalculateHandVsHandEquityMonteCarlo(hands[0], hands[1], benchmarkBoard, 10000, NewRand(int64(i))); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
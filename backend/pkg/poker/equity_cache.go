@@ -0,0 +1,281 @@
+package poker
+
+import (
+	"fmt"
+	"log"
+	"sync"
+	"sync/atomic"
+
+	"github.com/chehsunliu/poker"
+)
+
+// EquityCacheStore は全数計算したハンド同士の結果を永続化するストアです
+// 実装はpkg/dbのPostgresEquityCacheStoreなどで、複数のゴルーチンから呼び出されることがあります
+type EquityCacheStore interface {
+	// LoadEquities はキーに対応する保存済みの結果を返します（見つからないキーは結果に含めません）
+	LoadEquities(keys []string) (map[string]EquityResult, error)
+	// SaveEquities は結果を保存します（既に保存されているキーは無視して構いません）
+	SaveEquities(results map[string]EquityResult) error
+}
+
+// EquityCacheKey はゲームタイプと正準化した(ヒーロー, 相手, ボード)からキャッシュのキーを生成します
+// スートを入れ替えただけの組み合わせは同じキーになります（例: "4card_plo:JsQhKhAd:2h5s9d:7d8d9cTc"）
+// キーの先頭はVariant.GameType()の値です
+func EquityCacheKey(variant Variant, yourHand []poker.Card, opponentHand []poker.Card, board []poker.Card) string {
+	hero, opponent, canonicalBoard, _ := CanonicalizeMatchup(yourHand, opponentHand, board)
+	return fmt.Sprintf("%s:%s:%s:%s", variant.GameType(), GenerateBoardString(hero), GenerateBoardString(canonicalBoard), GenerateBoardString(opponent))
+}
+
+// equityCacheFlushSize はストアに未保存の結果をバックグラウンドでまとめて保存する件数です
+const equityCacheFlushSize = 10000
+
+// EquityCacheStats はエクイティキャッシュの統計情報です
+type EquityCacheStats struct {
+	Hits    int64 // キャッシュから結果を返した回数
+	Misses  int64 // キャッシュに結果がなく計算した回数
+	Loaded  int64 // ストアから読み込んだ結果の数
+	Saved   int64 // ストアに保存した結果の数
+	Entries int   // メモリ上のエントリ数
+}
+
+// HitRate はキャッシュのヒット率（%）を返します
+func (s EquityCacheStats) HitRate() float64 {
+	total := s.Hits + s.Misses
+	if total == 0 {
+		return 0
+	}
+	return float64(s.Hits) / float64(total) * 100
+}
+
+// EquityCache は正準化した組み合わせをキーに、全数計算したハンド同士の結果をキャッシュします
+// メモリ上のキャッシュ（最大maxEntries件、古いものから削除）と任意の永続ストアの2層で構成され、
+// 複数のゴルーチンから同時に使用できます
+// ストアへの保存はバックグラウンドの1つのゴルーチンとFlushで行い、失敗した場合はそれ以降ストアを使用しません
+type EquityCache struct {
+	mu         sync.Mutex
+	entries    map[string]EquityResult
+	order      []string                // 追加順のキー（上限を超えた場合に古いものから削除する）
+	pending    map[string]EquityResult // ストアに未保存の結果
+	maxEntries int
+	store      EquityCacheStore
+	storeErr   error      // ストアの読み込み・保存に失敗した場合のエラー（設定後はストアを使用しない）
+	saving     bool       // ストアへの保存が実行中か（保存は1つずつ行う）
+	saveDone   *sync.Cond // 保存の完了を通知する（c.muを使用）
+
+	hits   atomic.Int64
+	misses atomic.Int64
+	loaded atomic.Int64
+	saved  atomic.Int64
+}
+
+// NewEquityCache はメモリ上の上限がmaxEntries件のキャッシュを作成します
+// maxEntriesが0以下の場合は上限なし、storeがnilの場合はメモリ上のみのキャッシュになります
+func NewEquityCache(maxEntries int, store EquityCacheStore) *EquityCache {
+	c := &EquityCache{
+		entries:    make(map[string]EquityResult),
+		pending:    make(map[string]EquityResult),
+		maxEntries: maxEntries,
+		store:      store,
+	}
+	c.saveDone = sync.NewCond(&c.mu)
+	return c
+}
+
+// Get はキャッシュされた結果を返します
+func (c *EquityCache) Get(key string) (EquityResult, bool) {
+	c.mu.Lock()
+	result, ok := c.entries[key]
+	c.mu.Unlock()
+
+	if ok {
+		c.hits.Add(1)
+	} else {
+		c.misses.Add(1)
+	}
+	return result, ok
+}
+
+// Put は全数計算の結果をキャッシュします（サンプリングや中断された結果は保存しません）
+// ストアに未保存の結果がequityCacheFlushSize件に達した場合はバックグラウンドで保存し、呼び出し元は待ちません
+func (c *EquityCache) Put(key string, result EquityResult) {
+	if !result.Exhaustive || result.Partial {
+		return
+	}
+
+	c.mu.Lock()
+	c.add(key, result)
+	var toSave map[string]EquityResult
+	if c.store != nil && c.storeErr == nil {
+		c.pending[key] = result
+		// 保存中の場合は完了後のPutまたはFlushで保存する
+		if len(c.pending) >= equityCacheFlushSize && !c.saving {
+			toSave = c.pending
+			c.pending = make(map[string]EquityResult)
+			c.saving = true
+		}
+	}
+	c.mu.Unlock()
+
+	if toSave != nil {
+		go c.saveInBackground(toSave)
+	}
+}
+
+// saveInBackground はPutから起動され、結果をストアに保存します
+func (c *EquityCache) saveInBackground(results map[string]EquityResult) {
+	if err := c.save(results); err != nil {
+		log.Printf("Warning: %v", err)
+	}
+}
+
+// add はメモリ上のキャッシュに結果を追加し、上限を超えた場合は古いエントリを削除します（c.muを保持して呼び出す）
+func (c *EquityCache) add(key string, result EquityResult) {
+	if _, exists := c.entries[key]; !exists {
+		c.order = append(c.order, key)
+	}
+	c.entries[key] = result
+
+	for c.maxEntries > 0 && len(c.entries) > c.maxEntries {
+		oldest := c.order[0]
+		c.order = c.order[1:]
+		delete(c.entries, oldest)
+	}
+}
+
+// Prefetch はメモリ上にないキーの結果をストアからまとめて読み込みます
+// レンジ計算の前に呼び出すことで、ストアへの問い合わせを1回にまとめます
+func (c *EquityCache) Prefetch(keys []string) error {
+	if c.store == nil {
+		return nil
+	}
+
+	c.mu.Lock()
+	if c.storeErr != nil {
+		c.mu.Unlock()
+		return nil
+	}
+	var missing []string
+	for _, key := range keys {
+		if _, ok := c.entries[key]; !ok {
+			missing = append(missing, key)
+		}
+	}
+	c.mu.Unlock()
+	if len(missing) == 0 {
+		return nil
+	}
+
+	results, err := c.store.LoadEquities(missing)
+	if err != nil {
+		c.disableStore(err)
+		return fmt.Errorf("failed to load cached equities (equity cache store disabled): %v", err)
+	}
+
+	c.mu.Lock()
+	for key, result := range results {
+		c.add(key, result)
+	}
+	c.mu.Unlock()
+	c.loaded.Add(int64(len(results)))
+	return nil
+}
+
+// Flush はバックグラウンドの保存の完了を待ち、ストアに未保存の結果を保存します
+// ストアが無効になっている場合は、無効になった原因のエラーを返します
+func (c *EquityCache) Flush() error {
+	if c.store == nil {
+		return nil
+	}
+
+	c.mu.Lock()
+	for c.saving {
+		c.saveDone.Wait()
+	}
+	if c.storeErr != nil {
+		err := c.storeErr
+		c.mu.Unlock()
+		return fmt.Errorf("equity cache store is disabled: %v", err)
+	}
+	toSave := c.pending
+	c.pending = make(map[string]EquityResult)
+	c.saving = true
+	c.mu.Unlock()
+
+	return c.save(toSave)
+}
+
+// save は結果をストアに保存し、完了を通知します（c.savingを設定して呼び出す）
+// 失敗した場合は同じ失敗を繰り返さないようにストアを無効にし、結果はメモリ上のキャッシュにのみ残します
+func (c *EquityCache) save(results map[string]EquityResult) error {
+	defer func() {
+		c.mu.Lock()
+		c.saving = false
+		c.saveDone.Broadcast()
+		c.mu.Unlock()
+	}()
+
+	if len(results) == 0 {
+		return nil
+	}
+	if err := c.store.SaveEquities(results); err != nil {
+		c.disableStore(err)
+		return fmt.Errorf("failed to save cached equities (equity cache store disabled): %v", err)
+	}
+	c.saved.Add(int64(len(results)))
+	return nil
+}
+
+// disableStore はストアを無効にし、未保存の結果を破棄します
+func (c *EquityCache) disableStore(err error) {
+	c.mu.Lock()
+	if c.storeErr == nil {
+		c.storeErr = err
+	}
+	c.pending = make(map[string]EquityResult)
+	c.mu.Unlock()
+}
+
+// Stats はキャッシュの統計情報を返します
+func (c *EquityCache) Stats() EquityCacheStats {
+	c.mu.Lock()
+	entries := len(c.entries)
+	c.mu.Unlock()
+
+	return EquityCacheStats{
+		Hits:    c.hits.Load(),
+		Misses:  c.misses.Load(),
+		Loaded:  c.loaded.Load(),
+		Saved:   c.saved.Load(),
+		Entries: entries,
+	}
+}
+
+// globalEquityCache は全数計算で参照するキャッシュです（nilの場合はキャッシュを使用しません）
+var globalEquityCache atomic.Pointer[EquityCache]
+
+// SetEquityCache は全数計算（CalculateHandVsHandEquityと、それを使うレンジ計算）で使用するキャッシュを設定します
+// nilを渡すとキャッシュを無効にします
+func SetEquityCache(cache *EquityCache) {
+	globalEquityCache.Store(cache)
+}
+
+// CurrentEquityCache は設定されているキャッシュを返します（未設定の場合はnil）
+func CurrentEquityCache() *EquityCache {
+	return globalEquityCache.Load()
+}
+
+// prefetchRangeEquities はヒーローのハンドとレンジの各ハンドの組み合わせのキャッシュをストアから読み込みます
+func prefetchRangeEquities(variant Variant, yourHand []poker.Card, opponentHands [][]poker.Card, board []poker.Card) {
+	cache := CurrentEquityCache()
+	if cache == nil || cache.store == nil {
+		return
+	}
+
+	keys := make([]string, len(opponentHands))
+	for i, opponentHand := range opponentHands {
+		keys[i] = EquityCacheKey(variant, yourHand, opponentHand, board)
+	}
+	if err := cache.Prefetch(keys); err != nil {
+		log.Printf("Warning: %v", err)
+	}
+}
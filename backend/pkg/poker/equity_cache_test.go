@@ -0,0 +1,252 @@
+package poker
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/chehsunliu/poker"
+)
+
+// memoryEquityStore はテスト用のEquityCacheStoreです
+type memoryEquityStore struct {
+	mu      sync.Mutex
+	results map[string]EquityResult
+	loads   int
+	keys    int // LoadEquitiesで問い合わせたキーの数
+	saves   int
+	saveErr error // 設定した場合はSaveEquitiesがこのエラーを返す
+}
+
+func newMemoryEquityStore() *memoryEquityStore {
+	return &memoryEquityStore{results: make(map[string]EquityResult)}
+}
+
+func (s *memoryEquityStore) LoadEquities(keys []string) (map[string]EquityResult, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.loads++
+	s.keys += len(keys)
+	found := make(map[string]EquityResult)
+	for _, key := range keys {
+		if result, ok := s.results[key]; ok {
+			found[key] = result
+		}
+	}
+	return found, nil
+}
+
+func (s *memoryEquityStore) SaveEquities(results map[string]EquityResult) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.saves++
+	if s.saveErr != nil {
+		return s.saveErr
+	}
+	for key, result := range results {
+		s.results[key] = result
+	}
+	return nil
+}
+
+func TestEquityCache(t *testing.T) {
+	exhaustive := NewExhaustiveEquityResult(600, 100, 300)
+
+	// テストケース1: 全数計算の結果のみ保存され、ヒット・ミスが数えられる
+	t.Run("Get and put", func(t *testing.T) {
+		cache := NewEquityCache(0, nil)
+		if _, ok := cache.Get("a"); ok {
+			t.Errorf("Expected a miss on an empty cache")
+		}
+		cache.Put("a", exhaustive)
+		cache.Put("b", EquityResult{Equity: 50, Samples: 100})
+		cache.Put("c", exhaustive.asPartial())
+
+		if result, ok := cache.Get("a"); !ok || result != exhaustive {
+			t.Errorf("Expected cached result %+v, got %+v (found=%v)", exhaustive, result, ok)
+		}
+		if _, ok := cache.Get("b"); ok {
+			t.Errorf("Expected sampled results not to be cached")
+		}
+		if _, ok := cache.Get("c"); ok {
+			t.Errorf("Expected partial results not to be cached")
+		}
+
+		stats := cache.Stats()
+		if stats.Hits != 1 || stats.Misses != 3 || stats.Entries != 1 {
+			t.Errorf("Expected 1 hit, 3 misses and 1 entry, got %+v", stats)
+		}
+		if stats.HitRate() != 25 {
+			t.Errorf("Expected hit rate 25%%, got %.1f%%", stats.HitRate())
+		}
+	})
+
+	// テストケース2: 上限を超えると古いエントリから削除される
+	t.Run("Size limit", func(t *testing.T) {
+		cache := NewEquityCache(2, nil)
+		cache.Put("a", exhaustive)
+		cache.Put("b", exhaustive)
+		cache.Put("c", exhaustive)
+
+		if _, ok := cache.Get("a"); ok {
+			t.Errorf("Expected the oldest entry to be evicted")
+		}
+		if _, ok := cache.Get("c"); !ok {
+			t.Errorf("Expected the newest entry to be kept")
+		}
+		if entries := cache.Stats().Entries; entries != 2 {
+			t.Errorf("Expected 2 entries, got %d", entries)
+		}
+	})
+
+	// テストケース3: 保存した結果を別のキャッシュ（次回の実行）で読み込める
+	t.Run("Store round trip", func(t *testing.T) {
+		store := newMemoryEquityStore()
+		first := NewEquityCache(10, store)
+		first.Put("a", exhaustive)
+		if err := first.Flush(); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if saved := first.Stats().Saved; saved != 1 {
+			t.Errorf("Expected 1 saved entry, got %d", saved)
+		}
+
+		second := NewEquityCache(10, store)
+		if err := second.Prefetch([]string{"a", "missing"}); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if result, ok := second.Get("a"); !ok || result != exhaustive {
+			t.Errorf("Expected loaded result %+v, got %+v (found=%v)", exhaustive, result, ok)
+		}
+		if loaded := second.Stats().Loaded; loaded != 1 {
+			t.Errorf("Expected 1 loaded entry, got %d", loaded)
+		}
+
+		// メモリ上にあるキーはストアに問い合わせない
+		loads := store.loads
+		second.Prefetch([]string{"a"})
+		if store.loads != loads {
+			t.Errorf("Expected no store query for cached keys")
+		}
+	})
+
+	// テストケース4: 複数のゴルーチンから同時に使用できる
+	t.Run("Concurrent use", func(t *testing.T) {
+		cache := NewEquityCache(100, newMemoryEquityStore())
+		var wg sync.WaitGroup
+		for g := 0; g < 8; g++ {
+			wg.Add(1)
+			go func(g int) {
+				defer wg.Done()
+				for i := 0; i < 200; i++ {
+					key := fmt.Sprintf("%d", (g*200+i)%150)
+					if _, ok := cache.Get(key); !ok {
+						cache.Put(key, exhaustive)
+					}
+				}
+			}(g)
+		}
+		wg.Wait()
+
+		stats := cache.Stats()
+		if stats.Hits+stats.Misses != 8*200 {
+			t.Errorf("Expected %d lookups, got %d", 8*200, stats.Hits+stats.Misses)
+		}
+		if stats.Entries > 100 {
+			t.Errorf("Expected at most 100 entries, got %d", stats.Entries)
+		}
+	})
+
+	// テストケース5: 未保存の結果が一定数に達するとバックグラウンドで保存され、Flushはその完了を待つ
+	t.Run("Background save", func(t *testing.T) {
+		store := newMemoryEquityStore()
+		cache := NewEquityCache(0, store)
+		for i := 0; i < equityCacheFlushSize+1; i++ {
+			cache.Put(fmt.Sprintf("%d", i), exhaustive)
+		}
+		if err := cache.Flush(); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		if saved := cache.Stats().Saved; saved != equityCacheFlushSize+1 {
+			t.Errorf("Expected %d saved entries, got %d", equityCacheFlushSize+1, saved)
+		}
+		if len(store.results) != equityCacheFlushSize+1 || store.saves != 2 {
+			t.Errorf("Expected %d results in 2 saves, got %d in %d", equityCacheFlushSize+1, len(store.results), store.saves)
+		}
+	})
+
+	// テストケース6: 保存に失敗した場合はストアを無効にし、以降は保存を再試行しない
+	t.Run("Save failure disables the store", func(t *testing.T) {
+		store := newMemoryEquityStore()
+		store.saveErr = errors.New("connection refused")
+		cache := NewEquityCache(0, store)
+		cache.Put("a", exhaustive)
+		if err := cache.Flush(); err == nil {
+			t.Fatalf("Expected an error when the store fails")
+		}
+
+		for i := 0; i < equityCacheFlushSize; i++ {
+			cache.Put(fmt.Sprintf("%d", i), exhaustive)
+		}
+		if err := cache.Flush(); err == nil {
+			t.Errorf("Expected an error for a disabled store")
+		}
+		if store.saves != 1 {
+			t.Errorf("Expected no retries after the failure, got %d saves", store.saves)
+		}
+		if err := cache.Prefetch([]string{"missing"}); err != nil || store.loads != 0 {
+			t.Errorf("Expected no store query after the failure, got %d loads (err=%v)", store.loads, err)
+		}
+
+		// メモリ上のキャッシュは引き続き使用できる
+		if _, ok := cache.Get("a"); !ok {
+			t.Errorf("Expected the result to be kept in memory")
+		}
+	})
+
+	// テストケース7: レンジ同士の計算は有効な同値類の代表ハンドのみをストアに問い合わせる
+	t.Run("Range vs range prefetches representatives", func(t *testing.T) {
+		store := newMemoryEquityStore()
+		SetEquityCache(NewEquityCache(0, store))
+		defer SetEquityCache(nil)
+
+		heroRange := NewUniformRange([][]poker.Card{cards("Ac", "Kc")})
+		villainRange := NewUniformRange([][]poker.Card{
+			cards("Qh", "Qd"), cards("Qh", "Qs"), cards("Qd", "Qs"), // スートを入れ替えただけの組み合わせ
+			cards("Jh", "Jd"),
+			cards("Kc", "Qh"), // ヒーローのハンドと重複するため除外
+		})
+		if _, err := CalculateRangeVsRangeEquity(heroRange, villainRange, cards("2c", "7c", "Tc")); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if store.keys != 2 {
+			t.Errorf("Expected 2 prefetched keys, got %d", store.keys)
+		}
+	})
+
+	// テストケース8: 全数計算はスートを入れ替えただけの組み合わせにキャッシュを再利用する
+	t.Run("Hand vs hand equity uses the cache", func(t *testing.T) {
+		cache := NewEquityCache(0, nil)
+		SetEquityCache(cache)
+		defer SetEquityCache(nil)
+
+		first, err := CalculateHandVsHandEquity(cards("As", "Ks"), cards("Qh", "Jh"), cards("2s", "7h", "Td"))
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		// s->h, h->c, d->s
+		second, err := CalculateHandVsHandEquity(cards("Ah", "Kh"), cards("Qc", "Jc"), cards("2h", "7c", "Ts"))
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		if first != second {
+			t.Errorf("Expected identical results, got %+v and %+v", first, second)
+		}
+		if stats := cache.Stats(); stats.Hits != 1 || stats.Misses != 1 {
+			t.Errorf("Expected 1 hit and 1 miss, got %+v", stats)
+		}
+	})
+}
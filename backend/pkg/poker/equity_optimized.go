@@ -1,13 +1,12 @@
 package poker
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"math"
 	"math/rand"
 	"runtime"
-	"sort"
-	"strings"
 	"sync"
 	"time"
 
@@ -29,182 +28,183 @@ type EquityCalculationConfig struct {
 	ConvergenceCheck int
 }
 
-// HandRankCache はハンドランクのキャッシュを管理します
-type HandRankCache struct {
-	cache sync.Map
-	hits  int64
-	total int64
+// CalculateHandVsHandEquityMonteCarlo はモンテカルロシミュレーションでequityを計算します
+// ボードは0枚（プリフロップ）、3枚（フロップ）、4枚（ターン）、5枚（リバー）に対応します
+// 結果には勝敗数と、サンプリングによる標準誤差・95%信頼区間が含まれます
+// rngに同じシードの乱数生成器を渡すと同じ結果を返します（nilの場合は現在時刻をシードとします）
+func CalculateHandVsHandEquityMonteCarlo(yourHand []poker.Card, opponentHand []poker.Card, board []poker.Card, iterations int, rng *rand.Rand) (EquityResult, error) {
+	return CalculateHandVsHandEquityMonteCarloWithDeadCards(yourHand, opponentHand, board, nil, iterations, rng)
 }
 
-var globalHandRankCache = &HandRankCache{}
-
-// GetHandRank はキャッシュからハンドランクを取得します
-func (hrc *HandRankCache) GetHandRank(hand []poker.Card, board []poker.Card) (int, bool) {
-	key := generateHandKey(hand, board)
-	hrc.total++
-	if rank, ok := hrc.cache.Load(key); ok {
-		hrc.hits++
-		return rank.(int), true
+// CalculateHandVsHandEquityMonteCarloWithDeadCards はデッドカードを指定できるCalculateHandVsHandEquityMonteCarloです
+// デッドカードはデッキから取り除かれ、ランアウトには配られません
+func CalculateHandVsHandEquityMonteCarloWithDeadCards(yourHand []poker.Card, opponentHand []poker.Card, board []poker.Card, deadCards []poker.Card, iterations int, rng *rand.Rand) (EquityResult, error) {
+	if HasCardDuplicates(yourHand, opponentHand, board, deadCards) {
+		return EquityResult{}, fmt.Errorf("duplicate cards detected")
 	}
-	return 0, false
-}
-
-// SetHandRank はハンドランクをキャッシュに保存します
-func (hrc *HandRankCache) SetHandRank(hand []poker.Card, board []poker.Card, rank int) {
-	key := generateHandKey(hand, board)
-	hrc.cache.Store(key, rank)
-}
-
-// GetCacheStats はキャッシュの統計情報を返します
-func (hrc *HandRankCache) GetCacheStats() (int64, int64, float64) {
-	hits := hrc.hits
-	total := hrc.total
-	hitRate := 0.0
-	if total > 0 {
-		hitRate = float64(hits) / float64(total) * 100
+	if err := ValidateBoardSize(board); err != nil {
+		return EquityResult{}, err
 	}
-	return hits, total, hitRate
-}
-
-// generateHandKey はハンドとボードからキーを生成します
-func generateHandKey(hand []poker.Card, board []poker.Card) string {
-	var cards []string
-	for _, card := range append(hand, board...) {
-		cards = append(cards, card.String())
+	if iterations <= 0 {
+		return EquityResult{}, fmt.Errorf("iterations must be positive, got %d", iterations)
 	}
-	sort.Strings(cards) // 順序を正規化
-	return strings.Join(cards, "")
-}
-
-// CalculateHandVsHandEquityMonteCarlo はモンテカルロシミュレーションでequityを計算します
-func CalculateHandVsHandEquityMonteCarlo(yourHand []poker.Card, opponentHand []poker.Card, board []poker.Card, iterations int) (float64, error) {
-	if HasCardDuplicates(yourHand, opponentHand, board) {
-		return -1, fmt.Errorf("duplicate cards detected")
+	variant, err := DetectVariant(yourHand, opponentHand)
+	if err != nil {
+		return EquityResult{}, err
 	}
 
-	// 使用済みカードを除外
-	usedCards := make(map[string]bool)
-	for _, card := range append(append(yourHand, opponentHand...), board...) {
-		usedCards[card.String()] = true
-	}
+	// 使用済みカードとデッドカードを除いた残りカードのデッキを作成
+	remainingDeck := RemainingDeck(yourHand, opponentHand, board, deadCards)
 
-	// 残りカードのデッキを作成
-	deck := poker.NewDeck()
-	fullDeck := deck.Draw(52)
-	var remainingDeck []poker.Card
-	for _, card := range fullDeck {
-		if !usedCards[card.String()] {
-			remainingDeck = append(remainingDeck, card)
-		}
+	if len(remainingDeck) < CardsToComplete(board) {
+		return EquityResult{}, fmt.Errorf("insufficient remaining cards")
 	}
 
-	if len(remainingDeck) < 2 {
-		return -1, fmt.Errorf("insufficient remaining cards")
-	}
+	var outcomes outcomeCounter
 
-	wins := 0.0
-	ties := 0.0
+	// 乱数生成器を初期化（nilの場合は現在時刻をシードとする）
+	rng = randOrDefault(rng)
 
-	// 乱数生成器を初期化
-	rng := rand.New(rand.NewSource(time.Now().UnixNano()))
+	// 完成ボード用のバッファ（イテレーションごとに再利用）
+	finalBoard := make([]poker.Card, len(board)+CardsToComplete(board))
+	copy(finalBoard, board)
+
+	// 既知のボードは1回だけ評価し、イテレーションごとに配ったカードの分だけ評価する
+	evaluator := NewShowdownEvaluator(variant, yourHand, opponentHand)
+	evaluator.SetBoard(board)
 
 	// モンテカルロシミュレーション
 	for i := 0; i < iterations; i++ {
-		// ランダムに2枚選択（Fisher-Yatesシャッフルの簡略版）
-		idx1 := rng.Intn(len(remainingDeck))
-		idx2 := rng.Intn(len(remainingDeck) - 1)
-		if idx2 >= idx1 {
-			idx2++
-		}
+		// 不足しているボードカードをランダムに配る
+		dealRandomRunout(rng, len(board), remainingDeck, finalBoard)
+		outcomes.add(evaluator.JudgeRunout(finalBoard[len(board):]))
+	}
 
-		finalBoard := append(board, remainingDeck[idx1], remainingDeck[idx2])
+	return outcomes.result(false), nil
+}
 
-		winner := JudgeWinner(yourHand, opponentHand, finalBoard)
-		switch winner {
-		case "yourHand":
-			wins++
-		case "tie":
-			ties += 0.5
-		}
+// dealRandomRunout はremainingDeckの部分Fisher-Yatesシャッフルで
+// finalBoard[boardSize:]に重複のないランダムなカードを配ります
+func dealRandomRunout(rng *rand.Rand, boardSize int, remainingDeck []poker.Card, finalBoard []poker.Card) {
+	for pos, i := boardSize, 0; pos < len(finalBoard); pos, i = pos+1, i+1 {
+		j := i + rng.Intn(len(remainingDeck)-i)
+		remainingDeck[i], remainingDeck[j] = remainingDeck[j], remainingDeck[i]
+		finalBoard[pos] = remainingDeck[i]
 	}
-
-	equity := (wins + ties) / float64(iterations) * 100
-	return equity, nil
 }
 
 // CalculateHandVsHandEquityAdaptive は適応的精度制御でequityを計算します
-func CalculateHandVsHandEquityAdaptive(yourHand []poker.Card, opponentHand []poker.Card, board []poker.Card, config EquityCalculationConfig) (float64, int, error) {
-	if HasCardDuplicates(yourHand, opponentHand, board) {
-		return -1, 0, fmt.Errorf("duplicate cards detected")
-	}
+// ボードは0枚（プリフロップ）、3枚（フロップ）、4枚（ターン）、5枚（リバー）に対応します
+// 収束までに使用したイテレーション数は結果のSamplesに格納されます
+// rngはCalculateHandVsHandEquityMonteCarloと同様です（nilの場合は現在時刻をシードとします）
+func CalculateHandVsHandEquityAdaptive(yourHand []poker.Card, opponentHand []poker.Card, board []poker.Card, config EquityCalculationConfig, rng *rand.Rand) (EquityResult, error) {
+	return CalculateHandVsHandEquityAdaptiveWithDeadCards(yourHand, opponentHand, board, nil, config, rng)
+}
 
-	// 使用済みカードを除外
-	usedCards := make(map[string]bool)
-	for _, card := range append(append(yourHand, opponentHand...), board...) {
-		usedCards[card.String()] = true
+// CalculateHandVsHandEquityAdaptiveWithDeadCards はデッドカードを指定できるCalculateHandVsHandEquityAdaptiveです
+func CalculateHandVsHandEquityAdaptiveWithDeadCards(yourHand []poker.Card, opponentHand []poker.Card, board []poker.Card, deadCards []poker.Card, config EquityCalculationConfig, rng *rand.Rand) (EquityResult, error) {
+	if HasCardDuplicates(yourHand, opponentHand, board, deadCards) {
+		return EquityResult{}, fmt.Errorf("duplicate cards detected")
 	}
-
-	// 残りカードのデッキを作成
-	deck := poker.NewDeck()
-	fullDeck := deck.Draw(52)
-	var remainingDeck []poker.Card
-	for _, card := range fullDeck {
-		if !usedCards[card.String()] {
-			remainingDeck = append(remainingDeck, card)
-		}
+	if err := ValidateBoardSize(board); err != nil {
+		return EquityResult{}, err
 	}
+	if config.MaxIterations <= 0 {
+		return EquityResult{}, fmt.Errorf("max iterations must be positive, got %d", config.MaxIterations)
+	}
+	variant, err := DetectVariant(yourHand, opponentHand)
+	if err != nil {
+		return EquityResult{}, err
+	}
+
+	// 使用済みカードとデッドカードを除いた残りカードのデッキを作成
+	remainingDeck := RemainingDeck(yourHand, opponentHand, board, deadCards)
 
-	if len(remainingDeck) < 2 {
-		return -1, 0, fmt.Errorf("insufficient remaining cards")
+	if len(remainingDeck) < CardsToComplete(board) {
+		return EquityResult{}, fmt.Errorf("insufficient remaining cards")
 	}
 
-	wins := 0.0
-	total := 0.0
+	var outcomes outcomeCounter
 	var recentResults []float64
 
-	// 乱数生成器を初期化
-	rng := rand.New(rand.NewSource(time.Now().UnixNano()))
+	// 乱数生成器を初期化（nilの場合は現在時刻をシードとする）
+	rng = randOrDefault(rng)
 
-	for i := 0; i < config.MaxIterations; i++ {
-		// ランダムに2枚選択
-		idx1 := rng.Intn(len(remainingDeck))
-		idx2 := rng.Intn(len(remainingDeck) - 1)
-		if idx2 >= idx1 {
-			idx2++
-		}
+	// 完成ボード用のバッファ（イテレーションごとに再利用）
+	finalBoard := make([]poker.Card, len(board)+CardsToComplete(board))
+	copy(finalBoard, board)
 
-		finalBoard := append(board, remainingDeck[idx1], remainingDeck[idx2])
+	// 既知のボードは1回だけ評価し、イテレーションごとに配ったカードの分だけ評価する
+	evaluator := NewShowdownEvaluator(variant, yourHand, opponentHand)
+	evaluator.SetBoard(board)
 
-		winner := JudgeWinner(yourHand, opponentHand, finalBoard)
-		switch winner {
-		case "yourHand":
-			wins++
-		case "tie":
-			wins += 0.5
-		}
-		total++
+	for i := 0; i < config.MaxIterations; i++ {
+		// 不足しているボードカードをランダムに配る
+		dealRandomRunout(rng, len(board), remainingDeck, finalBoard)
+		outcomes.add(evaluator.JudgeRunout(finalBoard[len(board):]))
 
 		// 収束チェック
 		if i >= config.MinIterations && i%config.ConvergenceCheck == 0 {
-			currentEquity := (wins / total) * 100
-			recentResults = append(recentResults, currentEquity)
+			recentResults = append(recentResults, outcomes.result(false).Equity)
 
 			if len(recentResults) >= 5 {
 				// 最近の結果の標準偏差を計算
 				if standardDeviation(recentResults) < config.TargetPrecision {
-					return currentEquity, i + 1, nil
+					return outcomes.result(false), nil
 				}
 				recentResults = recentResults[1:] // 古い結果を削除
 			}
 		}
 	}
 
-	return (wins / total) * 100, config.MaxIterations, nil
+	return outcomes.result(false), nil
 }
 
 // CalculateHandVsRangeEquityMonteCarloParallel はモンテカルロシミュレーションで並列equity計算を行います
-func CalculateHandVsRangeEquityMonteCarloParallel(yourHand []poker.Card, opponentHands [][]poker.Card, board []poker.Card, mode string) (map[string]float64, error) {
+// 各ハンドの頻度を1.0としてCalculateHandVsWeightedRangeEquityMonteCarloParallelを呼び出します
+func CalculateHandVsRangeEquityMonteCarloParallel(yourHand []poker.Card, opponentHands [][]poker.Card, board []poker.Card, mode string, rng *rand.Rand) (map[string]float64, EquityResult, error) {
+	return CalculateHandVsWeightedRangeEquityMonteCarloParallel(yourHand, NewUniformRange(opponentHands), board, mode, rng)
+}
+
+// CalculateHandVsRangeEquityMonteCarloParallelWithDeadCards はデッドカードを考慮したCalculateHandVsRangeEquityMonteCarloParallelです
+func CalculateHandVsRangeEquityMonteCarloParallelWithDeadCards(yourHand []poker.Card, opponentHands [][]poker.Card, board []poker.Card, deadCards []poker.Card, mode string, rng *rand.Rand) (map[string]float64, EquityResult, error) {
+	return CalculateHandVsWeightedRangeEquityMonteCarloParallelWithDeadCards(yourHand, NewUniformRange(opponentHands), board, deadCards, mode, rng)
+}
+
+// CalculateHandVsRangeEquityMonteCarloParallelContext は各ハンドの頻度を1.0としたCalculateHandVsWeightedRangeEquityMonteCarloParallelContextです
+func CalculateHandVsRangeEquityMonteCarloParallelContext(ctx context.Context, yourHand []poker.Card, opponentHands [][]poker.Card, board []poker.Card, deadCards []poker.Card, mode string, rng *rand.Rand, progress ProgressFunc) (map[string]float64, EquityResult, error) {
+	return CalculateHandVsWeightedRangeEquityMonteCarloParallelContext(ctx, yourHand, NewUniformRange(opponentHands), board, deadCards, mode, rng, progress)
+}
+
+// CalculateHandVsWeightedRangeEquityMonteCarloParallel は頻度付きレンジに対してモンテカルロシミュレーションで並列equity計算を行います
+// 各ハンドのエクイティと、ハンドごとの推定誤差を頻度で重み付けして合成したレンジ全体の結果を返します
+// 各ハンドのシードはrngから入力順に生成するため、同じシードのrngからは並列実行でも同じ結果になります
+func CalculateHandVsWeightedRangeEquityMonteCarloParallel(yourHand []poker.Card, opponentRange WeightedRange, board []poker.Card, mode string, rng *rand.Rand) (map[string]float64, EquityResult, error) {
+	return CalculateHandVsWeightedRangeEquityMonteCarloParallelContext(context.Background(), yourHand, opponentRange, board, nil, mode, rng, nil)
+}
+
+// CalculateHandVsWeightedRangeEquityMonteCarloParallelWithDeadCards はデッドカードを考慮したCalculateHandVsWeightedRangeEquityMonteCarloParallelです
+// デッドカードの扱いはCalculateHandVsWeightedRangeEquityParallelContextと同じです
+func CalculateHandVsWeightedRangeEquityMonteCarloParallelWithDeadCards(yourHand []poker.Card, opponentRange WeightedRange, board []poker.Card, deadCards []poker.Card, mode string, rng *rand.Rand) (map[string]float64, EquityResult, error) {
+	return CalculateHandVsWeightedRangeEquityMonteCarloParallelContext(context.Background(), yourHand, opponentRange, board, deadCards, mode, rng, nil)
+}
+
+// CalculateHandVsWeightedRangeEquityMonteCarloParallelContext はctxのキャンセル・期限に対応したCalculateHandVsWeightedRangeEquityMonteCarloParallelです
+// デッドカード・頻度0のハンドの扱い、進捗の通知と中断時の結果はCalculateHandVsWeightedRangeEquityParallelContextと同じです
+func CalculateHandVsWeightedRangeEquityMonteCarloParallelContext(ctx context.Context, yourHand []poker.Card, opponentRange WeightedRange, board []poker.Card, deadCards []poker.Card, mode string, rng *rand.Rand, progress ProgressFunc) (map[string]float64, EquityResult, error) {
+	if err := ValidateBoardSize(board); err != nil {
+		return nil, EquityResult{}, err
+	}
+	if HasCardDuplicates(yourHand, board, deadCards) {
+		return nil, EquityResult{}, fmt.Errorf("duplicate cards detected")
+	}
+	if _, err := detectRangeVariant(yourHand, opponentRange); err != nil {
+		return nil, EquityResult{}, err
+	}
+
 	equities := make(map[string]float64)
+	handResults := newIndexedResults(len(opponentRange))
+	seeds := deriveSeeds(randOrDefault(rng), len(opponentRange))
 	var mu sync.Mutex
 	var wg sync.WaitGroup
 
@@ -213,31 +213,38 @@ func CalculateHandVsRangeEquityMonteCarloParallel(yourHand []poker.Card, opponen
 	switch mode {
 	case "FAST":
 		iterations = FAST_ITERATIONS
-		log.Printf("Using fast mode (%d iterations) for %d opponent hands", iterations, len(opponentHands))
+		log.Printf("Using fast mode (%d iterations) for %d opponent hands", iterations, len(opponentRange))
 	case "ACCURATE":
 		iterations = ACCURATE_ITERATIONS
-		log.Printf("Using accurate mode (%d iterations) for %d opponent hands", iterations, len(opponentHands))
+		log.Printf("Using accurate mode (%d iterations) for %d opponent hands", iterations, len(opponentRange))
 	case "NORMAL":
 		fallthrough
 	default:
 		iterations = NORMAL_ITERATIONS
-		log.Printf("Using normal mode (%d iterations) for %d opponent hands", iterations, len(opponentHands))
+		log.Printf("Using normal mode (%d iterations) for %d opponent hands", iterations, len(opponentRange))
 	}
 
 	numCPU := runtime.NumCPU()
 	semaphore := make(chan struct{}, numCPU)
 
+	var targets []int
+	for i, opponentHand := range opponentRange {
+		if opponentHand.Weight > 0 && !HasCardDuplicates(yourHand, opponentHand.Cards, board, deadCards) {
+			targets = append(targets, i)
+		}
+	}
+	tracker := newProgressTracker(progress, len(targets))
+
 	startTime := time.Now()
 
-	for _, opponentHand := range opponentHands {
-		if HasCardDuplicates(yourHand, opponentHand, board) {
-			continue
+	for _, i := range targets {
+		opponentHand := opponentRange[i]
+		if !acquireSlot(ctx, semaphore) {
+			break
 		}
-
 		wg.Add(1)
-		semaphore <- struct{}{}
 
-		go func(currentOpponentHand []poker.Card) {
+		go func(handIdx int, currentOpponentHand []poker.Card, weight float64) {
 			defer wg.Done()
 			defer func() { <-semaphore }()
 
@@ -246,27 +253,35 @@ func CalculateHandVsRangeEquityMonteCarloParallel(yourHand []poker.Card, opponen
 				villainHandStr += card.String()
 			}
 
-			equity, err := CalculateHandVsHandEquityMonteCarlo(yourHand, currentOpponentHand, board, iterations)
-			if err == nil && equity != -1 {
+			handRng := rand.New(rand.NewSource(seeds[handIdx]))
+			result, err := CalculateHandVsHandEquityMonteCarloWithDeadCards(yourHand, currentOpponentHand, board, deadCards, iterations, handRng)
+			if err == nil {
 				mu.Lock()
-				equities[villainHandStr] = equity
+				equities[villainHandStr] = result.Equity
 				mu.Unlock()
+				handResults.set(handIdx, result, weight)
 			}
-		}(opponentHand)
+			tracker.advance(1)
+		}(i, opponentHand.Cards, opponentHand.Weight)
 	}
 
 	wg.Wait()
 
 	duration := time.Since(startTime)
-	hits, total, hitRate := globalHandRankCache.GetCacheStats()
-	log.Printf("Monte Carlo calculation completed in %v. Cache stats: %d/%d hits (%.1f%%)",
-		duration, hits, total, hitRate)
+	log.Printf("Monte Carlo calculation completed in %v", duration)
 
 	if len(equities) == 0 {
-		return nil, fmt.Errorf("no valid equity calculations")
+		if err := ctx.Err(); err != nil {
+			return nil, EquityResult{}, err
+		}
+		return nil, EquityResult{}, fmt.Errorf("no valid equity calculations")
 	}
 
-	return equities, nil
+	result := CombineEquityResults(handResults.collect())
+	if err := ctx.Err(); err != nil {
+		return equities, result.asPartial(), err
+	}
+	return equities, result, nil
 }
 
 // standardDeviation は標準偏差を計算します
@@ -301,8 +316,3 @@ func GetDefaultAdaptiveConfig() EquityCalculationConfig {
 		ConvergenceCheck: 200, // 200イテレーションごとに収束チェック
 	}
 }
-
-// ClearHandRankCache はハンドランクキャッシュをクリアします
-func ClearHandRankCache() {
-	globalHandRankCache = &HandRankCache{}
-}
@@ -0,0 +1,116 @@
+package poker
+
+import "math"
+
+// ConfidenceZ95 は95%信頼区間のZ値です
+const ConfidenceZ95 = 1.96
+
+// EquityResult はエクイティ計算の結果と推定精度を表します
+type EquityResult struct {
+	Equity     float64 `json:"equity"`     // エクイティ（%、引き分けは0.5勝として計算）
+	Wins       int     `json:"wins"`       // 勝ちのランアウト数
+	Ties       int     `json:"ties"`       // 引き分けのランアウト数
+	Losses     int     `json:"losses"`     // 負けのランアウト数
+	StdError   float64 `json:"std_error"`  // エクイティの標準誤差（%）。全数計算の場合は0
+	CILow      float64 `json:"ci_low"`     // 95%信頼区間の下限（%）
+	CIHigh     float64 `json:"ci_high"`    // 95%信頼区間の上限（%）
+	Samples    int     `json:"samples"`    // 推定に使用したサンプル数（ハンド同士ではランアウト数、レンジのサンプリングではハンド数）
+	Exhaustive bool    `json:"exhaustive"` // サンプリングせずに全数計算した結果か
+	Partial    bool    `json:"partial"`    // キャンセル・期限切れにより一部のハンドのみで計算した結果か
+}
+
+// Margin は95%信頼区間の半幅（"±x%"のx）を返します
+func (r EquityResult) Margin() float64 {
+	return ConfidenceZ95 * r.StdError
+}
+
+// withConfidenceInterval は標準誤差から95%信頼区間を設定します（0〜100%に収めます）
+func (r EquityResult) withConfidenceInterval() EquityResult {
+	r.CILow = math.Max(0, r.Equity-r.Margin())
+	r.CIHigh = math.Min(100, r.Equity+r.Margin())
+	return r
+}
+
+// asPartial は計算が途中で中断された結果としてマークします
+// 計算できなかったハンドがあるため、全数計算の結果としては扱いません
+func (r EquityResult) asPartial() EquityResult {
+	r.Partial = true
+	r.Exhaustive = false
+	return r
+}
+
+// NewExhaustiveEquityResult は全数計算の勝敗数から結果を作成します
+// 保存しておいた勝敗数から結果を復元する場合に使用します
+func NewExhaustiveEquityResult(wins int, ties int, losses int) EquityResult {
+	return outcomeCounter{wins: wins, ties: ties, losses: losses}.result(true)
+}
+
+// outcomeCounter はランアウトごとの勝敗を集計します
+type outcomeCounter struct {
+	wins, ties, losses int
+}
+
+// add はJudgeの判定結果（"yourHand", "opponentHand", "tie"）を1回分加算します
+func (c *outcomeCounter) add(winner string) {
+	switch winner {
+	case "yourHand":
+		c.wins++
+	case "tie":
+		c.ties++
+	default:
+		c.losses++
+	}
+}
+
+// result は集計した勝敗からエクイティと標準誤差を計算します
+// 全数計算の場合は誤差なし、サンプリングの場合は1回あたりの獲得割合（1, 0.5, 0）の分散から標準誤差を求めます
+func (c outcomeCounter) result(exhaustive bool) EquityResult {
+	total := c.wins + c.ties + c.losses
+	result := EquityResult{
+		Wins:       c.wins,
+		Ties:       c.ties,
+		Losses:     c.losses,
+		Samples:    total,
+		Exhaustive: exhaustive,
+	}
+	if total == 0 {
+		return result
+	}
+
+	n := float64(total)
+	mean := (float64(c.wins) + float64(c.ties)*0.5) / n
+	result.Equity = mean * 100
+	if !exhaustive {
+		meanSquare := (float64(c.wins) + float64(c.ties)*0.25) / n
+		variance := math.Max(0, meanSquare-mean*mean)
+		result.StdError = math.Sqrt(variance/n) * 100
+	}
+	return result.withConfidenceInterval()
+}
+
+// CombineEquityResults は相手ハンドごとの結果を頻度で重み付けし、レンジ全体の結果にまとめます
+// 勝敗数とサンプル数は合計し、標準誤差は各結果を独立な推定値として合成します
+func CombineEquityResults(results []EquityResult, weights []float64) EquityResult {
+	combined := EquityResult{Exhaustive: true}
+	totalWeight := 0.0
+	variance := 0.0
+
+	for i, result := range results {
+		weight := weights[i]
+		combined.Equity += result.Equity * weight
+		combined.Wins += result.Wins
+		combined.Ties += result.Ties
+		combined.Losses += result.Losses
+		combined.Samples += result.Samples
+		combined.Exhaustive = combined.Exhaustive && result.Exhaustive
+		variance += math.Pow(result.StdError*weight, 2)
+		totalWeight += weight
+	}
+
+	if totalWeight == 0 {
+		return EquityResult{}
+	}
+	combined.Equity /= totalWeight
+	combined.StdError = math.Sqrt(variance) / totalWeight
+	return combined.withConfidenceInterval()
+}
@@ -0,0 +1,155 @@
+package poker
+
+import (
+	"math"
+	"testing"
+
+	"github.com/chehsunliu/poker"
+)
+
+func TestOutcomeCounterResult(t *testing.T) {
+	// テストケース1: サンプリング結果の標準誤差と95%信頼区間
+	t.Run("Sampled result", func(t *testing.T) {
+		counter := outcomeCounter{wins: 50, losses: 50}
+		result := counter.result(false)
+
+		if result.Equity != 50 || result.Samples != 100 || result.Exhaustive {
+			t.Fatalf("Unexpected result: %+v", result)
+		}
+		// 獲得割合の分散0.25、100サンプル → 標準誤差5%
+		if math.Abs(result.StdError-5) > 1e-9 {
+			t.Errorf("Expected standard error 5%%, got %.4f%%", result.StdError)
+		}
+		if math.Abs(result.CILow-40.2) > 1e-9 || math.Abs(result.CIHigh-59.8) > 1e-9 {
+			t.Errorf("Expected 95%% CI [40.2, 59.8], got [%.4f, %.4f]", result.CILow, result.CIHigh)
+		}
+	})
+
+	// テストケース2: 信頼区間は0〜100%に収まる
+	t.Run("Clamped interval", func(t *testing.T) {
+		result := outcomeCounter{wins: 9, ties: 1}.result(false)
+		if result.CIHigh > 100 || result.CILow < 0 {
+			t.Errorf("Expected interval within [0, 100], got [%.4f, %.4f]", result.CILow, result.CIHigh)
+		}
+	})
+
+	// テストケース3: 全数計算の結果は誤差なし
+	t.Run("Exhaustive result", func(t *testing.T) {
+		result := outcomeCounter{wins: 3, ties: 2, losses: 5}.result(true)
+		if result.Equity != 40 || result.StdError != 0 || result.CILow != 40 || result.CIHigh != 40 {
+			t.Errorf("Unexpected exhaustive result: %+v", result)
+		}
+	})
+}
+
+func TestCalculateHandVsHandEquityResult(t *testing.T) {
+	// AA vs KK、ターンまでKが落ちていない → 残り44枚中2枚でKKが逆転
+	yourHand := cards("As", "Ac")
+	opponentHand := cards("Ks", "Kc")
+	board := cards("2h", "7d", "Th", "3c")
+
+	// テストケース1: 全数計算は勝敗数を返す
+	t.Run("Exhaustive counts", func(t *testing.T) {
+		result, err := CalculateHandVsHandEquity(yourHand, opponentHand, board)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if result.Wins != 42 || result.Ties != 0 || result.Losses != 2 || result.Samples != 44 {
+			t.Errorf("Expected 42/0/2 over 44 runouts, got %d/%d/%d over %d", result.Wins, result.Ties, result.Losses, result.Samples)
+		}
+		if !result.Exhaustive || result.Margin() != 0 {
+			t.Errorf("Expected an exhaustive result without margin, got %+v", result)
+		}
+	})
+
+	// テストケース2: モンテカルロは標準誤差を返し、全数計算の値が誤差の範囲に収まる
+	t.Run("Monte Carlo error", func(t *testing.T) {
+		result, err := CalculateHandVsHandEquityMonteCarlo(yourHand, opponentHand, board, ACCURATE_ITERATIONS, nil)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if result.Exhaustive || result.Samples != ACCURATE_ITERATIONS || result.Wins+result.Ties+result.Losses != ACCURATE_ITERATIONS {
+			t.Fatalf("Unexpected Monte Carlo result: %+v", result)
+		}
+		if result.StdError <= 0 {
+			t.Fatalf("Expected positive standard error, got %.4f", result.StdError)
+		}
+		expected := 42.0 / 44.0 * 100
+		if math.Abs(result.Equity-expected) > 4*result.StdError {
+			t.Errorf("Expected %.2f%% within 4 standard errors of %.2f%% (SE %.2f%%)", expected, result.Equity, result.StdError)
+		}
+	})
+
+	// テストケース3: 適応的計算は使用したイテレーション数を返す
+	t.Run("Adaptive samples", func(t *testing.T) {
+		config := GetDefaultAdaptiveConfig()
+		result, err := CalculateHandVsHandEquityAdaptive(yourHand, opponentHand, board, config, nil)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if result.Samples < config.MinIterations || result.Samples > config.MaxIterations {
+			t.Errorf("Expected samples between %d and %d, got %d", config.MinIterations, config.MaxIterations, result.Samples)
+		}
+	})
+}
+
+func TestCombineEquityResults(t *testing.T) {
+	results := []EquityResult{
+		{Equity: 80, Wins: 8, Losses: 2, Samples: 10, StdError: 2},
+		{Equity: 40, Wins: 4, Losses: 6, Samples: 10, StdError: 4},
+	}
+
+	combined := CombineEquityResults(results, []float64{3, 1})
+
+	// (80*3 + 40*1) / 4 = 70
+	if math.Abs(combined.Equity-70) > 1e-9 {
+		t.Errorf("Expected weighted equity 70%%, got %.4f%%", combined.Equity)
+	}
+	if combined.Wins != 12 || combined.Losses != 8 || combined.Samples != 20 {
+		t.Errorf("Expected summed counts, got %+v", combined)
+	}
+	// sqrt((2*3)^2 + (4*1)^2) / 4
+	expectedError := math.Sqrt(36+16) / 4
+	if math.Abs(combined.StdError-expectedError) > 1e-9 {
+		t.Errorf("Expected standard error %.4f, got %.4f", expectedError, combined.StdError)
+	}
+	if combined.Exhaustive {
+		t.Error("Expected combined sampled results to be non-exhaustive")
+	}
+}
+
+func TestAdaptiveRangeResult(t *testing.T) {
+	yourHand := cards("As", "Ac", "Kd", "Qd")
+	board := cards("2h", "7d", "Ts")
+	opponentRange := NewUniformRange([][]poker.Card{
+		cards("Ks", "Kc", "Jd", "Td"),
+		cards("Qs", "Qc", "9d", "8d"),
+		cards("Js", "Jc", "6h", "5h"),
+		cards("9s", "8s", "6c", "5c"),
+	})
+
+	// テストケース1: 最小サンプル数がレンジより大きい場合は全ハンドを計算する
+	t.Run("Whole range", func(t *testing.T) {
+		_, result, err := CalculateHandVsWeightedRangeAdaptiveWithDetails(yourHand, opponentRange, board, DefaultAdaptiveConfig(), nil)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if !result.Exhaustive || result.StdError != 0 || result.Samples != len(opponentRange) {
+			t.Errorf("Expected exhaustive result over %d hands, got %+v", len(opponentRange), result)
+		}
+	})
+
+	// テストケース2: 一部のハンドのみの場合はハンド間の分散から誤差を求める
+	t.Run("Partial range", func(t *testing.T) {
+		handResults := []EquityResult{{Equity: 90}, {Equity: 30}}
+		result := sampledRangeResult(handResults, []float64{1, 1}, 4)
+		if result.Exhaustive || result.Samples != 2 {
+			t.Fatalf("Expected non-exhaustive result over 2 hands, got %+v", result)
+		}
+		// 分散900、n=2、有限母集団補正(4-2)/(4-1)
+		expectedError := math.Sqrt(900.0 / 2 * 2 / 3)
+		if math.Abs(result.StdError-expectedError) > 1e-9 {
+			t.Errorf("Expected standard error %.4f, got %.4f", expectedError, result.StdError)
+		}
+	})
+}
@@ -1,6 +1,8 @@
 package poker
 
 import (
+	"context"
+	"math"
 	"testing"
 
 	"github.com/chehsunliu/poker"
@@ -25,14 +27,18 @@ func TestCalculateHandVsHandEquity(t *testing.T) {
 		}
 
 		// 関数の実行
-		equity, cacheHit := CalculateHandVsHandEquity(yourHand, opponentHand, board)
+		result, err := CalculateHandVsHandEquity(yourHand, opponentHand, board)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		equity := result.Equity
 
 		// 結果の検証
 		if equity < 0 {
 			t.Errorf("Expected positive equity, got %.2f", equity)
 		}
-		if cacheHit {
-			t.Error("Expected cache miss, got cache hit")
+		if !result.Exhaustive {
+			t.Error("Expected an exhaustive result")
 		}
 	})
 
@@ -54,14 +60,11 @@ func TestCalculateHandVsHandEquity(t *testing.T) {
 		}
 
 		// 関数の実行
-		equity, cacheHit := CalculateHandVsHandEquity(yourHand, opponentHand, board)
+		_, err := CalculateHandVsHandEquity(yourHand, opponentHand, board)
 
 		// 結果の検証
-		if equity != -1 {
-			t.Errorf("Expected -1 for duplicate cards, got %.2f", equity)
-		}
-		if cacheHit {
-			t.Error("Expected cache miss, got cache hit")
+		if err == nil {
+			t.Error("Expected error for duplicate cards, got nil")
 		}
 	})
 
@@ -83,14 +86,11 @@ func TestCalculateHandVsHandEquity(t *testing.T) {
 		}
 
 		// 関数の実行
-		equity, cacheHit := CalculateHandVsHandEquity(yourHand, opponentHand, board)
+		_, err := CalculateHandVsHandEquity(yourHand, opponentHand, board)
 
 		// 結果の検証
-		if equity != -1 {
-			t.Errorf("Expected -1 for duplicate cards, got %.2f", equity)
-		}
-		if cacheHit {
-			t.Error("Expected cache miss, got cache hit")
+		if err == nil {
+			t.Error("Expected error for duplicate cards, got nil")
 		}
 	})
 
@@ -112,7 +112,11 @@ func TestCalculateHandVsHandEquity(t *testing.T) {
 		}
 
 		// 関数の実行
-		equity, _ := CalculateHandVsHandEquity(yourHand, opponentHand, board)
+		result, err := CalculateHandVsHandEquity(yourHand, opponentHand, board)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		equity := result.Equity
 
 		// 結果の検証 - AA vs KK on dry board should have equity around 80-95%
 		if equity < 75 || equity > 95 {
@@ -138,14 +142,18 @@ func TestCalculateHandVsHandEquity(t *testing.T) {
 		}
 
 		// 関数の実行
-		equity, cacheHit := CalculateHandVsHandEquity(yourHand, opponentHand, board)
+		result, err := CalculateHandVsHandEquity(yourHand, opponentHand, board)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		equity := result.Equity
 
 		// 結果の検証
 		if equity < 0 {
 			t.Errorf("Expected positive equity, got %.2f", equity)
 		}
-		if cacheHit {
-			t.Error("Expected cache miss, got cache hit")
+		if !result.Exhaustive {
+			t.Error("Expected an exhaustive result")
 		}
 	})
 }
@@ -181,7 +189,7 @@ func TestCalculateHandVsRangeEquityParallel(t *testing.T) {
 		}
 
 		// 関数の実行
-		equities, err := CalculateHandVsRangeEquityParallel(yourHand, opponentHands, board)
+		equities, _, err := CalculateHandVsRangeEquityParallel(yourHand, opponentHands, board)
 
 		// 結果の検証
 		if err != nil {
@@ -227,7 +235,7 @@ func TestCalculateHandVsRangeEquityParallel(t *testing.T) {
 		}
 
 		// 関数の実行
-		equities, err := CalculateHandVsRangeEquityParallel(yourHand, opponentHands, board)
+		equities, _, err := CalculateHandVsRangeEquityParallel(yourHand, opponentHands, board)
 
 		// 結果の検証
 		if err != nil {
@@ -256,7 +264,7 @@ func TestCalculateHandVsRangeEquityParallel(t *testing.T) {
 		}
 
 		// 関数の実行
-		_, err := CalculateHandVsRangeEquityParallel(yourHand, opponentHands, board)
+		_, _, err := CalculateHandVsRangeEquityParallel(yourHand, opponentHands, board)
 
 		// 結果の検証
 		if err == nil {
@@ -294,7 +302,7 @@ func TestCalculateHandVsRangeEquityParallel(t *testing.T) {
 		}
 
 		// 関数の実行
-		_, err := CalculateHandVsRangeEquityParallel(yourHand, opponentHands, board)
+		_, _, err := CalculateHandVsRangeEquityParallel(yourHand, opponentHands, board)
 
 		// 結果の検証
 		if err == nil {
@@ -335,7 +343,7 @@ func TestCalculateHandVsRangeEquityParallel(t *testing.T) {
 		}
 
 		// 関数の実行
-		equities, err := CalculateHandVsRangeEquityParallel(yourHand, opponentHands, board)
+		equities, _, err := CalculateHandVsRangeEquityParallel(yourHand, opponentHands, board)
 
 		// 結果の検証
 		if err != nil {
@@ -374,14 +382,18 @@ func TestCalculateHandVsHandEquityPLO(t *testing.T) {
 		}
 
 		// 関数の実行
-		equity, cacheHit := CalculateHandVsHandEquity(yourHand, opponentHand, board)
+		result, err := CalculateHandVsHandEquity(yourHand, opponentHand, board)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		equity := result.Equity
 
 		// 結果の検証
 		if equity < 0 {
 			t.Errorf("Expected positive equity, got %.2f", equity)
 		}
-		if cacheHit {
-			t.Error("Expected cache miss, got cache hit")
+		if !result.Exhaustive {
+			t.Error("Expected an exhaustive result")
 		}
 	})
 
@@ -407,7 +419,11 @@ func TestCalculateHandVsHandEquityPLO(t *testing.T) {
 		}
 
 		// 関数の実行
-		equity, _ := CalculateHandVsHandEquity(yourHand, opponentHand, board)
+		result, err := CalculateHandVsHandEquity(yourHand, opponentHand, board)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		equity := result.Equity
 
 		// 結果の検証 - yourHandのエクイティは低いはず（フラッシュが作れない）
 		if equity > 30 {
@@ -439,7 +455,11 @@ func TestCalculateHandVsHandEquityPLO(t *testing.T) {
 		}
 
 		// 関数の実行
-		equity, _ := CalculateHandVsHandEquity(yourHand, opponentHand, board)
+		result, err := CalculateHandVsHandEquity(yourHand, opponentHand, board)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		equity := result.Equity
 
 		// 結果の検証 - yourHandのエクイティは0（フラッシュが作れない）
 		if equity != 0 {
@@ -473,14 +493,18 @@ func TestCalculateHandVsHandEquityPLO5(t *testing.T) {
 		}
 
 		// 関数の実行
-		equity, cacheHit := CalculateHandVsHandEquity(yourHand, opponentHand, board)
+		result, err := CalculateHandVsHandEquity(yourHand, opponentHand, board)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		equity := result.Equity
 
 		// 結果の検証
 		if equity < 0 {
 			t.Errorf("Expected positive equity, got %.2f", equity)
 		}
-		if cacheHit {
-			t.Error("Expected cache miss, got cache hit")
+		if !result.Exhaustive {
+			t.Error("Expected an exhaustive result")
 		}
 		// PLO5では組み合わせが多いため、エクイティの分散が大きい
 		if equity < 20 || equity > 80 {
@@ -512,7 +536,11 @@ func TestCalculateHandVsHandEquityPLO5(t *testing.T) {
 		}
 
 		// 関数の実行
-		equity, _ := CalculateHandVsHandEquity(yourHand, opponentHand, board)
+		result, err := CalculateHandVsHandEquity(yourHand, opponentHand, board)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		equity := result.Equity
 
 		// 結果の検証 - フラッシュドローがあるのでそこそこのエクイティがあるはず
 		if equity < 25 {
@@ -544,7 +572,11 @@ func TestCalculateHandVsHandEquityPLO5(t *testing.T) {
 		}
 
 		// 関数の実行
-		equity, _ := CalculateHandVsHandEquity(yourHand, opponentHand, board)
+		result, err := CalculateHandVsHandEquity(yourHand, opponentHand, board)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		equity := result.Equity
 
 		// 結果の検証 - 多くのストレートドローがあるのでそこそこのエクイティ
 		if equity < 25 {
@@ -585,7 +617,7 @@ func TestCalculateHandVsHandEquityPLO5(t *testing.T) {
 		}
 
 		// 関数の実行
-		equities, err := CalculateHandVsRangeEquityParallel(yourHand, opponentHands, board)
+		equities, _, err := CalculateHandVsRangeEquityParallel(yourHand, opponentHands, board)
 
 		// 結果の検証
 		if err != nil {
@@ -621,11 +653,416 @@ func TestCalculateHandVsHandEquityPLO5(t *testing.T) {
 		}
 
 		// 関数の実行
-		equity, _ := CalculateHandVsHandEquity(yourHand, opponentHand, board)
+		_, err := CalculateHandVsHandEquity(yourHand, opponentHand, board)
 
 		// 結果の検証
-		if equity != -1 {
-			t.Errorf("Expected -1 for duplicate cards, got %.2f", equity)
+		if err == nil {
+			t.Error("Expected error for duplicate cards, got nil")
+		}
+	})
+}
+func TestCalculateHandVsWeightedRangeEquityParallel(t *testing.T) {
+	yourHand := []poker.Card{
+		poker.NewCard("Ah"),
+		poker.NewCard("Ad"),
+		poker.NewCard("Kc"),
+		poker.NewCard("Qc"),
+	}
+	strongHand := []poker.Card{
+		poker.NewCard("7h"),
+		poker.NewCard("7c"),
+		poker.NewCard("Jh"),
+		poker.NewCard("Jd"),
+	}
+	weakHand := []poker.Card{
+		poker.NewCard("3h"),
+		poker.NewCard("4h"),
+		poker.NewCard("5s"),
+		poker.NewCard("6s"),
+	}
+	board := []poker.Card{
+		poker.NewCard("2c"),
+		poker.NewCard("7d"),
+		poker.NewCard("Ts"),
+	}
+
+	// テストケース1: 平均エクイティが頻度で重み付けされる
+	t.Run("Average equity is weighted by frequency", func(t *testing.T) {
+		opponentRange := WeightedRange{
+			{Cards: strongHand, Weight: 1.0},
+			{Cards: weakHand, Weight: 0.01},
+		}
+
+		equities, rangeResult, err := CalculateHandVsWeightedRangeEquityParallel(yourHand, opponentRange, board)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if len(equities) != 2 {
+			t.Fatalf("Expected 2 equity results, got %d", len(equities))
+		}
+		if !rangeResult.Exhaustive || rangeResult.StdError != 0 {
+			t.Errorf("Expected an exhaustive result without standard error, got %+v", rangeResult)
+		}
+		averageEquity := rangeResult.Equity
+
+		strongEquity := equities[GenerateBoardString(strongHand)]
+		weakEquity := equities[GenerateBoardString(weakHand)]
+		expected := (strongEquity*1.0 + weakEquity*0.01) / 1.01
+		if averageEquity < expected-1e-9 || averageEquity > expected+1e-9 {
+			t.Errorf("Expected weighted average %.4f, got %.4f", expected, averageEquity)
+		}
+
+		// 単純平均とは異なることを確認
+		simpleAverage := (strongEquity + weakEquity) / 2
+		if averageEquity >= simpleAverage {
+			t.Errorf("Expected weighted average %.2f to be below simple average %.2f", averageEquity, simpleAverage)
+		}
+	})
+
+	// テストケース2: 頻度0のハンドは除外される
+	t.Run("Zero weight hands are skipped", func(t *testing.T) {
+		opponentRange := WeightedRange{
+			{Cards: strongHand, Weight: 1.0},
+			{Cards: weakHand, Weight: 0},
+		}
+
+		equities, _, err := CalculateHandVsWeightedRangeEquityParallel(yourHand, opponentRange, board)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if len(equities) != 1 {
+			t.Errorf("Expected 1 equity result, got %d", len(equities))
+		}
+	})
+
+	// テストケース3: モンテカルロでも平均エクイティが頻度で重み付けされ、頻度0のハンドは除外される
+	t.Run("Monte Carlo average equity is weighted by frequency", func(t *testing.T) {
+		opponentRange := WeightedRange{
+			{Cards: strongHand, Weight: 1.0},
+			{Cards: weakHand, Weight: 0.01},
+			{Cards: cards("8h", "8c", "9h", "9d"), Weight: 0},
+		}
+
+		equities, rangeResult, err := CalculateHandVsWeightedRangeEquityMonteCarloParallel(yourHand, opponentRange, board, "FAST", NewRand(1))
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if len(equities) != 2 {
+			t.Fatalf("Expected 2 equity results, got %d", len(equities))
+		}
+
+		strongEquity := equities[GenerateBoardString(strongHand)]
+		weakEquity := equities[GenerateBoardString(weakHand)]
+		expected := (strongEquity*1.0 + weakEquity*0.01) / 1.01
+		if math.Abs(rangeResult.Equity-expected) > 1e-9 {
+			t.Errorf("Expected weighted average %.4f, got %.4f", expected, rangeResult.Equity)
+		}
+
+		// 頻度を無視する従来の呼び出しは単純平均になる
+		_, uniformResult, err := CalculateHandVsRangeEquityMonteCarloParallel(yourHand, [][]poker.Card{strongHand, weakHand}, board, "FAST", NewRand(1))
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if math.Abs(uniformResult.Equity-(strongEquity+weakEquity)/2) > 1e-9 {
+			t.Errorf("Expected simple average %.4f, got %.4f", (strongEquity+weakEquity)/2, uniformResult.Equity)
+		}
+	})
+}
+
+func TestCalculateHandVsHandEquityBoardSizes(t *testing.T) {
+	// テストケース1: ターン（4枚ボード）では1枚だけ配る
+	t.Run("Turn board", func(t *testing.T) {
+		// AA vs KK、ターンまでKが落ちていない → Kのアウツは残り2枚
+		yourHand := []poker.Card{poker.NewCard("As"), poker.NewCard("Ac")}
+		opponentHand := []poker.Card{poker.NewCard("Ks"), poker.NewCard("Kc")}
+		board := []poker.Card{
+			poker.NewCard("2h"),
+			poker.NewCard("7d"),
+			poker.NewCard("Th"),
+			poker.NewCard("3c"),
+		}
+
+		result, err := CalculateHandVsHandEquity(yourHand, opponentHand, board)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		equity := result.Equity
+
+		// 残り44枚中、Kd・Khの2枚でKKが逆転
+		expected := 42.0 / 44.0 * 100
+		if equity < expected-0.01 || equity > expected+0.01 {
+			t.Errorf("Expected equity %.2f%%, got %.2f%%", expected, equity)
+		}
+	})
+
+	// テストケース2: リバー（5枚ボード）では勝敗が確定する
+	t.Run("River board", func(t *testing.T) {
+		yourHand := []poker.Card{
+			poker.NewCard("Ah"),
+			poker.NewCard("Kh"),
+			poker.NewCard("2c"),
+			poker.NewCard("3d"),
+		}
+		opponentHand := []poker.Card{
+			poker.NewCard("Qs"),
+			poker.NewCard("Qd"),
+			poker.NewCard("4c"),
+			poker.NewCard("5d"),
+		}
+		board := []poker.Card{
+			poker.NewCard("Qh"),
+			poker.NewCard("Jh"),
+			poker.NewCard("Th"),
+			poker.NewCard("8c"),
+			poker.NewCard("9s"),
+		}
+
+		result, err := CalculateHandVsHandEquity(yourHand, opponentHand, board)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		equity := result.Equity
+
+		// AhKhのロイヤルフラッシュが確定
+		if equity != 100 {
+			t.Errorf("Expected 100%% equity on the river, got %.2f%%", equity)
+		}
+	})
+
+	// テストケース3: PLOのターンでもボードから3枚のみ使用する
+	t.Run("PLO turn board", func(t *testing.T) {
+		yourHand := []poker.Card{
+			poker.NewCard("Ah"),
+			poker.NewCard("Kh"),
+			poker.NewCard("2c"),
+			poker.NewCard("3d"),
+		}
+		opponentHand := []poker.Card{
+			poker.NewCard("Qs"),
+			poker.NewCard("Qd"),
+			poker.NewCard("4c"),
+			poker.NewCard("5d"),
+		}
+		board := []poker.Card{
+			poker.NewCard("Qh"),
+			poker.NewCard("Jh"),
+			poker.NewCard("Th"),
+			poker.NewCard("8c"),
+		}
+
+		result, err := CalculateHandVsHandEquity(yourHand, opponentHand, board)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		equity := result.Equity
+
+		// ターンでロイヤルフラッシュ完成済み
+		if equity != 100 {
+			t.Errorf("Expected 100%% equity with a made royal flush, got %.2f%%", equity)
+		}
+	})
+
+	// テストケース4: 不正なボード枚数
+	t.Run("Invalid board size", func(t *testing.T) {
+		yourHand := []poker.Card{poker.NewCard("As"), poker.NewCard("Ac")}
+		opponentHand := []poker.Card{poker.NewCard("Ks"), poker.NewCard("Kc")}
+		board := []poker.Card{poker.NewCard("2h"), poker.NewCard("7d")}
+
+		_, err := CalculateHandVsHandEquity(yourHand, opponentHand, board)
+		if err == nil {
+			t.Error("Expected error for invalid board size, got nil")
+		}
+
+		if _, err := CalculateHandVsHandEquityMonteCarlo(yourHand, opponentHand, board, 100, nil); err == nil {
+			t.Error("Expected error for invalid board size in Monte Carlo, got nil")
+		}
+	})
+}
+
+func TestCalculateHandVsHandEquityMonteCarloBoardSizes(t *testing.T) {
+	// テストケース1: プリフロップ（0枚ボード）では5枚配る
+	t.Run("Preflop board", func(t *testing.T) {
+		yourHand := []poker.Card{poker.NewCard("As"), poker.NewCard("Ac")}
+		opponentHand := []poker.Card{poker.NewCard("Ks"), poker.NewCard("Kc")}
+
+		result, err := CalculateHandVsHandEquityMonteCarlo(yourHand, opponentHand, []poker.Card{}, ACCURATE_ITERATIONS, nil)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		equity := result.Equity
+
+		// AA vs KK プリフロップは約82%
+		if equity < 78 || equity > 86 {
+			t.Errorf("Expected preflop AA vs KK equity around 82%%, got %.2f%%", equity)
+		}
+	})
+
+	// テストケース2: ターン（4枚ボード）ではexhaustiveと近い値になる
+	t.Run("Turn board", func(t *testing.T) {
+		yourHand := []poker.Card{poker.NewCard("As"), poker.NewCard("Ac")}
+		opponentHand := []poker.Card{poker.NewCard("Ks"), poker.NewCard("Kc")}
+		board := []poker.Card{
+			poker.NewCard("2h"),
+			poker.NewCard("7d"),
+			poker.NewCard("Th"),
+			poker.NewCard("3c"),
+		}
+
+		result, err := CalculateHandVsHandEquityMonteCarlo(yourHand, opponentHand, board, ACCURATE_ITERATIONS, nil)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		equity := result.Equity
+
+		expected := 42.0 / 44.0 * 100
+		if equity < expected-2 || equity > expected+2 {
+			t.Errorf("Expected equity around %.2f%%, got %.2f%%", expected, equity)
+		}
+	})
+}
+
+func TestCalculateHandVsHandEquityPLO6(t *testing.T) {
+	yourHand := []poker.Card{
+		poker.NewCard("Ah"),
+		poker.NewCard("Ad"),
+		poker.NewCard("Kh"),
+		poker.NewCard("Qc"),
+		poker.NewCard("Jd"),
+		poker.NewCard("9c"),
+	}
+	opponentHand := []poker.Card{
+		poker.NewCard("Kc"),
+		poker.NewCard("Kd"),
+		poker.NewCard("Jc"),
+		poker.NewCard("Tc"),
+		poker.NewCard("9s"),
+		poker.NewCard("8d"),
+	}
+	board := []poker.Card{
+		poker.NewCard("2h"),
+		poker.NewCard("7d"),
+		poker.NewCard("Ts"),
+	}
+
+	// テストケース1: PLO6 - 双方のエクイティの合計が100%になる
+	t.Run("PLO6 - Equities sum to 100", func(t *testing.T) {
+		result, err := CalculateHandVsHandEquity(yourHand, opponentHand, board)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		equity := result.Equity
+		reverseEquityResult, err := CalculateHandVsHandEquity(opponentHand, yourHand, board)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		reverseEquity := reverseEquityResult.Equity
+
+		if equity <= 0 || equity >= 100 {
+			t.Fatalf("Expected equity between 0 and 100, got %.2f%%", equity)
+		}
+		if math.Abs(equity+reverseEquity-100) > 0.0001 {
+			t.Errorf("Expected equities to sum to 100%%, got %.2f%% + %.2f%%", equity, reverseEquity)
 		}
 	})
-}
\ No newline at end of file
+
+	// テストケース2: PLO6 - モンテカルロは全数計算と近い値になる
+	t.Run("PLO6 - Monte Carlo matches exhaustive", func(t *testing.T) {
+		expectedResult, err := CalculateHandVsHandEquity(yourHand, opponentHand, board)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		expected := expectedResult.Equity
+
+		result, err := CalculateHandVsHandEquityMonteCarlo(yourHand, opponentHand, board, ACCURATE_ITERATIONS, nil)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		equity := result.Equity
+		if math.Abs(equity-expected) > 2 {
+			t.Errorf("Expected equity around %.2f%%, got %.2f%%", expected, equity)
+		}
+	})
+}
+
+func TestCalculateEquityWithDeadCards(t *testing.T) {
+	yourHand := cards("As", "Ac")
+	opponentHand := cards("Ks", "Kc")
+	board := cards("2h", "7d", "Th", "3c")
+
+	// テストケース1: デッドカードはランアウトに配られない
+	t.Run("Dead cards are removed from runouts", func(t *testing.T) {
+		result, err := CalculateHandVsHandEquityWithDeadCards(yourHand, opponentHand, board, cards("Kd"))
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		// 残り43枚中、Khの1枚だけでKKが逆転
+		if result.Wins+result.Ties+result.Losses != 43 || result.Losses != 1 {
+			t.Errorf("Expected 42 wins and 1 loss over 43 runouts, got %+v", result)
+		}
+
+		// KKのアウツが両方デッドならAAの勝ちが確定する
+		result, err = CalculateHandVsHandEquityWithDeadCards(yourHand, opponentHand, board, cards("Kd", "Kh"))
+		if err != nil || result.Equity != 100 {
+			t.Errorf("Expected 100%% equity, got %.2f%% (%v)", result.Equity, err)
+		}
+		mcResult, err := CalculateHandVsHandEquityMonteCarloWithDeadCards(yourHand, opponentHand, board, cards("Kd", "Kh"), FAST_ITERATIONS, NewRand(1))
+		if err != nil || mcResult.Equity != 100 {
+			t.Errorf("Expected 100%% Monte Carlo equity, got %.2f%% (%v)", mcResult.Equity, err)
+		}
+	})
+
+	// テストケース2: デッドカードと重複するカードはエラー
+	t.Run("Duplicate dead card", func(t *testing.T) {
+		if _, err := CalculateHandVsHandEquityWithDeadCards(yourHand, opponentHand, board, cards("As")); err == nil {
+			t.Error("Expected error for a dead card in the hand, got nil")
+		}
+		if _, err := CalculateHandVsHandEquityMonteCarloWithDeadCards(yourHand, opponentHand, board, cards("2h"), 100, nil); err == nil {
+			t.Error("Expected error for a dead card on the board, got nil")
+		}
+		if _, _, err := CalculateHandVsWeightedRangeEquityParallelContext(context.Background(), yourHand, NewUniformRange([][]poker.Card{opponentHand}), board, cards("Ac"), nil); err == nil {
+			t.Error("Expected error for a dead card in the hero hand, got nil")
+		}
+	})
+
+	// テストケース3: デッドカードを含む相手ハンドはレンジから除外される
+	t.Run("Range excludes dead combos", func(t *testing.T) {
+		deadCards := cards("Kd")
+		opponentRange := NewUniformRange([][]poker.Card{
+			cards("Ks", "Kc"),
+			cards("Kd", "Kh"), // デッドカードを含む
+			cards("Qs", "Qc"),
+		})
+
+		equities, result, err := CalculateHandVsWeightedRangeEquityParallelContext(context.Background(), yourHand, opponentRange, board, deadCards, nil)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if len(equities) != 2 {
+			t.Fatalf("Expected 2 hands, got %v", equities)
+		}
+		if _, ok := equities["KdKh"]; ok {
+			t.Errorf("Expected KdKh to be excluded, got %v", equities)
+		}
+		if result.Wins+result.Ties+result.Losses != 2*43 {
+			t.Errorf("Expected 43 runouts per hand, got %+v", result)
+		}
+
+		mcEquities, _, err := CalculateHandVsRangeEquityMonteCarloParallelContext(context.Background(), yourHand, [][]poker.Card{cards("Kd", "Kh"), cards("Qs", "Qc")}, board, deadCards, "FAST", NewRand(1), nil)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if len(mcEquities) != 1 {
+			t.Errorf("Expected only QsQc, got %v", mcEquities)
+		}
+	})
+
+	// テストケース4: スートの対称性はデッドカードの集合も固定する
+	t.Run("Isomorphism fixes dead cards", func(t *testing.T) {
+		if size := NewSuitIsomorphism(yourHand, nil).Size(); size != 4 {
+			t.Errorf("Expected 4 permutations without dead cards, got %d", size)
+		}
+		if size := NewSuitIsomorphism(yourHand, nil, cards("Kh")...).Size(); size != 2 {
+			t.Errorf("Expected 2 permutations with a dead card, got %d", size)
+		}
+	})
+}
@@ -0,0 +1,221 @@
+package poker
+
+import (
+	"fmt"
+
+	"github.com/chehsunliu/poker"
+)
+
+// EVの計算はポット・ベット・スタックを同じ単位（通常はBB）で扱い、
+// エクイティはほかの計算と同じく%で受け取ります
+// コールの後はショーダウンまでエクイティをすべて実現すると仮定します
+// この仮定はコールでオールインになる場合（AllInBetなど）にのみ正確で、スタックが残る場合は近似になります
+
+// Rake はレーキの設定です
+type Rake struct {
+	Percent float64 `json:"percent"` // ポットに対するレーキの割合（%）
+	Cap     float64 `json:"cap"`     // レーキの上限（0の場合は上限なし）
+}
+
+// MidRake はプリセットのレンジ（six_handed_100bb_midrake）が想定するレーキです（5%、上限3BB）
+var MidRake = Rake{Percent: 5, Cap: 3}
+
+// Amount はポットから差し引かれるレーキの額を返します
+func (r Rake) Amount(pot float64) float64 {
+	rake := pot * r.Percent / 100
+	if r.Cap > 0 && rake > r.Cap {
+		return r.Cap
+	}
+	return rake
+}
+
+// validate はレーキの設定を検証します
+func (r Rake) validate() error {
+	if !(r.Percent >= 0 && r.Percent < 100) {
+		return fmt.Errorf("rake percent must be between 0 and 100, got %v", r.Percent)
+	}
+	if !(r.Cap >= 0) {
+		return fmt.Errorf("rake cap must not be negative, got %v", r.Cap)
+	}
+	return nil
+}
+
+// Action はクイズの正解となるアクションです
+type Action string
+
+const (
+	ActionCall Action = "call"
+	ActionFold Action = "fold"
+)
+
+// BetSpot は相手のベットに対するヒーローのコール判断の状況です
+type BetSpot struct {
+	Pot   float64 `json:"pot"`   // 相手のベット前のポット
+	Bet   float64 `json:"bet"`   // 相手のベット額
+	Stack float64 `json:"stack"` // 相手のベット前のエフェクティブスタック
+	Rake  Rake    `json:"rake"`
+}
+
+// PotSizedBet は相手がポットサイズ（スタックが足りない場合はオールイン）をベットした状況を返します
+func PotSizedBet(pot float64, stack float64, rake Rake) BetSpot {
+	return BetSpot{Pot: pot, Bet: min(pot, stack), Stack: stack, Rake: rake}
+}
+
+// AllInBet は相手がエフェクティブスタックをオールインした状況を返します
+func AllInBet(pot float64, stack float64, rake Rake) BetSpot {
+	return BetSpot{Pot: pot, Bet: stack, Stack: stack, Rake: rake}
+}
+
+// IsAllIn はコールするとエフェクティブスタックがすべてポットに入るかを返します
+func (s BetSpot) IsAllIn() bool {
+	return s.CallAmount() >= s.Stack
+}
+
+// Validate は状況を検証します（NaNは不正な値として扱います）
+func (s BetSpot) Validate() error {
+	if !(s.Pot > 0) {
+		return fmt.Errorf("pot must be positive, got %v", s.Pot)
+	}
+	if !(s.Bet > 0) {
+		return fmt.Errorf("bet must be positive, got %v", s.Bet)
+	}
+	if !(s.Stack > 0) {
+		return fmt.Errorf("stack must be positive, got %v", s.Stack)
+	}
+	return s.Rake.validate()
+}
+
+// CallAmount はコールに必要な額を返します（ベットがスタックを超える場合、超えた分は相手に返るためスタックまで）
+func (s BetSpot) CallAmount() float64 {
+	return min(s.Bet, s.Stack)
+}
+
+// finalPot はコールした場合のレーキを差し引いた最終的なポットを返します
+func (s BetSpot) finalPot() float64 {
+	pot := s.Pot + 2*s.CallAmount()
+	return pot - s.Rake.Amount(pot)
+}
+
+// BreakEvenEquity はコールのEVが0になるエクイティ（%）を返します
+func (s BetSpot) BreakEvenEquity() float64 {
+	return s.CallAmount() / s.finalPot() * 100
+}
+
+// CallEV はエクイティ（%）でコールした場合のEVを返します（フォールドのEVを0とする）
+func (s BetSpot) CallEV(equity float64) float64 {
+	return equity/100*s.finalPot() - s.CallAmount()
+}
+
+// CallDecision はコールかフォールドかのクイズの問題と正解です
+type CallDecision struct {
+	BetSpot
+	Equity          float64 `json:"equity"`            // ヒーローのエクイティ（%）
+	CallEV          float64 `json:"call_ev"`           // コールのEV
+	BreakEvenEquity float64 `json:"break_even_equity"` // コールに必要なエクイティ（%）
+	AllIn           bool    `json:"all_in"`            // コールでオールインになるか（falseの場合、エクイティをすべて実現する仮定は近似）
+	Action          Action  `json:"action"`            // 正解（コールのEVが正の場合はcall）
+}
+
+// DecideCall はエクイティ（%）から相手のベットにコールすべきかを判断します
+func DecideCall(spot BetSpot, equity float64) (CallDecision, error) {
+	if err := spot.Validate(); err != nil {
+		return CallDecision{}, err
+	}
+	if !isPercent(equity) {
+		return CallDecision{}, fmt.Errorf("equity must be between 0 and 100, got %v", equity)
+	}
+
+	decision := CallDecision{
+		BetSpot:         spot,
+		Equity:          equity,
+		CallEV:          spot.CallEV(equity),
+		BreakEvenEquity: spot.BreakEvenEquity(),
+		AllIn:           spot.IsAllIn(),
+		Action:          ActionFold,
+	}
+	if decision.CallEV > 0 {
+		decision.Action = ActionCall
+	}
+	return decision, nil
+}
+
+// JamEV はヒーローがエフェクティブスタックをオールインした場合のEVです
+type JamEV struct {
+	FoldFrequency float64 `json:"fold_frequency"` // 相手がフォールドする割合（%）
+	Equity        float64 `json:"equity"`         // コールされた場合のエクイティ（%）
+	EV            float64 `json:"ev"`             // チェック・フォールドのEVを0としたオールインのEV
+}
+
+// CalculateJamEV は相手のフォールド率（%）とコールされた場合のエクイティ（%）からオールインのEVを計算します
+// フォールドされた場合はポットを、コールされた場合はpot+2*stackをエクイティの割合で獲得します（いずれもレーキを差し引く）
+func CalculateJamEV(pot float64, stack float64, rake Rake, foldFrequency float64, equity float64) (JamEV, error) {
+	if !(pot > 0 && stack > 0) {
+		return JamEV{}, fmt.Errorf("pot and stack must be positive, got %v and %v", pot, stack)
+	}
+	if err := rake.validate(); err != nil {
+		return JamEV{}, err
+	}
+	if !isPercent(foldFrequency) {
+		return JamEV{}, fmt.Errorf("fold frequency must be between 0 and 100, got %v", foldFrequency)
+	}
+	if !isPercent(equity) {
+		return JamEV{}, fmt.Errorf("equity must be between 0 and 100, got %v", equity)
+	}
+
+	calledPot := pot + 2*stack
+	foldEV := pot - rake.Amount(pot)
+	callEV := equity/100*(calledPot-rake.Amount(calledPot)) - stack
+	return JamEV{
+		FoldFrequency: foldFrequency,
+		Equity:        equity,
+		EV:            foldFrequency/100*foldEV + (1-foldFrequency/100)*callEV,
+	}, nil
+}
+
+// CalculateJamEVVsRange は相手のレンジとコールするレンジからオールインのEVを計算します
+// callingRangeはopponentRangeのうちコールするハンドとその頻度（FilterRangeの結果など）で、
+// フォールド率はヒーローのハンドとボードを除外したレンジの頻度の合計に対するcallingRangeの頻度の合計から求めます
+// コールされた場合のエクイティはCalculateHandVsWeightedRangeEquityParallelで全数計算するため、
+// ボードはフロップ以降（3〜5枚）に限ります（プリフロップはCalculatePreflopEquityVsRangeとCalculateJamEVを使用してください）
+func CalculateJamEVVsRange(yourHand []poker.Card, opponentRange WeightedRange, callingRange WeightedRange, board []poker.Card, pot float64, stack float64, rake Rake) (JamEV, error) {
+	if len(board) < 3 {
+		return JamEV{}, fmt.Errorf("board must have 3 to 5 cards, got %d", len(board))
+	}
+
+	rangeWeight := liveRangeWeight(yourHand, opponentRange, board)
+	if rangeWeight == 0 {
+		return JamEV{}, fmt.Errorf("no valid hands in opponent range")
+	}
+	callingWeight := liveRangeWeight(yourHand, callingRange, board)
+	if callingWeight > rangeWeight*(1+1e-9) {
+		return JamEV{}, fmt.Errorf("calling range must be a subset of the opponent range")
+	}
+	foldFrequency := max(0, (1-callingWeight/rangeWeight)*100)
+
+	// 相手が常にフォールドする場合はエクイティを計算しない
+	var equity float64
+	if callingWeight > 0 {
+		_, result, err := CalculateHandVsWeightedRangeEquityParallel(yourHand, callingRange, board)
+		if err != nil {
+			return JamEV{}, err
+		}
+		equity = result.Equity
+	}
+	return CalculateJamEV(pot, stack, rake, foldFrequency, equity)
+}
+
+// isPercent は値が0〜100%の範囲にあるかを返します（NaNはfalse）
+func isPercent(value float64) bool {
+	return value >= 0 && value <= 100
+}
+
+// liveRangeWeight はヒーローのハンドとボードを除外したレンジの頻度の合計を返します
+func liveRangeWeight(yourHand []poker.Card, weightedRange WeightedRange, board []poker.Card) float64 {
+	var total float64
+	for _, hand := range weightedRange {
+		if hand.Weight > 0 && !HasCardDuplicates(yourHand, hand.Cards, board) {
+			total += hand.Weight
+		}
+	}
+	return total
+}
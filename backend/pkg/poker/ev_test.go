@@ -0,0 +1,177 @@
+package poker
+
+import (
+	"encoding/json"
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestRakeAmount(t *testing.T) {
+	tests := []struct {
+		rake Rake
+		pot  float64
+		want float64
+	}{
+		{Rake{Percent: 5}, 100, 5},
+		{MidRake, 20, 1},
+		{MidRake, 100, 3}, // 上限
+		{Rake{}, 100, 0},
+	}
+	for _, tt := range tests {
+		if got := tt.rake.Amount(tt.pot); math.Abs(got-tt.want) > 1e-9 {
+			t.Errorf("%+v.Amount(%v): expected %v, got %v", tt.rake, tt.pot, tt.want, got)
+		}
+	}
+}
+
+func TestDecideCall(t *testing.T) {
+	tests := []struct {
+		name          string
+		spot          BetSpot
+		equity        float64
+		wantBreakEven float64
+		wantEV        float64
+		wantAction    Action
+		wantAllIn     bool
+	}{
+		// ポットサイズのベットには33.3%のエクイティが必要
+		{"Pot-sized bet call", PotSizedBet(10, 100, Rake{}), 40, 100.0 / 3, 2, ActionCall, false},
+		{"Pot-sized bet fold", PotSizedBet(10, 100, Rake{}), 30, 100.0 / 3, -1, ActionFold, false},
+		// レーキ（最終的なポット30の5%）の分だけ必要なエクイティが増える
+		{"Pot-sized bet with rake", PotSizedBet(10, 100, MidRake), 35, 10 / 28.5 * 100, 0.35*28.5 - 10, ActionFold, false},
+		// スタックが足りない場合はオールイン
+		{"Short stack", PotSizedBet(100, 40, Rake{}), 25, 40.0 / 180 * 100, 5, ActionCall, true},
+		// スタックを超えるベットはスタックまでコールする
+		{"Overbet all-in", BetSpot{Pot: 10, Bet: 50, Stack: 20}, 50, 40, 5, ActionCall, true},
+		// オールインのコールは最終的なポット205（レーキは上限の3）に対して100
+		{"All-in", AllInBet(5, 100, MidRake), 50, 100.0 / 202 * 100, 1, ActionCall, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			decision, err := DecideCall(tt.spot, tt.equity)
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if math.Abs(decision.BreakEvenEquity-tt.wantBreakEven) > 1e-9 {
+				t.Errorf("Expected break-even equity %.4f%%, got %.4f%%", tt.wantBreakEven, decision.BreakEvenEquity)
+			}
+			if math.Abs(decision.CallEV-tt.wantEV) > 1e-9 {
+				t.Errorf("Expected call EV %.4f, got %.4f", tt.wantEV, decision.CallEV)
+			}
+			if decision.Action != tt.wantAction {
+				t.Errorf("Expected %s, got %s", tt.wantAction, decision.Action)
+			}
+			if decision.AllIn != tt.wantAllIn {
+				t.Errorf("Expected all-in %v, got %v", tt.wantAllIn, decision.AllIn)
+			}
+		})
+	}
+
+	t.Run("JSON", func(t *testing.T) {
+		decision, err := DecideCall(PotSizedBet(10, 100, Rake{}), 40)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		data, err := json.Marshal(decision)
+		if err != nil {
+			t.Fatalf("Failed to marshal: %v", err)
+		}
+		if !strings.HasPrefix(string(data), `{"pot":10,"bet":10,"stack":100,"rake":{"percent":0,"cap":0},"equity":40,"call_ev":2,`) ||
+			!strings.HasSuffix(string(data), `"action":"call"}`) {
+			t.Errorf("Unexpected JSON: %s", data)
+		}
+	})
+
+	t.Run("Rejects invalid input", func(t *testing.T) {
+		if _, err := DecideCall(BetSpot{Pot: 0, Bet: 10, Stack: 100}, 50); err == nil {
+			t.Errorf("Expected an error for an empty pot")
+		}
+		if _, err := DecideCall(BetSpot{Pot: 10, Bet: 10, Stack: 100, Rake: Rake{Percent: -1}}, 50); err == nil {
+			t.Errorf("Expected an error for a negative rake")
+		}
+		if _, err := DecideCall(PotSizedBet(10, 100, Rake{}), 101); err == nil {
+			t.Errorf("Expected an error for equity over 100%%")
+		}
+		if _, err := DecideCall(PotSizedBet(10, 100, Rake{}), math.NaN()); err == nil {
+			t.Errorf("Expected an error for NaN equity")
+		}
+	})
+}
+
+func TestCalculateJamEV(t *testing.T) {
+	// フォールドで10、コールされた場合は0.5*30-10=5
+	result, err := CalculateJamEV(10, 10, Rake{}, 50, 50)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if math.Abs(result.EV-7.5) > 1e-9 {
+		t.Errorf("Expected EV 7.5, got %v", result.EV)
+	}
+
+	// レーキはフォールドされた場合とコールされた場合のそれぞれのポットから差し引く
+	result, err = CalculateJamEV(10, 10, MidRake, 50, 50)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if want := 0.5*9.5 + 0.5*(0.5*28.5-10); math.Abs(result.EV-want) > 1e-9 {
+		t.Errorf("Expected EV %v, got %v", want, result.EV)
+	}
+
+	if _, err := CalculateJamEV(10, 10, Rake{}, 120, 50); err == nil {
+		t.Errorf("Expected an error for fold frequency over 100%%")
+	}
+	if _, err := CalculateJamEV(10, 0, Rake{}, 50, 50); err == nil {
+		t.Errorf("Expected an error for an empty stack")
+	}
+}
+
+func TestCalculateJamEVVsRange(t *testing.T) {
+	// ヒーローはロイヤルフラッシュ（エクイティ100%）
+	board := cards("As", "Ks", "Qs", "2d", "3c")
+	yourHand := cards("Js", "Ts", "4h", "4d")
+	opponentRange := WeightedRange{
+		{Cards: cards("Ah", "Ad", "Kh", "Kd"), Weight: 1.0},
+		{Cards: cards("Qh", "Qd", "5h", "5d"), Weight: 1.0},
+		{Cards: cards("6h", "6d", "7h", "7d"), Weight: 1.0},
+		{Cards: cards("8h", "8d", "9h", "9d"), Weight: 1.0},
+		{Cards: cards("Js", "Jd", "9c", "8c"), Weight: 1.0}, // ヒーローのハンドと重複するため除外
+	}
+	callingRange := opponentRange[:1]
+
+	result, err := CalculateJamEVVsRange(yourHand, opponentRange, callingRange, board, 10, 10, Rake{})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if math.Abs(result.FoldFrequency-75) > 1e-9 {
+		t.Errorf("Expected fold frequency 75%%, got %.2f%%", result.FoldFrequency)
+	}
+	if result.Equity != 100 {
+		t.Errorf("Expected equity 100%%, got %.2f%%", result.Equity)
+	}
+	// 0.75*10 + 0.25*(30-10)
+	if math.Abs(result.EV-12.5) > 1e-9 {
+		t.Errorf("Expected EV 12.5, got %v", result.EV)
+	}
+
+	t.Run("Always folds", func(t *testing.T) {
+		result, err := CalculateJamEVVsRange(yourHand, opponentRange, nil, board, 10, 10, Rake{})
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if result.FoldFrequency != 100 || result.EV != 10 {
+			t.Errorf("Expected to win the pot uncontested, got %+v", result)
+		}
+	})
+
+	t.Run("Rejects invalid input", func(t *testing.T) {
+		if _, err := CalculateJamEVVsRange(yourHand, opponentRange, callingRange, nil, 10, 10, Rake{}); err == nil {
+			t.Errorf("Expected an error for a preflop board")
+		}
+		doubled := WeightedRange{{Cards: opponentRange[0].Cards, Weight: 5.0}}
+		if _, err := CalculateJamEVVsRange(yourHand, opponentRange, doubled, board, 10, 10, Rake{}); err == nil {
+			t.Errorf("Expected an error when the calling range is larger than the opponent range")
+		}
+	})
+}
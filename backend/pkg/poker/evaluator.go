@@ -0,0 +1,231 @@
+package poker
+
+import (
+	"github.com/chehsunliu/poker"
+)
+
+// worstHandRank は最も弱いランクです（5枚が揃わない場合の初期値にも使用します）
+const worstHandRank int32 = 7462
+
+// maxOmahaHandPairs はハンドから選ぶ2枚の組み合わせの最大数です（PLO6: C(6,2) = 15）
+const maxOmahaHandPairs = 15
+
+// boardPairIndices[n] はボードのn枚から2枚を選ぶ組み合わせのインデックスです
+// 新しく追加したカードと組み合わせて、そのカードを含むボードの3枚を作ります
+var boardPairIndices = [5][][2]int{
+	2: {{0, 1}},
+	3: {{0, 1}, {0, 2}, {1, 2}},
+	4: {{0, 1}, {0, 2}, {0, 3}, {1, 2}, {1, 3}, {2, 3}},
+}
+
+// evaluatorHand は評価器が保持するハンドと、事前に計算したハンドの2枚の組み合わせです
+type evaluatorHand struct {
+	cards    [6]poker.Card
+	size     int
+	pairs    [maxOmahaHandPairs][2]poker.Card
+	numPairs int
+}
+
+// set はハンドのカードと2枚の組み合わせを設定します
+func (h *evaluatorHand) set(hand []poker.Card) {
+	h.size = copy(h.cards[:], hand)
+	h.numPairs = 0
+	for i := 0; i < h.size; i++ {
+		for j := i + 1; j < h.size; j++ {
+			h.pairs[h.numPairs] = [2]poker.Card{h.cards[i], h.cards[j]}
+			h.numPairs++
+		}
+	}
+}
+
+// ShowdownEvaluator は2つのハンドのショーダウンを割り当てなしで判定する評価器です
+// ハンドの2枚の組み合わせを事前に計算し、ボードはPushで1枚ずつ追加して、
+// 追加したカードを含む5枚の組み合わせのみを評価します（フロップの3枚はSetBoardで1回だけ評価し、
+// ターン・リバーはそれまでの最良ランクとの差分だけを評価します）
+// 作業用のバッファを内部に持つため、ゴルーチンごとに別の評価器を使用してください
+type ShowdownEvaluator struct {
+	variant   Variant
+	hands     [2]evaluatorHand
+	board     [5]poker.Card
+	boardSize int
+	best      [2][6]int32 // best[p][n] はボードのn枚目までを使ったプレイヤーpの最良ランク
+	scratch   [5]poker.Card
+}
+
+// NewShowdownEvaluator はヒーローと相手のハンドの評価器を作成します（ボードは空の状態です）
+// ハンドの枚数はvariantと一致している必要があります
+func NewShowdownEvaluator(variant Variant, yourHand []poker.Card, opponentHand []poker.Card) *ShowdownEvaluator {
+	e := &ShowdownEvaluator{variant: variant}
+	e.SetHands(yourHand, opponentHand)
+	return e
+}
+
+// SetHands は評価するハンドを入れ替えます（現在のボードは維持し、最良ランクを再計算します）
+func (e *ShowdownEvaluator) SetHands(yourHand []poker.Card, opponentHand []poker.Card) {
+	e.hands[0].set(yourHand)
+	e.hands[1].set(opponentHand)
+	e.reevaluate()
+}
+
+// SetBoard はボードを設定します（0〜5枚）
+func (e *ShowdownEvaluator) SetBoard(board []poker.Card) {
+	e.boardSize = copy(e.board[:], board)
+	e.reevaluate()
+}
+
+// reevaluate はボードの1枚目から最良ランクを計算し直します
+func (e *ShowdownEvaluator) reevaluate() {
+	e.best[0][0], e.best[1][0] = worstHandRank, worstHandRank
+	for n := 1; n <= e.boardSize; n++ {
+		e.evaluateCard(n)
+	}
+}
+
+// Push はボードにカードを1枚追加し、そのカードを含む組み合わせのみを評価します
+func (e *ShowdownEvaluator) Push(card poker.Card) {
+	if e.boardSize == len(e.board) {
+		panic("board already has 5 cards")
+	}
+	e.board[e.boardSize] = card
+	e.boardSize++
+	e.evaluateCard(e.boardSize)
+}
+
+// Pop は最後にPushしたボードのカードを取り除きます
+func (e *ShowdownEvaluator) Pop() {
+	e.boardSize--
+}
+
+// Ranks は現在のボードでのヒーローと相手の最良ランクを返します（小さいほど強い）
+func (e *ShowdownEvaluator) Ranks() (int32, int32) {
+	return e.best[0][e.boardSize], e.best[1][e.boardSize]
+}
+
+// Judge は現在のボードで勝敗を判定します
+// 戻り値はVariant.Judgeと同様に "yourHand", "opponentHand", "tie" のいずれかです
+func (e *ShowdownEvaluator) Judge() string {
+	return compareRanks(e.Ranks())
+}
+
+// JudgeRunout は現在のボードにrunoutのカードを追加して勝敗を判定し、ボードを元に戻します
+func (e *ShowdownEvaluator) JudgeRunout(runout []poker.Card) string {
+	for _, card := range runout {
+		e.Push(card)
+	}
+	winner := e.Judge()
+	e.boardSize -= len(runout)
+	return winner
+}
+
+// EnumerateRunouts は残りデッキから不足しているボードカードを全通り配り、ランアウトごとに勝敗をfnに渡します
+func (e *ShowdownEvaluator) EnumerateRunouts(remainingDeck []poker.Card, fn func(winner string)) {
+	if e.boardSize == len(e.board) {
+		fn(e.Judge())
+		return
+	}
+	for i := range remainingDeck {
+		e.Push(remainingDeck[i])
+		e.EnumerateRunouts(remainingDeck[i+1:], fn)
+		e.Pop()
+	}
+}
+
+// evaluateCard はボードのn枚目のカードを含む組み合わせを評価し、best[p][n]を更新します
+func (e *ShowdownEvaluator) evaluateCard(n int) {
+	for p := range e.hands {
+		best := e.best[p][n-1]
+		var rank int32
+		if e.variant.IsOmaha() {
+			rank = e.evaluateOmahaCard(&e.hands[p], n)
+		} else {
+			rank = e.evaluateHoldemCard(&e.hands[p], n)
+		}
+		if rank < best {
+			best = rank
+		}
+		e.best[p][n] = best
+	}
+}
+
+// evaluateOmahaCard はハンドの2枚と、ボードのn枚目を含むボードの3枚の組み合わせの最良ランクを返します
+func (e *ShowdownEvaluator) evaluateOmahaCard(hand *evaluatorHand, n int) int32 {
+	best := worstHandRank
+	if n < 3 {
+		return best
+	}
+	e.scratch[4] = e.board[n-1]
+	for _, idx := range boardPairIndices[n-1] {
+		e.scratch[2] = e.board[idx[0]]
+		e.scratch[3] = e.board[idx[1]]
+		for _, pair := range hand.pairs[:hand.numPairs] {
+			e.scratch[0] = pair[0]
+			e.scratch[1] = pair[1]
+			if rank := poker.Evaluate(e.scratch[:]); rank < best {
+				best = rank
+			}
+		}
+	}
+	return best
+}
+
+// evaluateHoldemCard はハンドとボードのn枚のうち、ボードのn枚目を含む5枚の組み合わせの最良ランクを返します
+func (e *ShowdownEvaluator) evaluateHoldemCard(hand *evaluatorHand, n int) int32 {
+	best := worstHandRank
+
+	// 追加したカード以外（ハンドとボードのn-1枚）から4枚を選ぶ
+	var others [7]poker.Card
+	m := copy(others[:], hand.cards[:hand.size])
+	m += copy(others[m:], e.board[:n-1])
+	if m < 4 {
+		return best
+	}
+
+	e.scratch[4] = e.board[n-1]
+	for a := 0; a < m; a++ {
+		e.scratch[0] = others[a]
+		for b := a + 1; b < m; b++ {
+			e.scratch[1] = others[b]
+			for c := b + 1; c < m; c++ {
+				e.scratch[2] = others[c]
+				for d := c + 1; d < m; d++ {
+					e.scratch[3] = others[d]
+					if rank := poker.Evaluate(e.scratch[:]); rank < best {
+						best = rank
+					}
+				}
+			}
+		}
+	}
+	return best
+}
+
+// evaluateBestFive はcards（5〜7枚）から作れる5枚の組み合わせの最良ランクを割り当てなしで返します
+// poker.Evaluateは6枚・7枚の評価で毎回スライスを割り当てるため、5枚ずつ評価します
+func evaluateBestFive(cards []poker.Card) int32 {
+	if len(cards) <= 5 {
+		return poker.Evaluate(cards)
+	}
+
+	best := worstHandRank
+	var five [5]poker.Card
+	n := len(cards)
+	for a := 0; a < n; a++ {
+		five[0] = cards[a]
+		for b := a + 1; b < n; b++ {
+			five[1] = cards[b]
+			for c := b + 1; c < n; c++ {
+				five[2] = cards[c]
+				for d := c + 1; d < n; d++ {
+					five[3] = cards[d]
+					for e := d + 1; e < n; e++ {
+						five[4] = cards[e]
+						if rank := poker.Evaluate(five[:]); rank < best {
+							best = rank
+						}
+					}
+				}
+			}
+		}
+	}
+	return best
+}
@@ -0,0 +1,108 @@
+package poker
+
+import (
+	"testing"
+
+	"github.com/chehsunliu/poker"
+)
+
+// naiveEvaluate はルール通りに全ての5枚の組み合わせを評価する比較用の実装です
+func naiveEvaluate(variant Variant, hand []poker.Card, board []poker.Card) int32 {
+	if !variant.IsOmaha() {
+		return poker.Evaluate(append(append([]poker.Card{}, hand...), board...))
+	}
+	best := worstHandRank
+	for i := 0; i < len(hand); i++ {
+		for j := i + 1; j < len(hand); j++ {
+			for x := 0; x < len(board); x++ {
+				for y := x + 1; y < len(board); y++ {
+					for z := y + 1; z < len(board); z++ {
+						rank := poker.Evaluate([]poker.Card{hand[i], hand[j], board[x], board[y], board[z]})
+						if rank < best {
+							best = rank
+						}
+					}
+				}
+			}
+		}
+	}
+	return best
+}
+
+func TestShowdownEvaluator(t *testing.T) {
+	// テストケース1: ランダムなハンドとボードで、差分評価が全組み合わせの評価と一致する
+	t.Run("Matches naive evaluation", func(t *testing.T) {
+		rng := NewRand(1)
+		for _, variant := range Variants {
+			for trial := 0; trial < 200; trial++ {
+				deck := FullDeck()
+				rng.Shuffle(len(deck), func(i, j int) { deck[i], deck[j] = deck[j], deck[i] })
+				size := variant.HandSize()
+				yourHand, opponentHand, board := deck[:size], deck[size:2*size], deck[2*size:2*size+5]
+
+				evaluator := NewShowdownEvaluator(variant, yourHand, opponentHand)
+				evaluator.SetBoard(board[:3])
+				yourRank, opponentRank := evaluator.Ranks()
+				if yourRank != naiveEvaluate(variant, yourHand, board[:3]) || opponentRank != naiveEvaluate(variant, opponentHand, board[:3]) {
+					t.Fatalf("%s: flop ranks differ for %s vs %s on %s", variant, GenerateBoardString(yourHand), GenerateBoardString(opponentHand), GenerateBoardString(board[:3]))
+				}
+
+				want := compareRanks(naiveEvaluate(variant, yourHand, board), naiveEvaluate(variant, opponentHand, board))
+				if got := evaluator.JudgeRunout(board[3:]); got != want {
+					t.Fatalf("%s: expected %s, got %s for %s vs %s on %s", variant, want, got, GenerateBoardString(yourHand), GenerateBoardString(opponentHand), GenerateBoardString(board))
+				}
+				if got := variant.Judge(yourHand, opponentHand, board); got != want {
+					t.Fatalf("%s: Judge expected %s, got %s", variant, want, got)
+				}
+
+				// JudgeRunoutの後はフロップの状態に戻っている
+				if yourRank, _ := evaluator.Ranks(); yourRank != naiveEvaluate(variant, yourHand, board[:3]) {
+					t.Fatalf("%s: expected the board to be restored after JudgeRunout", variant)
+				}
+			}
+		}
+	})
+
+	// テストケース2: 全ランアウトの列挙がforEachRunoutとJudgeの結果と一致する
+	t.Run("Enumerates every runout", func(t *testing.T) {
+		yourHand := cards("As", "Ad", "Kh", "Kd")
+		opponentHand := cards("Qs", "Qd", "Jh", "Jd")
+		board := cards("2c", "7s", "Js")
+		remainingDeck := RemainingDeck(yourHand, opponentHand, board)
+
+		var want outcomeCounter
+		forEachRunout(board, remainingDeck, func(finalBoard []poker.Card) {
+			want.add(VariantPLO4.Judge(yourHand, opponentHand, finalBoard))
+		})
+
+		var got outcomeCounter
+		evaluator := NewShowdownEvaluator(VariantPLO4, yourHand, opponentHand)
+		evaluator.SetBoard(board)
+		evaluator.EnumerateRunouts(remainingDeck, got.add)
+
+		if got != want {
+			t.Errorf("Expected %+v, got %+v", want, got)
+		}
+	})
+
+	// テストケース3: 評価はメモリを割り当てない
+	t.Run("Does not allocate", func(t *testing.T) {
+		yourHand := cards("As", "Ad", "Kh", "Kd", "Qc")
+		opponentHand := cards("Qs", "Qd", "Jh", "Jd", "Tc")
+		board := cards("2c", "7s", "Js")
+		runout := cards("8h", "3d")
+		evaluator := NewShowdownEvaluator(VariantPLO5, yourHand, opponentHand)
+		evaluator.SetBoard(board)
+
+		if allocs := testing.AllocsPerRun(100, func() { evaluator.JudgeRunout(runout) }); allocs != 0 {
+			t.Errorf("Expected JudgeRunout not to allocate, got %.1f allocations", allocs)
+		}
+		finalBoard := cards("2c", "7s", "Js", "8h", "3d")
+		if allocs := testing.AllocsPerRun(100, func() { VariantPLO5.Judge(yourHand, opponentHand, finalBoard) }); allocs != 0 {
+			t.Errorf("Expected PLO Judge not to allocate, got %.1f allocations", allocs)
+		}
+		if allocs := testing.AllocsPerRun(100, func() { VariantHoldem.Judge(yourHand[:2], opponentHand[:2], finalBoard) }); allocs != 0 {
+			t.Errorf("Expected Hold'em Judge not to allocate, got %.1f allocations", allocs)
+		}
+	})
+}
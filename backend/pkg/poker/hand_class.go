@@ -0,0 +1,351 @@
+package poker
+
+import (
+	"fmt"
+
+	"github.com/chehsunliu/poker"
+)
+
+// HandClass はフロップでのオマハのハンドの分類です
+// 1つのハンドは下記の優先順位で最初に該当する1つのクラスに分類されます
+// （完成した強い役 → ドロー → ワンペア → 弱いストレートドロー → エア）
+type HandClass int
+
+const (
+	HandClassFullHouseOrBetter HandClass = iota // フルハウス以上
+	HandClassFlush                              // フラッシュ
+	HandClassStraight                           // ストレート
+	HandClassSet                                // セット（ポケットペア＋ボードの1枚）
+	HandClassTrips                              // トリップス（ハンドの1枚＋ペアボード）
+	HandClassTwoPair                            // ツーペア（ハンドの2枚がそれぞれボードの異なるランクとペア）
+	HandClassComboDraw                          // フラッシュドロー＋ストレートドロー
+	HandClassWrap                               // ラップ（ストレートのアウツが9枚以上）
+	HandClassFlushDraw                          // フラッシュドロー
+	HandClassOverpair                           // オーバーペア（ボードの最高ランクより上のポケットペア）
+	HandClassTopPair                            // トップペア
+	HandClassPair                               // その他のワンペア（セカンドペア以下・アンダーペア）
+	HandClassStraightDraw                       // ストレートドロー（アウツが4〜8枚: オープンエンド・ガットショット）
+	HandClassAir                                // 上記のいずれにも該当しない
+)
+
+// HandClasses は全ハンドクラスを優先順位の順に並べたものです
+var HandClasses = []HandClass{
+	HandClassFullHouseOrBetter, HandClassFlush, HandClassStraight, HandClassSet, HandClassTrips, HandClassTwoPair,
+	HandClassComboDraw, HandClassWrap, HandClassFlushDraw,
+	HandClassOverpair, HandClassTopPair, HandClassPair,
+	HandClassStraightDraw, HandClassAir,
+}
+
+// handClassNames はJSONやログに出力するハンドクラスの名前です
+var handClassNames = map[HandClass]string{
+	HandClassFullHouseOrBetter: "full_house_or_better",
+	HandClassFlush:             "flush",
+	HandClassStraight:          "straight",
+	HandClassSet:               "set",
+	HandClassTrips:             "trips",
+	HandClassTwoPair:           "two_pair",
+	HandClassComboDraw:         "combo_draw",
+	HandClassWrap:              "wrap",
+	HandClassFlushDraw:         "flush_draw",
+	HandClassOverpair:          "overpair",
+	HandClassTopPair:           "top_pair",
+	HandClassPair:              "pair",
+	HandClassStraightDraw:      "straight_draw",
+	HandClassAir:               "air",
+}
+
+// wrapMinOuts はラップとみなすストレートのアウツの最小枚数です
+const wrapMinOuts = 9
+
+// straightDrawMinOuts はストレートドローとみなすアウツの最小枚数です（ガットショット）
+const straightDrawMinOuts = 4
+
+// String はハンドクラスの名前を返します（"set", "flush_draw"など）
+func (c HandClass) String() string {
+	if name, ok := handClassNames[c]; ok {
+		return name
+	}
+	return fmt.Sprintf("HandClass(%d)", int(c))
+}
+
+// MarshalText はJSONにハンドクラスの名前を出力します
+func (c HandClass) MarshalText() ([]byte, error) {
+	if _, ok := handClassNames[c]; !ok {
+		return nil, fmt.Errorf("unknown hand class: %d", int(c))
+	}
+	return []byte(c.String()), nil
+}
+
+// UnmarshalText はハンドクラスの名前を解析します
+func (c *HandClass) UnmarshalText(text []byte) error {
+	for class, name := range handClassNames {
+		if name == string(text) {
+			*c = class
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown hand class: %s", text)
+}
+
+// ClassifyFlopHand はオマハ（PLO4/PLO5/PLO6）のハンドをフロップで分類します
+// 役はオマハのルール（ハンドから2枚、ボードから3枚）で判定し、ドローはターンで完成するアウツから判定します
+func ClassifyFlopHand(hand []poker.Card, flop []poker.Card) (HandClass, error) {
+	if len(flop) != 3 {
+		return 0, fmt.Errorf("flop must have 3 cards, got %d", len(flop))
+	}
+	variant, err := DetectVariant(hand)
+	if err != nil {
+		return 0, err
+	}
+	if !variant.IsOmaha() {
+		return 0, fmt.Errorf("hand classification is only supported for Omaha, got %s", variant)
+	}
+	if HasCardDuplicates(hand, flop) {
+		return 0, fmt.Errorf("duplicate cards detected")
+	}
+	return classifyFlopHand(hand, flop), nil
+}
+
+// classifyFlopHand はClassifyFlopHandの本体です（入力は検証済み）
+func classifyFlopHand(hand []poker.Card, flop []poker.Card) HandClass {
+	// 完成した役（ストレート以上）
+	switch poker.RankClass(evaluateOmahaHand(hand, flop)) {
+	case 1, 2, 3: // ストレートフラッシュ・フォーカード・フルハウス
+		return HandClassFullHouseOrBetter
+	case 4:
+		return HandClassFlush
+	case 5:
+		return HandClassStraight
+	}
+
+	// ボードのランクごとの枚数とハンドのランクごとの枚数
+	var boardRanks, handRanks [13]int
+	for _, card := range flop {
+		boardRanks[card.Rank()]++
+	}
+	for _, card := range hand {
+		handRanks[card.Rank()]++
+	}
+	topBoardRank := int32(-1)
+	for rank := int32(12); rank >= 0; rank-- {
+		if boardRanks[rank] > 0 {
+			topBoardRank = rank
+			break
+		}
+	}
+
+	// セット・トリップス・ツーペア・ワンペア
+	hasSet, hasTrips, hasOverpair, hasTopPair, hasPair := false, false, false, false, false
+	pairedBoardRanks := 0
+	for rank := int32(0); rank < 13; rank++ {
+		switch {
+		case boardRanks[rank] == 1 && handRanks[rank] >= 2:
+			hasSet = true
+		case boardRanks[rank] == 2 && handRanks[rank] >= 1:
+			hasTrips = true
+		}
+		if boardRanks[rank] == 1 && handRanks[rank] >= 1 {
+			pairedBoardRanks++
+			if rank == topBoardRank {
+				hasTopPair = true
+			} else {
+				hasPair = true
+			}
+		}
+		if boardRanks[rank] == 0 && handRanks[rank] >= 2 {
+			if rank > topBoardRank {
+				hasOverpair = true
+			} else {
+				hasPair = true
+			}
+		}
+	}
+	switch {
+	case hasSet:
+		return HandClassSet
+	case hasTrips:
+		return HandClassTrips
+	case pairedBoardRanks >= 2:
+		return HandClassTwoPair
+	}
+
+	// ドロー
+	flushDraw := hasFlushDraw(hand, flop)
+	straightOuts := countStraightOuts(hand, flop)
+	switch {
+	case flushDraw && straightOuts >= straightDrawMinOuts:
+		return HandClassComboDraw
+	case straightOuts >= wrapMinOuts:
+		return HandClassWrap
+	case flushDraw:
+		return HandClassFlushDraw
+	case hasOverpair:
+		return HandClassOverpair
+	case hasTopPair:
+		return HandClassTopPair
+	case hasPair:
+		return HandClassPair
+	case straightOuts >= straightDrawMinOuts:
+		return HandClassStraightDraw
+	}
+	return HandClassAir
+}
+
+// hasFlushDraw はボードに同じスートが2枚あり、ハンドにそのスートが2枚以上あるか（オマハのフラッシュドロー）を返します
+func hasFlushDraw(hand []poker.Card, flop []poker.Card) bool {
+	var boardSuits, handSuits [16]int
+	for _, card := range flop {
+		boardSuits[card.Suit()]++
+	}
+	for _, card := range hand {
+		handSuits[card.Suit()]++
+	}
+	for suit := range boardSuits {
+		if boardSuits[suit] == 2 && handSuits[suit] >= 2 {
+			return true
+		}
+	}
+	return false
+}
+
+// countStraightOuts はターンで落ちるとストレートが完成する残りデッキのカードの枚数を返します
+func countStraightOuts(hand []poker.Card, flop []poker.Card) int {
+	var board [4]int32
+	for i, card := range flop {
+		board[i] = card.Rank()
+	}
+
+	// ランクごとに判定し、デッキに残っている枚数を数える
+	var used [13]int
+	for _, card := range hand {
+		used[card.Rank()]++
+	}
+	for _, card := range flop {
+		used[card.Rank()]++
+	}
+
+	outs := 0
+	for rank := int32(0); rank < 13; rank++ {
+		board[3] = rank
+		if makesOmahaStraight(hand, board[:]) {
+			outs += 4 - used[rank]
+		}
+	}
+	return outs
+}
+
+// makesOmahaStraight はハンドの2枚とボードの3枚でストレートになる組み合わせがあるかをランクのみで判定します
+func makesOmahaStraight(hand []poker.Card, boardRanks []int32) bool {
+	for i := 0; i < len(hand); i++ {
+		for j := i + 1; j < len(hand); j++ {
+			for x := 0; x < len(boardRanks); x++ {
+				for y := x + 1; y < len(boardRanks); y++ {
+					for z := y + 1; z < len(boardRanks); z++ {
+						if isStraightRanks(hand[i].Rank(), hand[j].Rank(), boardRanks[x], boardRanks[y], boardRanks[z]) {
+							return true
+						}
+					}
+				}
+			}
+		}
+	}
+	return false
+}
+
+// isStraightRanks は5枚のランクがストレート（A-2-3-4-5を含む）かを返します
+func isStraightRanks(ranks ...int32) bool {
+	var bits int32
+	for _, rank := range ranks {
+		if bits&(1<<rank) != 0 {
+			return false
+		}
+		bits |= 1 << rank
+	}
+	// A-2-3-4-5（Aはランク12）
+	if bits == 1<<12|0xF {
+		return true
+	}
+	for bits&1 == 0 {
+		bits >>= 1
+	}
+	return bits == 0x1F
+}
+
+// HandClassBreakdown はハンドクラスごとのレンジに占める割合と、そのクラスに対するヒーローのエクイティです
+type HandClassBreakdown struct {
+	Class     HandClass `json:"class"`
+	Combos    int       `json:"combos"`    // クラスに含まれるハンド数
+	Frequency float64   `json:"frequency"` // レンジ全体に占める割合（%、頻度で重み付け）
+	Equity    float64   `json:"equity"`    // このクラスのハンドに対するヒーローのエクイティ（%、頻度で重み付けした平均）
+	Samples   int       `json:"samples"`   // エクイティの計算に使用したハンド数（サンプリングした場合はCombosより少なくなる）
+}
+
+// CalculateHandClassBreakdown は相手のレンジをフロップでハンドクラスに分類し、クラスごとの割合とヒーローのエクイティを集計します
+// equitiesはCalculateHandVsRangeEquityParallelなどが返すハンド文字列ごとのヒーローのエクイティです
+// ヒーローのハンドやボードとカードが重複するハンド、頻度0のハンドは除外し、該当するハンドがないクラスは結果に含めません
+// equitiesに含まれないハンド（サンプリングで計算されなかったハンド）は割合にのみ反映されます
+func CalculateHandClassBreakdown(yourHand []poker.Card, opponentRange WeightedRange, flop []poker.Card, equities map[string]float64) ([]HandClassBreakdown, error) {
+	if len(flop) != 3 {
+		return nil, fmt.Errorf("flop must have 3 cards, got %d", len(flop))
+	}
+	variant, err := detectRangeVariant(yourHand, opponentRange)
+	if err != nil {
+		return nil, err
+	}
+	if !variant.IsOmaha() {
+		return nil, fmt.Errorf("hand classification is only supported for Omaha, got %s", variant)
+	}
+
+	type classTotals struct {
+		combos         int
+		weight         float64
+		equityWeight   float64
+		weightedEquity float64
+		samples        int
+	}
+	totals := make(map[HandClass]*classTotals)
+	var totalWeight float64
+
+	for _, opponentHand := range opponentRange {
+		if opponentHand.Weight <= 0 || HasCardDuplicates(yourHand, opponentHand.Cards, flop) {
+			continue
+		}
+		class := classifyFlopHand(opponentHand.Cards, flop)
+		t, ok := totals[class]
+		if !ok {
+			t = &classTotals{}
+			totals[class] = t
+		}
+		t.combos++
+		t.weight += opponentHand.Weight
+		totalWeight += opponentHand.Weight
+
+		if equity, ok := equities[GenerateBoardString(opponentHand.Cards)]; ok {
+			t.equityWeight += opponentHand.Weight
+			t.weightedEquity += equity * opponentHand.Weight
+			t.samples++
+		}
+	}
+	if totalWeight == 0 {
+		return nil, fmt.Errorf("no valid hands in opponent range")
+	}
+
+	var breakdown []HandClassBreakdown
+	for _, class := range HandClasses {
+		t, ok := totals[class]
+		if !ok {
+			continue
+		}
+		entry := HandClassBreakdown{
+			Class:     class,
+			Combos:    t.combos,
+			Frequency: t.weight / totalWeight * 100,
+			Samples:   t.samples,
+		}
+		if t.equityWeight > 0 {
+			entry.Equity = t.weightedEquity / t.equityWeight
+		}
+		breakdown = append(breakdown, entry)
+	}
+	return breakdown, nil
+}
@@ -0,0 +1,110 @@
+package poker
+
+import (
+	"encoding/json"
+	"math"
+	"testing"
+)
+
+func TestClassifyFlopHand(t *testing.T) {
+	tests := []struct {
+		name string
+		hand string
+		flop string
+		want HandClass
+	}{
+		{"Full house", "9s9dAhQc", "9cKdKs", HandClassFullHouseOrBetter},
+		{"Flush", "AsKs7d4c", "Qs8s2s", HandClassFlush},
+		{"Straight", "JhTd4c3c", "9s8d7h", HandClassStraight},
+		{"Set", "9s9dKhQc", "9c5h2d", HandClassSet},
+		{"Trips", "9sKdQh4c", "9c9h2d", HandClassTrips},
+		{"Two pair", "Ks9dQh4c", "Kc9h2d", HandClassTwoPair},
+		{"Combo draw", "JsTs8h7c", "9s6s2c", HandClassComboDraw},
+		{"Wrap", "JsTd8h7c", "9s6d2c", HandClassWrap},
+		{"Flush draw", "AsKs7d4c", "Qs8s2h", HandClassFlushDraw},
+		{"Overpair", "AsAdKhQc", "Ts7d2c", HandClassOverpair},
+		{"Top pair", "Ts9d4h3c", "Th6s2c", HandClassTopPair},
+		{"Second pair", "7s7dKhQc", "Th6s2c", HandClassPair},
+		{"Straight draw", "AhKd4c3c", "9s7s6d", HandClassStraightDraw},
+		{"Air", "AhKd4c3c", "Ts9s8d", HandClassAir},
+		{"PLO5 set", "9s9dKhQc3h", "9c5h2d", HandClassSet},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hand, err := ParseHand(tt.hand)
+			if err != nil {
+				t.Fatalf("Invalid hand: %v", err)
+			}
+			flop, err := ParseBoard(tt.flop)
+			if err != nil {
+				t.Fatalf("Invalid flop: %v", err)
+			}
+
+			got, err := ClassifyFlopHand(hand, flop)
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Expected %s, got %s", tt.want, got)
+			}
+		})
+	}
+
+	t.Run("Rejects Hold'em and non-flop boards", func(t *testing.T) {
+		if _, err := ClassifyFlopHand(cards("As", "Ad"), cards("Ts", "7d", "2c")); err == nil {
+			t.Errorf("Expected an error for a Hold'em hand")
+		}
+		if _, err := ClassifyFlopHand(cards("As", "Ad", "Kh", "Qc"), cards("Ts", "7d", "2c", "3h")); err == nil {
+			t.Errorf("Expected an error for a turn board")
+		}
+	})
+}
+
+func TestCalculateHandClassBreakdown(t *testing.T) {
+	yourHand := cards("As", "Ad", "Kh", "Qc")
+	flop := cards("9c", "5h", "2d")
+	opponentRange := WeightedRange{
+		{Cards: cards("9s", "9d", "Kd", "Qd"), Weight: 1.0}, // set
+		{Cards: cards("5s", "5d", "Kc", "Qh"), Weight: 0.5}, // set
+		{Cards: cards("Ks", "Jh", "8c", "4c"), Weight: 1.0}, // air
+		{Cards: cards("Qc", "Jd", "8h", "7h"), Weight: 1.0}, // ヒーローとカードが重複するため除外
+		{Cards: cards("Js", "Jd", "4h", "3c"), Weight: 0.5}, // overpair（エクイティ未計算）
+	}
+	equities := map[string]float64{
+		"9s9dKdQd": 10,
+		"5s5dKcQh": 16,
+		"KsJh8c4c": 80,
+	}
+
+	breakdown, err := CalculateHandClassBreakdown(yourHand, opponentRange, flop, equities)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	want := []HandClassBreakdown{
+		{Class: HandClassSet, Combos: 2, Frequency: 50, Equity: 12, Samples: 2},
+		{Class: HandClassOverpair, Combos: 1, Frequency: 50.0 / 3, Equity: 0, Samples: 0},
+		{Class: HandClassAir, Combos: 1, Frequency: 100.0 / 3, Equity: 80, Samples: 1},
+	}
+	if len(breakdown) != len(want) {
+		t.Fatalf("Expected %d classes, got %+v", len(want), breakdown)
+	}
+	for i := range want {
+		got := breakdown[i]
+		if got.Class != want[i].Class || got.Combos != want[i].Combos || got.Samples != want[i].Samples ||
+			math.Abs(got.Frequency-want[i].Frequency) > 1e-9 || math.Abs(got.Equity-want[i].Equity) > 1e-9 {
+			t.Errorf("Class %d: expected %+v, got %+v", i, want[i], got)
+		}
+	}
+
+	// JSONにはクラス名が出力される
+	data, err := json.Marshal(breakdown[0])
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	var decoded HandClassBreakdown
+	if err := json.Unmarshal(data, &decoded); err != nil || decoded.Class != HandClassSet {
+		t.Errorf("Expected round trip of %s, got %s (%v)", data, decoded.Class, err)
+	}
+}
@@ -0,0 +1,340 @@
+package poker
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/chehsunliu/poker"
+)
+
+// HandFilter はハンドの条件式（ProPokerToolsの汎用構文に近い表記）です
+//
+//	AA**, AAxx      ランクのパターン（*とxは任意のカード。パターンより多いカードは任意として扱う）
+//	AsKs**          スートを指定したカード
+//	$ds, $ss, $r    ダブルスーテッド・シングルスーテッド・レインボー（2枚以上あるスートの数が2以上・1・0）
+//	$p, $np         ペアあり・ペアなし
+//	$rd             ランダウン（異なる4ランクが連続する5ランクの範囲に収まる。Aはローとしても扱う）
+//	!               否定
+//	: または &      かつ
+//	, または |      または（優先順位は ! > かつ > または、括弧でまとめられます）
+//
+// 例: "AA**:$ds"（AAのダブルスーテッド）、"$rd:!$p"（ペアのないランダウン）、"!(AA,KK)"
+type HandFilter struct {
+	expr string
+	root filterNode
+}
+
+// filterNode は条件式の構文木のノードです
+type filterNode interface {
+	match(hand []poker.Card) bool
+}
+
+// ParseHandFilter は条件式を解析します
+func ParseHandFilter(expr string) (*HandFilter, error) {
+	p := &filterParser{input: expr}
+	root, err := p.parseOr()
+	if err != nil {
+		return nil, fmt.Errorf("invalid hand filter %q: %v", expr, err)
+	}
+	p.skipSpaces()
+	if p.pos < len(p.input) {
+		return nil, fmt.Errorf("invalid hand filter %q: unexpected %q at position %d", expr, p.input[p.pos:], p.pos)
+	}
+	return &HandFilter{expr: expr, root: root}, nil
+}
+
+// String は条件式を返します
+func (f *HandFilter) String() string {
+	return f.expr
+}
+
+// Match はハンドが条件を満たすかを返します
+func (f *HandFilter) Match(hand []poker.Card) bool {
+	return f.root.match(hand)
+}
+
+// FilterRange はレンジから条件を満たすハンドだけを頻度を保ったまま取り出します
+// 結果は通常のレンジと同じく、すべてのエクイティ計算に使用できます
+func FilterRange(weightedRange WeightedRange, filter *HandFilter) WeightedRange {
+	var filtered WeightedRange
+	for _, hand := range weightedRange {
+		if filter.Match(hand.Cards) {
+			filtered = append(filtered, hand)
+		}
+	}
+	return filtered
+}
+
+// filterParser は条件式の再帰下降パーサーです
+type filterParser struct {
+	input string
+	pos   int
+}
+
+func (p *filterParser) skipSpaces() {
+	for p.pos < len(p.input) && (p.input[p.pos] == ' ' || p.input[p.pos] == '\t') {
+		p.pos++
+	}
+}
+
+// consume は次の文字がopsのいずれかであれば読み進めます
+func (p *filterParser) consume(ops string) bool {
+	p.skipSpaces()
+	if p.pos < len(p.input) && strings.IndexByte(ops, p.input[p.pos]) >= 0 {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+// parseOr は "a , b | c" を解析します
+func (p *filterParser) parseOr() (filterNode, error) {
+	node, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	children := []filterNode{node}
+	for p.consume(",|") {
+		node, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		children = append(children, node)
+	}
+	if len(children) == 1 {
+		return children[0], nil
+	}
+	return orNode(children), nil
+}
+
+// parseAnd は "a : b & c" を解析します
+func (p *filterParser) parseAnd() (filterNode, error) {
+	node, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	children := []filterNode{node}
+	for p.consume(":&") {
+		node, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		children = append(children, node)
+	}
+	if len(children) == 1 {
+		return children[0], nil
+	}
+	return andNode(children), nil
+}
+
+// parseUnary は否定・括弧・マクロ・パターンを解析します
+func (p *filterParser) parseUnary() (filterNode, error) {
+	if p.consume("!") {
+		node, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return notNode{node}, nil
+	}
+	if p.consume("(") {
+		node, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if !p.consume(")") {
+			return nil, fmt.Errorf("missing ')' at position %d", p.pos)
+		}
+		return node, nil
+	}
+	if p.consume("$") {
+		return p.parseMacro()
+	}
+	return p.parsePattern()
+}
+
+// filterMacros はマクロの名前と判定関数です
+var filterMacros = map[string]func(hand []poker.Card) bool{
+	"ds":      func(hand []poker.Card) bool { return suitedSuitCount(hand) >= 2 },
+	"ss":      func(hand []poker.Card) bool { return suitedSuitCount(hand) == 1 },
+	"r":       func(hand []poker.Card) bool { return suitedSuitCount(hand) == 0 },
+	"p":       func(hand []poker.Card) bool { return hasPairedRank(hand) },
+	"np":      func(hand []poker.Card) bool { return !hasPairedRank(hand) },
+	"rd":      isRundown,
+	"rundown": isRundown,
+}
+
+// parseMacro は "$ds" などの "$" に続く名前を解析します
+func (p *filterParser) parseMacro() (filterNode, error) {
+	start := p.pos
+	for p.pos < len(p.input) && isFilterLetter(p.input[p.pos]) {
+		p.pos++
+	}
+	name := strings.ToLower(p.input[start:p.pos])
+	fn, ok := filterMacros[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown macro $%s", name)
+	}
+	return macroNode(fn), nil
+}
+
+func isFilterLetter(c byte) bool {
+	return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+// parsePattern は "AA**", "AsKs", "AAxx" を解析します
+func (p *filterParser) parsePattern() (filterNode, error) {
+	p.skipSpaces()
+	var pattern patternNode
+	for p.pos < len(p.input) {
+		c := p.input[p.pos]
+		if c == '*' || c == 'x' || c == 'X' {
+			pattern = append(pattern, patternElem{rank: -1})
+			p.pos++
+			continue
+		}
+		rank := strings.IndexByte(holdemRanks, strings.ToUpper(string(c))[0])
+		if rank < 0 {
+			break
+		}
+		elem := patternElem{rank: int32(rank)}
+		p.pos++
+		// ランクの後ろにスートがあればスートを指定したカード
+		if p.pos < len(p.input) && strings.IndexByte("shdcSHDC", p.input[p.pos]) >= 0 {
+			card := poker.NewCard(string(holdemRanks[rank]) + strings.ToLower(p.input[p.pos:p.pos+1]))
+			elem.suit = card.Suit()
+			p.pos++
+		}
+		pattern = append(pattern, elem)
+	}
+	if len(pattern) == 0 {
+		if p.pos < len(p.input) {
+			return nil, fmt.Errorf("unexpected %q at position %d", p.input[p.pos:], p.pos)
+		}
+		return nil, fmt.Errorf("unexpected end of filter")
+	}
+	return pattern, nil
+}
+
+type orNode []filterNode
+
+func (n orNode) match(hand []poker.Card) bool {
+	for _, child := range n {
+		if child.match(hand) {
+			return true
+		}
+	}
+	return false
+}
+
+type andNode []filterNode
+
+func (n andNode) match(hand []poker.Card) bool {
+	for _, child := range n {
+		if !child.match(hand) {
+			return false
+		}
+	}
+	return true
+}
+
+type notNode struct {
+	child filterNode
+}
+
+func (n notNode) match(hand []poker.Card) bool {
+	return !n.child.match(hand)
+}
+
+type macroNode func(hand []poker.Card) bool
+
+func (n macroNode) match(hand []poker.Card) bool {
+	return n(hand)
+}
+
+// patternElem はパターンの1枚です（rankが-1の場合は任意のランク、suitが0の場合は任意のスート）
+type patternElem struct {
+	rank int32
+	suit int32
+}
+
+func (e patternElem) matches(card poker.Card) bool {
+	return (e.rank < 0 || card.Rank() == e.rank) && (e.suit == 0 || card.Suit() == e.suit)
+}
+
+// patternNode はハンドの異なるカードをパターンの各要素に割り当てられるかで判定します
+type patternNode []patternElem
+
+func (n patternNode) match(hand []poker.Card) bool {
+	if len(n) > len(hand) {
+		return false
+	}
+	var used [8]bool
+	var assign func(i int) bool
+	assign = func(i int) bool {
+		if i == len(n) {
+			return true
+		}
+		for j, card := range hand {
+			if !used[j] && n[i].matches(card) {
+				used[j] = true
+				if assign(i + 1) {
+					return true
+				}
+				used[j] = false
+			}
+		}
+		return false
+	}
+	return assign(0)
+}
+
+// suitedSuitCount は2枚以上あるスートの数を返します
+func suitedSuitCount(hand []poker.Card) int {
+	var suits [16]int
+	for _, card := range hand {
+		suits[card.Suit()]++
+	}
+	count := 0
+	for _, n := range suits {
+		if n >= 2 {
+			count++
+		}
+	}
+	return count
+}
+
+// hasPairedRank は同じランクのカードが2枚以上あるかを返します
+func hasPairedRank(hand []poker.Card) bool {
+	var ranks [13]int
+	for _, card := range hand {
+		ranks[card.Rank()]++
+		if ranks[card.Rank()] >= 2 {
+			return true
+		}
+	}
+	return false
+}
+
+// isRundown は異なる4ランクが連続する5ランクの範囲に収まるかを返します（Aはローとしても扱う）
+func isRundown(hand []poker.Card) bool {
+	// ビット0をローのA、ビット1〜13を2〜Aとする
+	var bits int
+	for _, card := range hand {
+		bits |= 1 << (card.Rank() + 1)
+		if card.Rank() == 12 {
+			bits |= 1
+		}
+	}
+	for low := 0; low <= 9; low++ {
+		count := 0
+		for rank := low; rank < low+5; rank++ {
+			if bits&(1<<rank) != 0 {
+				count++
+			}
+		}
+		if count >= 4 {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,84 @@
+package poker
+
+import (
+	"testing"
+)
+
+func TestHandFilter(t *testing.T) {
+	// テストケース1: パターン・マクロ・論理演算
+	t.Run("Match", func(t *testing.T) {
+		testCases := []struct {
+			expr     string
+			hand     []string
+			expected bool
+		}{
+			{"AA**", []string{"As", "Ad", "Kh", "Qc"}, true},
+			{"AAxx", []string{"As", "Kd", "Kh", "Qc"}, false},
+			{"AA", []string{"As", "Ad", "Ah", "2c", "3d"}, true}, // パターンより多いカードは任意
+			{"AsKs", []string{"Kd", "As", "Ks", "2c"}, true},
+			{"AsKs", []string{"Ah", "Kh", "As", "Kd"}, false},
+			{"$ds", []string{"As", "Ks", "Qh", "Jh"}, true},
+			{"$ds", []string{"As", "Ks", "Qs", "Jh"}, false},
+			{"$ss", []string{"As", "Ks", "Qs", "Jh"}, true},
+			{"$r", []string{"As", "Kh", "Qd", "Jc"}, true},
+			{"$p", []string{"9s", "9h", "8d", "7c"}, true},
+			{"$np", []string{"9s", "9h", "8d", "7c"}, false},
+			{"$rd", []string{"9s", "8h", "7d", "6c"}, true},
+			{"$rd", []string{"9s", "8h", "7d", "5c"}, true},  // 1ギャップのランダウン
+			{"$rd", []string{"9s", "8h", "6d", "4c"}, false}, // 5ランクの範囲に収まらない
+			{"$rd", []string{"As", "2h", "3d", "4c"}, true},  // Aはローとしても扱う
+			{"$rd:$ds", []string{"Ts", "9s", "8h", "7h"}, true},
+			{"$rd:$ds", []string{"Ts", "9s", "8h", "7d"}, false},
+			{"AA**, KK**", []string{"Ks", "Kd", "7h", "2c"}, true},
+			{"!(AA, KK) & $ds", []string{"Ks", "Kd", "7s", "2d"}, false},
+			{"!(AA, KK) & $ds", []string{"Qs", "Qd", "7s", "2d"}, true},
+			{"AA:$ss | $rd", []string{"9s", "8h", "7d", "6c"}, true}, // かつはまたはより優先
+		}
+
+		for _, tc := range testCases {
+			filter, err := ParseHandFilter(tc.expr)
+			if err != nil {
+				t.Fatalf("%s: expected no error, got %v", tc.expr, err)
+			}
+			if got := filter.Match(cards(tc.hand...)); got != tc.expected {
+				t.Errorf("%s on %v: expected %v, got %v", tc.expr, tc.hand, tc.expected, got)
+			}
+		}
+	})
+
+	// テストケース2: 不正な条件式はエラー
+	t.Run("Invalid filter", func(t *testing.T) {
+		for _, expr := range []string{"", "AA**:", "(AA", "AA)", "$foo", "AZ", "!!"} {
+			if _, err := ParseHandFilter(expr); err == nil {
+				t.Errorf("%q: expected error, got nil", expr)
+			}
+		}
+	})
+}
+
+func TestFilterRange(t *testing.T) {
+	opponentRange := WeightedRange{
+		{Cards: cards("As", "Ad", "Kh", "Qc"), Weight: 1.0},
+		{Cards: cards("Ah", "Ac", "9h", "8c"), Weight: 0.5},
+		{Cards: cards("Ts", "9s", "8h", "7h"), Weight: 1.0},
+		{Cards: cards("Ks", "Kd", "7s", "2d"), Weight: 1.0},
+	}
+	filter, err := ParseHandFilter("AA**:$ds")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	filtered := FilterRange(opponentRange, filter)
+	if len(filtered) != 1 || filtered[0].Weight != 0.5 {
+		t.Fatalf("Expected only the double-suited aces with weight 0.5, got %+v", filtered)
+	}
+
+	// 取り出したレンジはそのままエクイティ計算に使用できる
+	_, result, err := CalculateHandVsWeightedRangeEquityParallel(cards("Ks", "Qs", "Jd", "Td"), filtered, cards("2c", "7d", "Jh"))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if result.Samples == 0 || result.Equity <= 0 || result.Equity >= 100 {
+		t.Errorf("Expected an equity against the sub-range, got %+v", result)
+	}
+}
@@ -0,0 +1,247 @@
+package poker
+
+import (
+	"fmt"
+	"math/rand"
+	"sort"
+
+	"github.com/chehsunliu/poker"
+)
+
+// HiLoShowdown はハイロースプリット（PLO8・Big O）のショーダウン結果を表します
+type HiLoShowdown struct {
+	HighWinner string  // "yourHand", "opponentHand", "tie"
+	LowWinner  string  // "yourHand", "opponentHand", "tie", "none"（8-or-betterのローが成立しない場合）
+	YourShare  float64 // yourHandが獲得するポットの割合（0〜1）
+}
+
+// HiLoEquity はハイロースプリットのエクイティ内訳を表します（すべて%）
+type HiLoEquity struct {
+	Equity   float64 // 獲得ポットの期待値
+	Scoop    float64 // ポット全体を単独で獲得する割合
+	HighOnly float64 // ハイ側のみを獲得する割合（ローが成立し、ロー側は獲得できない）
+	LowOnly  float64 // ロー側のみを獲得する割合（ハイ側は獲得できない）
+	Split    float64 // 上記以外でポットの一部を獲得する割合（クォーター、チョップなど）
+}
+
+// lowRankValues はカードのランク（poker.Card.Rank()）からローの値への対応表です
+// A=1, 2〜8はそのまま、9以上はローに使えない（0）
+var lowRankValues = [13]int32{2, 3, 4, 5, 6, 7, 8, 0, 0, 0, 0, 0, 1}
+
+// noLowRank はローが成立しないことを表すランクです
+const noLowRank int32 = -1
+
+// JudgeWinnerHiLo はPLO8・Big O（5-card hi-lo）のハイロースプリットの勝敗を判定します
+// ハイ・ローともにハンドから2枚、ボードから3枚を使用し、ローは8-or-betterのみ成立します
+// ハイ・ローそれぞれのポット半分を分け合う場合は、さらに等分（クォーター）します
+// 2つのハンドが同じ枚数のオマハのハンドでない場合はエラーを返します
+func JudgeWinnerHiLo(yourHand []poker.Card, opponentHand []poker.Card, board []poker.Card) (HiLoShowdown, error) {
+	if _, err := detectHiLoVariant(yourHand, opponentHand); err != nil {
+		return HiLoShowdown{}, err
+	}
+	return judgeHiLo(yourHand, opponentHand, board), nil
+}
+
+// judgeHiLo は検証済みのハンドでハイロースプリットの勝敗を判定します
+func judgeHiLo(yourHand []poker.Card, opponentHand []poker.Card, board []poker.Card) HiLoShowdown {
+	showdown := HiLoShowdown{
+		HighWinner: compareRanks(evaluateOmahaHand(yourHand, board), evaluateOmahaHand(opponentHand, board)),
+		LowWinner:  "none",
+	}
+
+	yourLow := evaluateLowHand(yourHand, board)
+	opponentLow := evaluateLowHand(opponentHand, board)
+	if yourLow != noLowRank || opponentLow != noLowRank {
+		switch {
+		case opponentLow == noLowRank || (yourLow != noLowRank && yourLow < opponentLow):
+			showdown.LowWinner = "yourHand"
+		case yourLow == noLowRank || opponentLow < yourLow:
+			showdown.LowWinner = "opponentHand"
+		default:
+			showdown.LowWinner = "tie"
+		}
+	}
+
+	if showdown.LowWinner == "none" {
+		// ローが成立しない場合はハイがポット全体を獲得する
+		showdown.YourShare = potShare(showdown.HighWinner)
+	} else {
+		showdown.YourShare = potShare(showdown.HighWinner)/2 + potShare(showdown.LowWinner)/2
+	}
+	return showdown
+}
+
+// potShare は勝者の判定結果からyourHandの獲得割合を返します
+func potShare(winner string) float64 {
+	switch winner {
+	case "yourHand":
+		return 1
+	case "tie":
+		return 0.5
+	default:
+		return 0
+	}
+}
+
+// evaluateLowHand は8-or-betterのローを評価します（小さいほど強い）
+// ハンドから2枚、ボードから3枚を使用し、5枚とも異なる8以下のランクである必要があります
+// ストレート・フラッシュはローの評価に影響しません。成立しない場合はnoLowRankを返します
+func evaluateLowHand(hand []poker.Card, board []poker.Card) int32 {
+	bestLow := noLowRank
+
+	for i := 0; i < len(hand); i++ {
+		for j := i + 1; j < len(hand); j++ {
+			for x := 0; x < len(board); x++ {
+				for y := x + 1; y < len(board); y++ {
+					for z := y + 1; z < len(board); z++ {
+						low := lowValue(hand[i], hand[j], board[x], board[y], board[z])
+						if low != noLowRank && (bestLow == noLowRank || low < bestLow) {
+							bestLow = low
+						}
+					}
+				}
+			}
+		}
+	}
+
+	return bestLow
+}
+
+// lowValue は5枚のカードのローの値を返します
+// 高いカードから順に比較できるよう、降順に並べたランクを9進数として表現します
+func lowValue(cards ...poker.Card) int32 {
+	var ranks [5]int32
+	var seen int32
+	for i, card := range cards {
+		rank := lowRankValues[card.Rank()]
+		if rank == 0 || seen&(1<<rank) != 0 {
+			return noLowRank
+		}
+		seen |= 1 << rank
+		ranks[i] = rank
+	}
+
+	sort.Slice(ranks[:], func(i, j int) bool { return ranks[i] > ranks[j] })
+
+	var value int32
+	for _, rank := range ranks {
+		value = value*9 + rank
+	}
+	return value
+}
+
+// hiLoEquityAccumulator はランアウトごとのショーダウン結果を集計します
+type hiLoEquityAccumulator struct {
+	share, scoop, highOnly, lowOnly, split, total float64
+}
+
+func (a *hiLoEquityAccumulator) add(showdown HiLoShowdown) {
+	a.total++
+	a.share += showdown.YourShare
+
+	highShare := potShare(showdown.HighWinner)
+	lowShare := potShare(showdown.LowWinner)
+	switch {
+	case showdown.YourShare == 1:
+		a.scoop++
+	case showdown.LowWinner != "none" && highShare > 0 && lowShare == 0:
+		a.highOnly++
+	case showdown.LowWinner != "none" && lowShare > 0 && highShare == 0:
+		a.lowOnly++
+	case showdown.YourShare > 0:
+		a.split++
+	}
+}
+
+func (a *hiLoEquityAccumulator) result() HiLoEquity {
+	return HiLoEquity{
+		Equity:   a.share / a.total * 100,
+		Scoop:    a.scoop / a.total * 100,
+		HighOnly: a.highOnly / a.total * 100,
+		LowOnly:  a.lowOnly / a.total * 100,
+		Split:    a.split / a.total * 100,
+	}
+}
+
+// detectHiLoVariant は2つのハンドのゲームタイプを判定し、ハイローを計算できるオマハであることを確認します
+func detectHiLoVariant(yourHand []poker.Card, opponentHand []poker.Card) (Variant, error) {
+	variant, err := DetectVariant(yourHand, opponentHand)
+	if err != nil {
+		return 0, err
+	}
+	if !variant.IsOmaha() {
+		return 0, fmt.Errorf("hi-lo requires Omaha hands, got %s", variant)
+	}
+	return variant, nil
+}
+
+// validateHiLoHands はハイローのエクイティ計算の入力を検証します
+func validateHiLoHands(yourHand []poker.Card, opponentHand []poker.Card, board []poker.Card, deadCards []poker.Card) error {
+	if _, err := detectHiLoVariant(yourHand, opponentHand); err != nil {
+		return err
+	}
+	if HasCardDuplicates(yourHand, opponentHand, board, deadCards) {
+		return fmt.Errorf("duplicate cards detected")
+	}
+	return ValidateBoardSize(board)
+}
+
+// CalculateHandVsHandHiLoEquity はPLO8・Big Oのエクイティを全数計算し、スクープ・ハイのみ・ローのみの内訳を返します
+func CalculateHandVsHandHiLoEquity(yourHand []poker.Card, opponentHand []poker.Card, board []poker.Card) (HiLoEquity, error) {
+	return CalculateHandVsHandHiLoEquityWithDeadCards(yourHand, opponentHand, board, nil)
+}
+
+// CalculateHandVsHandHiLoEquityWithDeadCards はデッドカードを考慮したCalculateHandVsHandHiLoEquityです
+// デッドカード（フォールドしたハンドや見えたカード）はデッキから除かれ、ボードに配られません
+func CalculateHandVsHandHiLoEquityWithDeadCards(yourHand []poker.Card, opponentHand []poker.Card, board []poker.Card, deadCards []poker.Card) (HiLoEquity, error) {
+	if err := validateHiLoHands(yourHand, opponentHand, board, deadCards); err != nil {
+		return HiLoEquity{}, err
+	}
+
+	remainingDeck := RemainingDeck(yourHand, opponentHand, board, deadCards)
+	if len(remainingDeck) < CardsToComplete(board) {
+		return HiLoEquity{}, fmt.Errorf("insufficient remaining cards")
+	}
+
+	var accumulator hiLoEquityAccumulator
+	forEachRunout(board, remainingDeck, func(finalBoard []poker.Card) {
+		accumulator.add(judgeHiLo(yourHand, opponentHand, finalBoard))
+	})
+
+	return accumulator.result(), nil
+}
+
+// CalculateHandVsHandHiLoEquityMonteCarlo はPLO8・Big Oのエクイティをモンテカルロシミュレーションで計算します
+// rngに同じシードの乱数生成器を渡すと同じ結果を返します（nilの場合は現在時刻をシードとします）
+func CalculateHandVsHandHiLoEquityMonteCarlo(yourHand []poker.Card, opponentHand []poker.Card, board []poker.Card, iterations int, rng *rand.Rand) (HiLoEquity, error) {
+	return CalculateHandVsHandHiLoEquityMonteCarloWithDeadCards(yourHand, opponentHand, board, nil, iterations, rng)
+}
+
+// CalculateHandVsHandHiLoEquityMonteCarloWithDeadCards はデッドカードを考慮したCalculateHandVsHandHiLoEquityMonteCarloです
+func CalculateHandVsHandHiLoEquityMonteCarloWithDeadCards(yourHand []poker.Card, opponentHand []poker.Card, board []poker.Card, deadCards []poker.Card, iterations int, rng *rand.Rand) (HiLoEquity, error) {
+	if err := validateHiLoHands(yourHand, opponentHand, board, deadCards); err != nil {
+		return HiLoEquity{}, err
+	}
+	if iterations <= 0 {
+		return HiLoEquity{}, fmt.Errorf("iterations must be positive, got %d", iterations)
+	}
+
+	remainingDeck := RemainingDeck(yourHand, opponentHand, board, deadCards)
+	if len(remainingDeck) < CardsToComplete(board) {
+		return HiLoEquity{}, fmt.Errorf("insufficient remaining cards")
+	}
+
+	// 乱数生成器を初期化（nilの場合は現在時刻をシードとする）
+	rng = randOrDefault(rng)
+
+	finalBoard := make([]poker.Card, len(board)+CardsToComplete(board))
+	copy(finalBoard, board)
+
+	var accumulator hiLoEquityAccumulator
+	for i := 0; i < iterations; i++ {
+		dealRandomRunout(rng, len(board), remainingDeck, finalBoard)
+		accumulator.add(judgeHiLo(yourHand, opponentHand, finalBoard))
+	}
+
+	return accumulator.result(), nil
+}
@@ -0,0 +1,205 @@
+package poker
+
+import (
+	"math"
+	"testing"
+
+	"github.com/chehsunliu/poker"
+)
+
+func cards(strs ...string) []poker.Card {
+	result := make([]poker.Card, 0, len(strs))
+	for _, s := range strs {
+		result = append(result, poker.NewCard(s))
+	}
+	return result
+}
+
+func TestEvaluateLowHand(t *testing.T) {
+	// テストケース1: ホイール（A-2-3-4-5）は最強のロー
+	t.Run("Wheel is the best low", func(t *testing.T) {
+		wheel := evaluateLowHand(cards("Ah", "2d", "Kc", "Kd"), cards("3c", "4s", "5h", "Qd", "Jc"))
+		eightLow := evaluateLowHand(cards("7h", "8d", "Kc", "Kd"), cards("3c", "4s", "5h", "Qd", "Jc"))
+		if wheel == noLowRank || eightLow == noLowRank {
+			t.Fatal("Expected both hands to qualify for low")
+		}
+		if wheel >= eightLow {
+			t.Errorf("Expected wheel (%d) to beat 8-7 low (%d)", wheel, eightLow)
+		}
+	})
+
+	// テストケース2: ハンドから2枚使う必要がある（ボードのみのローは不可）
+	t.Run("Must use exactly 2 hole cards", func(t *testing.T) {
+		low := evaluateLowHand(cards("Ah", "Kd", "Kc", "Qd"), cards("2c", "3s", "4h", "5d", "6c"))
+		if low != noLowRank {
+			t.Errorf("Expected no low with only one low hole card, got %d", low)
+		}
+	})
+
+	// テストケース3: ペアになるカードはローに使えない
+	t.Run("Paired cards do not qualify", func(t *testing.T) {
+		low := evaluateLowHand(cards("2h", "3d", "Kc", "Kd"), cards("2c", "3s", "9h", "Td", "Jc"))
+		if low != noLowRank {
+			t.Errorf("Expected no low with paired low cards, got %d", low)
+		}
+	})
+}
+
+func TestJudgeWinnerHiLo(t *testing.T) {
+	// テストケース1: ハイとローを分け合う
+	t.Run("High and low split", func(t *testing.T) {
+		yourHand := cards("Ah", "2d", "Kc", "Kd")     // ナッツロー
+		opponentHand := cards("Qh", "Qd", "Jc", "9d") // Qのセット
+		board := cards("3c", "4s", "7h", "Qs", "Tc")
+
+		showdown, err := JudgeWinnerHiLo(yourHand, opponentHand, board)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if showdown.HighWinner != "opponentHand" || showdown.LowWinner != "yourHand" {
+			t.Errorf("Expected opponent high and your low, got %+v", showdown)
+		}
+		if showdown.YourShare != 0.5 {
+			t.Errorf("Expected 0.5 share, got %.2f", showdown.YourShare)
+		}
+	})
+
+	// テストケース2: ローが成立しない場合はハイがスクープ
+	t.Run("No qualifying low", func(t *testing.T) {
+		yourHand := cards("Ah", "Ad", "Kc", "Kd")
+		opponentHand := cards("Qh", "Qd", "Jc", "9d")
+		board := cards("As", "Ts", "9h", "Qs", "Tc")
+
+		showdown, err := JudgeWinnerHiLo(yourHand, opponentHand, board)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if showdown.LowWinner != "none" {
+			t.Errorf("Expected no low, got %s", showdown.LowWinner)
+		}
+		if showdown.YourShare != 1 {
+			t.Errorf("Expected scoop, got share %.2f", showdown.YourShare)
+		}
+	})
+
+	// テストケース3: ローを分け合いハイを獲得するとポットの3/4
+	t.Run("Quartered low", func(t *testing.T) {
+		yourHand := cards("Ah", "2d", "Kc", "Kd")     // A-2ロー + Kのセット
+		opponentHand := cards("As", "2c", "Qh", "Jd") // A-2ロー
+		board := cards("3c", "4s", "7h", "Ks", "Tc")
+
+		showdown, err := JudgeWinnerHiLo(yourHand, opponentHand, board)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if showdown.HighWinner != "yourHand" || showdown.LowWinner != "tie" {
+			t.Errorf("Expected your high and tied low, got %+v", showdown)
+		}
+		if showdown.YourShare != 0.75 {
+			t.Errorf("Expected 0.75 share, got %.2f", showdown.YourShare)
+		}
+	})
+
+	// テストケース4: Big O（5枚ハンド）
+	t.Run("Big O hands", func(t *testing.T) {
+		yourHand := cards("Ah", "2d", "Kc", "Kd", "9s")
+		opponentHand := cards("Qh", "Qd", "Jc", "9d", "8c")
+		board := cards("3c", "4s", "7h", "Qs", "Tc")
+
+		showdown, err := JudgeWinnerHiLo(yourHand, opponentHand, board)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if showdown.LowWinner != "yourHand" {
+			t.Errorf("Expected your low, got %s", showdown.LowWinner)
+		}
+	})
+
+	// テストケース5: オマハ以外・枚数の異なるハンドはエラー
+	t.Run("Rejects non-Omaha hands", func(t *testing.T) {
+		board := cards("3c", "4s", "7h", "Qs", "Tc")
+		if _, err := JudgeWinnerHiLo(cards("Ah", "2d"), cards("Qh", "Qd"), board); err == nil {
+			t.Error("Expected error for Hold'em hands, got nil")
+		}
+		if _, err := JudgeWinnerHiLo(cards("Ah", "2d", "Kc", "Kd"), cards("Qh", "Qd", "Jc", "9d", "8c"), board); err == nil {
+			t.Error("Expected error for mixed hand sizes, got nil")
+		}
+		if _, err := JudgeWinnerHiLo(cards("Ah", "2d", "Kc", "Kd", "9s", "8s", "6d"), cards("Qh", "Qd", "Jc", "9d", "8c", "5h", "5d"), board); err == nil {
+			t.Error("Expected error for 7 card hands, got nil")
+		}
+	})
+}
+
+func TestCalculateHandVsHandHiLoEquity(t *testing.T) {
+	yourHand := cards("Ah", "2d", "3c", "Kd")
+	opponentHand := cards("Qh", "Qd", "Jc", "Td")
+	board := cards("4c", "7s", "Qs")
+
+	// テストケース1: 内訳の合計がエクイティと整合する
+	t.Run("Breakdown is consistent", func(t *testing.T) {
+		equity, err := CalculateHandVsHandHiLoEquity(yourHand, opponentHand, board)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if equity.Equity <= 0 || equity.Equity >= 100 {
+			t.Errorf("Expected equity between 0 and 100, got %.2f", equity.Equity)
+		}
+		if equity.LowOnly <= 0 {
+			t.Errorf("Expected some low-only runouts, got %.2f", equity.LowOnly)
+		}
+		total := equity.Scoop + equity.HighOnly + equity.LowOnly + equity.Split
+		if total > 100+1e-9 {
+			t.Errorf("Expected breakdown to be at most 100%%, got %.2f", total)
+		}
+
+		mcEquity, err := CalculateHandVsHandHiLoEquityMonteCarlo(yourHand, opponentHand, board, ACCURATE_ITERATIONS, nil)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if math.Abs(mcEquity.Equity-equity.Equity) > 3 {
+			t.Errorf("Monte Carlo %.2f%% differs from exhaustive %.2f%%", mcEquity.Equity, equity.Equity)
+		}
+	})
+
+	// テストケース2: Hold'emハンドはエラー
+	t.Run("Hold'em hands are rejected", func(t *testing.T) {
+		if _, err := CalculateHandVsHandHiLoEquity(cards("Ah", "2d"), cards("Qh", "Qd"), board); err == nil {
+			t.Error("Expected error for Hold'em hands, got nil")
+		}
+		if _, err := CalculateHandVsHandHiLoEquity(cards("Ah", "2d", "3c", "Kd", "9s", "8s", "6d"), cards("Qh", "Qd", "Jc", "Td", "8c", "5h", "5d"), board); err == nil {
+			t.Error("Expected error for 7 card hands, got nil")
+		}
+	})
+
+	// テストケース3: ローを完成させるリバーがすべてデッドならローは成立しない
+	t.Run("Dead cards", func(t *testing.T) {
+		turnBoard := cards("4c", "7s", "Qs", "Kh")
+		deadCards := cards(
+			"As", "Ad", "Ac", "2s", "2h", "2c", "3s", "3h", "3d",
+			"5s", "5h", "5d", "5c", "6s", "6h", "6d", "6c", "8s", "8h", "8d", "8c",
+		)
+
+		equity, err := CalculateHandVsHandHiLoEquity(yourHand, opponentHand, turnBoard)
+		if err != nil || equity.LowOnly <= 0 {
+			t.Fatalf("Expected some low-only runouts without dead cards, got %+v (%v)", equity, err)
+		}
+		equity, err = CalculateHandVsHandHiLoEquityWithDeadCards(yourHand, opponentHand, turnBoard, deadCards)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if equity.LowOnly != 0 {
+			t.Errorf("Expected no low-only runouts, got %+v", equity)
+		}
+		mcEquity, err := CalculateHandVsHandHiLoEquityMonteCarloWithDeadCards(yourHand, opponentHand, turnBoard, deadCards, FAST_ITERATIONS, NewRand(1))
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if mcEquity.LowOnly != 0 {
+			t.Errorf("Expected no low-only Monte Carlo runouts, got %+v", mcEquity)
+		}
+
+		if _, err := CalculateHandVsHandHiLoEquityWithDeadCards(yourHand, opponentHand, turnBoard, cards("Ah")); err == nil {
+			t.Error("Expected error for a dead card in the hero hand, got nil")
+		}
+	})
+}
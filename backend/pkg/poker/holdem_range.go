@@ -0,0 +1,240 @@
+package poker
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/chehsunliu/poker"
+)
+
+// holdemRanks はランクの文字（ランクの値の順）です
+const holdemRanks = "23456789TJQKA"
+
+// holdemSuits はコンボを展開するスートの順序です
+const holdemSuits = "shdc"
+
+// holdemHandClass はスートを区別しないホールデムのハンド（"AKs", "QQ"など）です
+type holdemHandClass struct {
+	high, low int  // ランクの値（2=0〜A=12、high >= low）
+	suited    bool // スーテッドを含むか
+	offsuit   bool // オフスートを含むか（ペアの場合は常にtrue）
+}
+
+// isPair はポケットペアかを返します
+func (c holdemHandClass) isPair() bool {
+	return c.high == c.low
+}
+
+// ParseHoldemRange はNLHEのレンジ表記を頻度付きのコンボに展開します
+// カンマ区切りで次の表記を受け付けます
+//
+//	QQ, AKs, AKo, AK      ペア・スーテッド・オフスート（AKはAKsとAKoの両方）
+//	QQ+, ATs+             ペアはQQ〜AA、ペア以外はキッカーをハイカードの1つ下まで（ATs〜AKs）
+//	QQ-99, KTo-K8o        ペア・ハイカードが同じハンドの範囲
+//	76s-54s               ギャップが同じコネクターの範囲
+//	AsKd                  スートを指定した1コンボ
+//	AKo:0.5               頻度（0〜1、省略時は1）
+//
+// 同じコンボが複数回指定された場合は、後の頻度で上書きします
+func ParseHoldemRange(s string) (WeightedRange, error) {
+	var weightedRange WeightedRange
+	index := make(map[[2]poker.Card]int)
+
+	for _, token := range strings.Split(s, ",") {
+		token = strings.TrimSpace(token)
+		if token == "" {
+			continue
+		}
+
+		notation, weight, err := splitHoldemWeight(token)
+		if err != nil {
+			return nil, fmt.Errorf("invalid range %q: %v", token, err)
+		}
+		combos, err := expandHoldemNotation(notation)
+		if err != nil {
+			return nil, fmt.Errorf("invalid range %q: %v", token, err)
+		}
+
+		for _, combo := range combos {
+			// カードの順序によらず同じコンボを同じキーにする
+			key := combo
+			if key[0] > key[1] {
+				key[0], key[1] = key[1], key[0]
+			}
+			if i, ok := index[key]; ok {
+				weightedRange[i].Weight = weight
+				continue
+			}
+			index[key] = len(weightedRange)
+			weightedRange = append(weightedRange, WeightedHand{Cards: []poker.Card{combo[0], combo[1]}, Weight: weight})
+		}
+	}
+
+	if len(weightedRange) == 0 {
+		return nil, fmt.Errorf("empty range")
+	}
+	return weightedRange, nil
+}
+
+// splitHoldemWeight は "AKo:0.5" を表記と頻度に分けます
+func splitHoldemWeight(token string) (string, float64, error) {
+	idx := strings.Index(token, ":")
+	if idx < 0 {
+		return token, 1.0, nil
+	}
+	weight, err := strconv.ParseFloat(strings.TrimSpace(token[idx+1:]), 64)
+	if err != nil {
+		return "", 0, fmt.Errorf("invalid weight: %v", err)
+	}
+	if weight < 0 || weight > 1 {
+		return "", 0, fmt.Errorf("weight out of range: %v", weight)
+	}
+	return strings.TrimSpace(token[:idx]), weight, nil
+}
+
+// expandHoldemNotation は1つの表記（頻度を除く）をコンボに展開します
+func expandHoldemNotation(notation string) ([][2]poker.Card, error) {
+	// スートを指定した1コンボ（"AsKd"）
+	if len(notation) == 4 && strings.ContainsAny(notation[1:2], "shdcSHDC") && strings.ContainsAny(notation[3:4], "shdcSHDC") {
+		hand, err := ParseHand(notation)
+		if err != nil {
+			return nil, err
+		}
+		if len(hand) != 2 {
+			return nil, fmt.Errorf("expected 2 cards, got %d", len(hand))
+		}
+		return [][2]poker.Card{{hand[0], hand[1]}}, nil
+	}
+
+	var classes []holdemHandClass
+	switch {
+	case strings.Contains(notation, "-"):
+		parts := strings.Split(notation, "-")
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("invalid range notation")
+		}
+		from, err := parseHoldemHandClass(parts[0])
+		if err != nil {
+			return nil, err
+		}
+		to, err := parseHoldemHandClass(parts[1])
+		if err != nil {
+			return nil, err
+		}
+		if classes, err = holdemClassRange(from, to); err != nil {
+			return nil, err
+		}
+	case strings.HasSuffix(notation, "+"):
+		base, err := parseHoldemHandClass(strings.TrimSuffix(notation, "+"))
+		if err != nil {
+			return nil, err
+		}
+		if base.isPair() {
+			classes, _ = holdemClassRange(base, holdemHandClass{high: 12, low: 12, offsuit: true})
+		} else {
+			top := base
+			top.low = base.high - 1
+			classes, _ = holdemClassRange(base, top)
+		}
+	default:
+		class, err := parseHoldemHandClass(notation)
+		if err != nil {
+			return nil, err
+		}
+		classes = []holdemHandClass{class}
+	}
+
+	var combos [][2]poker.Card
+	for _, class := range classes {
+		combos = append(combos, class.combos()...)
+	}
+	return combos, nil
+}
+
+// parseHoldemHandClass は "AKs", "AKo", "AK", "QQ" を解析します
+func parseHoldemHandClass(s string) (holdemHandClass, error) {
+	if len(s) != 2 && len(s) != 3 {
+		return holdemHandClass{}, fmt.Errorf("invalid hand %q", s)
+	}
+	first := strings.IndexByte(holdemRanks, strings.ToUpper(s[0:1])[0])
+	second := strings.IndexByte(holdemRanks, strings.ToUpper(s[1:2])[0])
+	if first < 0 || second < 0 {
+		return holdemHandClass{}, fmt.Errorf("invalid rank in %q", s)
+	}
+	class := holdemHandClass{high: first, low: second, suited: true, offsuit: true}
+	if class.low > class.high {
+		class.high, class.low = class.low, class.high
+	}
+
+	if len(s) == 3 {
+		switch strings.ToLower(s[2:3]) {
+		case "s":
+			class.offsuit = false
+		case "o":
+			class.suited = false
+		default:
+			return holdemHandClass{}, fmt.Errorf("invalid suitedness in %q", s)
+		}
+	}
+	if class.isPair() {
+		if len(s) == 3 {
+			return holdemHandClass{}, fmt.Errorf("pairs cannot be suited or offsuit: %q", s)
+		}
+		class.suited = false
+	}
+	return class, nil
+}
+
+// holdemClassRange は2つのハンドの間のハンドを列挙します
+// ペア同士、ハイカードが同じハンド同士、ギャップが同じハンド同士のみ指定できます
+func holdemClassRange(from, to holdemHandClass) ([]holdemHandClass, error) {
+	if from.suited != to.suited || from.offsuit != to.offsuit {
+		return nil, fmt.Errorf("range ends must have the same suitedness")
+	}
+
+	var classes []holdemHandClass
+	switch {
+	case from.isPair() && to.isPair():
+		lo, hi := min(from.high, to.high), max(from.high, to.high)
+		for rank := hi; rank >= lo; rank-- {
+			classes = append(classes, holdemHandClass{high: rank, low: rank, offsuit: true})
+		}
+	case from.isPair() || to.isPair():
+		return nil, fmt.Errorf("cannot mix pairs and non-pairs in a range")
+	case from.high == to.high:
+		lo, hi := min(from.low, to.low), max(from.low, to.low)
+		for rank := hi; rank >= lo; rank-- {
+			classes = append(classes, holdemHandClass{high: from.high, low: rank, suited: from.suited, offsuit: from.offsuit})
+		}
+	case from.high-from.low == to.high-to.low:
+		gap := from.high - from.low
+		lo, hi := min(from.high, to.high), max(from.high, to.high)
+		for rank := hi; rank >= lo; rank-- {
+			classes = append(classes, holdemHandClass{high: rank, low: rank - gap, suited: from.suited, offsuit: from.offsuit})
+		}
+	default:
+		return nil, fmt.Errorf("range ends must share the high card or the gap")
+	}
+	return classes, nil
+}
+
+// combos はハンドのコンボを列挙します（ペア6、スーテッド4、オフスート12コンボ）
+func (c holdemHandClass) combos() [][2]poker.Card {
+	var combos [][2]poker.Card
+	for i, suit1 := range holdemSuits {
+		for j, suit2 := range holdemSuits {
+			if c.isPair() && j <= i {
+				continue
+			}
+			if !c.isPair() && ((i == j && !c.suited) || (i != j && !c.offsuit)) {
+				continue
+			}
+			combos = append(combos, [2]poker.Card{
+				poker.NewCard(string(holdemRanks[c.high]) + string(suit1)),
+				poker.NewCard(string(holdemRanks[c.low]) + string(suit2)),
+			})
+		}
+	}
+	return combos
+}
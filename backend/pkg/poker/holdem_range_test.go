@@ -0,0 +1,78 @@
+package poker
+
+import (
+	"testing"
+)
+
+func TestParseHoldemRange(t *testing.T) {
+	// テストケース1: 表記ごとのコンボ数
+	t.Run("Combo counts", func(t *testing.T) {
+		testCases := []struct {
+			notation string
+			combos   int
+		}{
+			{"QQ", 6},
+			{"AKs", 4},
+			{"AKo", 12},
+			{"AK", 16},
+			{"QQ+", 18},     // QQ, KK, AA
+			{"ATs+", 16},    // ATs, AJs, AQs, AKs
+			{"QQ-99", 24},   // QQ, JJ, TT, 99
+			{"KTo-K8o", 36}, // KTo, K9o, K8o
+			{"76s-54s", 12}, // 76s, 65s, 54s
+			{"AsKd", 1},
+			{"22+, A2s+", 78 + 48},
+		}
+
+		for _, tc := range testCases {
+			weightedRange, err := ParseHoldemRange(tc.notation)
+			if err != nil {
+				t.Fatalf("%s: expected no error, got %v", tc.notation, err)
+			}
+			if len(weightedRange) != tc.combos {
+				t.Errorf("%s: expected %d combos, got %d", tc.notation, tc.combos, len(weightedRange))
+			}
+			if err := VariantHoldem.ValidateRange(weightedRange); err != nil {
+				t.Errorf("%s: expected Hold'em hands, got %v", tc.notation, err)
+			}
+			for _, hand := range weightedRange {
+				if HasCardDuplicates(hand.Cards) {
+					t.Errorf("%s: invalid combo %s", tc.notation, GenerateBoardString(hand.Cards))
+				}
+			}
+		}
+	})
+
+	// テストケース2: 展開されるハンドと頻度
+	t.Run("Expanded hands and weights", func(t *testing.T) {
+		weightedRange, err := ParseHoldemRange("76s-54s, AKo:0.5, AcKd:0.25")
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		weights := make(map[string]float64)
+		for _, hand := range weightedRange {
+			weights[GenerateBoardString(hand.Cards)] = hand.Weight
+		}
+		for hand, expected := range map[string]float64{"7s6s": 1, "6h5h": 1, "5c4c": 1, "AsKd": 0.5, "AcKd": 0.25} {
+			if weight, ok := weights[hand]; !ok || weight != expected {
+				t.Errorf("%s: expected weight %.2f, got %.2f (found: %v)", hand, expected, weight, ok)
+			}
+		}
+		// 後から指定したコンボは追加せずに頻度を上書きする
+		if len(weightedRange) != 12+12 {
+			t.Errorf("Expected 24 combos, got %d", len(weightedRange))
+		}
+		if _, ok := weights["8s7s"]; ok {
+			t.Error("Expected 87s not to be included")
+		}
+	})
+
+	// テストケース3: 不正な表記はエラー
+	t.Run("Invalid notation", func(t *testing.T) {
+		for _, notation := range []string{"", "AXs", "QQs", "AKs:1.5", "AKs:abc", "QQ-AKs", "AKs-QJo", "AKs-T8s", "AKx", "AsAs"} {
+			if _, err := ParseHoldemRange(notation); err == nil {
+				t.Errorf("%q: expected error, got nil", notation)
+			}
+		}
+	})
+}
@@ -0,0 +1,231 @@
+package poker
+
+import (
+	"sort"
+
+	"github.com/chehsunliu/poker"
+)
+
+// suitOrder はスートの並び順です（FullDeckと同じ順序）
+const suitOrder = "shdc"
+
+var (
+	// isoDeck はFullDeckの順序のカード一覧です（インデックスは ランク*4 + スート）
+	isoDeck = FullDeck()
+	// isoCardIndex はカードからisoDeckのインデックスを引くためのテーブルです
+	isoCardIndex = func() map[poker.Card]int {
+		index := make(map[poker.Card]int, len(isoDeck))
+		for i, card := range isoDeck {
+			index[card] = i
+		}
+		return index
+	}()
+	// allSuitPermutations は4スートの全ての置換（24通り）です
+	allSuitPermutations = generateSuitPermutations()
+)
+
+// SuitPermutation はスートの置換を表します
+// p[s]はsuitOrderのs番目のスートの置換先のインデックスです
+type SuitPermutation [4]int
+
+// IdentitySuitPermutation はスートを入れ替えない恒等置換です
+var IdentitySuitPermutation = SuitPermutation{0, 1, 2, 3}
+
+// Apply はカードのスートを置換したカードを返します（ランクと並び順は変わりません）
+func (p SuitPermutation) Apply(cards []poker.Card) []poker.Card {
+	permuted := make([]poker.Card, len(cards))
+	for i, card := range cards {
+		idx := isoCardIndex[card]
+		permuted[i] = isoDeck[idx/4*4+p[idx%4]]
+	}
+	return permuted
+}
+
+// String は置換を"s->h,h->s,d->d,c->c"の形式で返します
+func (p SuitPermutation) String() string {
+	s := ""
+	for from, to := range p {
+		if from > 0 {
+			s += ","
+		}
+		s += string(suitOrder[from]) + "->" + string(suitOrder[to])
+	}
+	return s
+}
+
+// generateSuitPermutations は4スートの全ての置換を生成します
+func generateSuitPermutations() []SuitPermutation {
+	var perms []SuitPermutation
+	var permute func(p SuitPermutation, used [4]bool, pos int)
+	permute = func(p SuitPermutation, used [4]bool, pos int) {
+		if pos == 4 {
+			perms = append(perms, p)
+			return
+		}
+		for s := 0; s < 4; s++ {
+			if used[s] {
+				continue
+			}
+			p[pos] = s
+			used[s] = true
+			permute(p, used, pos+1)
+			used[s] = false
+		}
+	}
+	permute(SuitPermutation{}, [4]bool{}, 0)
+	return perms
+}
+
+// sortedCardIndices はカードのisoDeckインデックスを昇順に並べて返します（カードの並び順に依存しない表現）
+func sortedCardIndices(cards []poker.Card, p SuitPermutation) []int {
+	indices := make([]int, len(cards))
+	for i, card := range cards {
+		idx := isoCardIndex[card]
+		indices[i] = idx/4*4 + p[idx%4]
+	}
+	sort.Ints(indices)
+	return indices
+}
+
+// compareIndices はインデックス列を辞書順で比較します
+func compareIndices(a []int, b []int) int {
+	for i := 0; i < len(a) && i < len(b); i++ {
+		if a[i] != b[i] {
+			return a[i] - b[i]
+		}
+	}
+	return len(a) - len(b)
+}
+
+// indicesToCards はisoDeckインデックスの列をカードに変換します
+func indicesToCards(indices []int) []poker.Card {
+	cards := make([]poker.Card, len(indices))
+	for i, idx := range indices {
+		cards[i] = isoDeck[idx]
+	}
+	return cards
+}
+
+// CanonicalizeMatchup は(ヒーロー, 相手, ボード)をスートの置換で正準形に変換します
+// スートを入れ替えただけの組み合わせはエクイティが同じになるため、同じ正準形に変換されます
+// 全24通りの置換のうち、(ヒーロー, ボード, 相手)のカード列が辞書順で最小になる置換を選び、
+// 並べ替えた各カード列とその置換を返します
+func CanonicalizeMatchup(yourHand []poker.Card, opponentHand []poker.Card, board []poker.Card) (canonicalHero []poker.Card, canonicalOpponent []poker.Card, canonicalBoard []poker.Card, perm SuitPermutation) {
+	var best [3][]int
+	for i, p := range allSuitPermutations {
+		candidate := [3][]int{
+			sortedCardIndices(yourHand, p),
+			sortedCardIndices(board, p),
+			sortedCardIndices(opponentHand, p),
+		}
+		if i > 0 {
+			cmp := 0
+			for j := range candidate {
+				if cmp = compareIndices(candidate[j], best[j]); cmp != 0 {
+					break
+				}
+			}
+			if cmp >= 0 {
+				continue
+			}
+		}
+		best = candidate
+		perm = p
+	}
+	return indicesToCards(best[0]), indicesToCards(best[2]), indicesToCards(best[1]), perm
+}
+
+// SuitIsomorphism はヒーローのハンドとボードを固定したときのスートの対称性を表します
+// ヒーローのハンドとボードを変えないスートの置換で移り合う相手ハンドは、エクイティが同じになります
+// レンジ計算ではヒーローとボードが共通のため、CanonicalizeMatchupを毎回呼ぶ代わりにこちらを使用します
+type SuitIsomorphism struct {
+	perms []SuitPermutation // ヒーローのハンドとボードを変えない置換（恒等置換を含む）
+}
+
+// NewSuitIsomorphism はヒーローのハンドとボードを変えないスートの置換を求めます
+// デッドカードを指定した場合は、デッドカードの集合も変えない置換のみを使用します
+func NewSuitIsomorphism(yourHand []poker.Card, board []poker.Card, deadCards ...poker.Card) *SuitIsomorphism {
+	heroIndices := sortedCardIndices(yourHand, IdentitySuitPermutation)
+	boardIndices := sortedCardIndices(board, IdentitySuitPermutation)
+	deadIndices := sortedCardIndices(deadCards, IdentitySuitPermutation)
+
+	iso := &SuitIsomorphism{}
+	for _, p := range allSuitPermutations {
+		if compareIndices(sortedCardIndices(yourHand, p), heroIndices) == 0 &&
+			compareIndices(sortedCardIndices(board, p), boardIndices) == 0 &&
+			compareIndices(sortedCardIndices(deadCards, p), deadIndices) == 0 {
+			iso.perms = append(iso.perms, p)
+		}
+	}
+	return iso
+}
+
+// Size は対称性を持つ置換の数（恒等置換を含む）を返します。1の場合は対称性がありません
+func (s *SuitIsomorphism) Size() int {
+	return len(s.perms)
+}
+
+// Canonical は相手ハンドの正準形（同値なハンドの中で代表となるハンド）を返します
+func (s *SuitIsomorphism) Canonical(opponentHand []poker.Card) []poker.Card {
+	var best []int
+	for i, p := range s.perms {
+		candidate := sortedCardIndices(opponentHand, p)
+		if i == 0 || compareIndices(candidate, best) < 0 {
+			best = candidate
+		}
+	}
+	return indicesToCards(best)
+}
+
+// CanonicalKey は相手ハンドの正準形を文字列で返します（同値なハンドは同じキーになります）
+func (s *SuitIsomorphism) CanonicalKey(opponentHand []poker.Card) string {
+	return GenerateBoardString(s.Canonical(opponentHand))
+}
+
+// groupRepresentatives は各同値類の代表ハンドを返します
+func groupRepresentatives(opponentRange WeightedRange, groups [][]int) [][]poker.Card {
+	hands := make([][]poker.Card, len(groups))
+	for i, group := range groups {
+		hands[i] = opponentRange[group[0]].Cards
+	}
+	return hands
+}
+
+// groupIsomorphicHands はインデックスで指定した相手ハンドを同値類ごとにまとめます
+// 各グループの先頭が代表ハンドで、グループは代表ハンドのindicesでの出現順に並びます
+func (s *SuitIsomorphism) groupIsomorphicHands(opponentRange WeightedRange, indices []int) [][]int {
+	var groups [][]int
+	groupIndex := make(map[string]int)
+	for _, i := range indices {
+		key := s.CanonicalKey(opponentRange[i].Cards)
+		if g, ok := groupIndex[key]; ok {
+			groups[g] = append(groups[g], i)
+			continue
+		}
+		groupIndex[key] = len(groups)
+		groups = append(groups, []int{i})
+	}
+	return groups
+}
+
+// IsSuitSymmetric はレンジがスートの入れ替えに対して対称か（どのスートの置換でも各ハンドの頻度が変わらないか）を返します
+// 対称なレンジに対するプリフロップのエクイティは、スートを入れ替えただけのハンドで同じになります
+func IsSuitSymmetric(weightedRange WeightedRange) bool {
+	weights := make(map[string]float64, len(weightedRange))
+	for _, hand := range weightedRange {
+		weights[suitPermutedKey(hand.Cards, IdentitySuitPermutation)] += hand.Weight
+	}
+	for _, p := range allSuitPermutations {
+		for _, hand := range weightedRange {
+			if weights[suitPermutedKey(hand.Cards, p)] != weights[suitPermutedKey(hand.Cards, IdentitySuitPermutation)] {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// suitPermutedKey はスートを置換したハンドの、カードの並び順によらないキーを返します
+func suitPermutedKey(cards []poker.Card, p SuitPermutation) string {
+	return GenerateBoardString(indicesToCards(sortedCardIndices(cards, p)))
+}
@@ -0,0 +1,121 @@
+package poker
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/chehsunliu/poker"
+)
+
+func TestCanonicalizeMatchup(t *testing.T) {
+	// テストケース1: スートを入れ替えた組み合わせは同じ正準形になる
+	t.Run("Suit-swapped matchups share a canonical form", func(t *testing.T) {
+		hero1, villain1, board1 := cards("As", "Ks"), cards("Qh", "Jh"), cards("2s", "7h", "Td")
+		// s->h, h->c, d->s
+		hero2, villain2, board2 := cards("Ah", "Kh"), cards("Qc", "Jc"), cards("2h", "7c", "Ts")
+
+		h1, v1, b1, _ := CanonicalizeMatchup(hero1, villain1, board1)
+		h2, v2, b2, _ := CanonicalizeMatchup(hero2, villain2, board2)
+		if !reflect.DeepEqual(h1, h2) || !reflect.DeepEqual(v1, v2) || !reflect.DeepEqual(b1, b2) {
+			t.Errorf("Expected identical canonical forms, got %s/%s/%s and %s/%s/%s",
+				GenerateBoardString(h1), GenerateBoardString(v1), GenerateBoardString(b1),
+				GenerateBoardString(h2), GenerateBoardString(v2), GenerateBoardString(b2))
+		}
+
+		equity1, _ := CalculateHandVsHandEquity(hero1, villain1, board1)
+		equity2, _ := CalculateHandVsHandEquity(hero2, villain2, board2)
+		if equity1 != equity2 {
+			t.Errorf("Expected isomorphic matchups to have the same equity, got %+v and %+v", equity1, equity2)
+		}
+	})
+
+	// テストケース2: 返された置換を適用すると正準形になる
+	t.Run("Returned permutation maps to the canonical form", func(t *testing.T) {
+		hero, villain, board := cards("Ac", "Kd", "Qd", "Jh"), cards("Ts", "9s", "8c", "7c"), cards("2d", "5h", "9c")
+		canonicalHero, canonicalVillain, canonicalBoard, perm := CanonicalizeMatchup(hero, villain, board)
+
+		check := func(name string, original []poker.Card, canonical []poker.Card) {
+			got := sortedCardIndices(perm.Apply(original), IdentitySuitPermutation)
+			want := sortedCardIndices(canonical, IdentitySuitPermutation)
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("%s: applying %s gave %s, want %s", name, perm,
+					GenerateBoardString(perm.Apply(original)), GenerateBoardString(canonical))
+			}
+		}
+		check("hero", hero, canonicalHero)
+		check("villain", villain, canonicalVillain)
+		check("board", board, canonicalBoard)
+	})
+
+	// テストケース3: スートの対応が異なる組み合わせは区別される
+	t.Run("Non-isomorphic matchups differ", func(t *testing.T) {
+		_, v1, _, _ := CanonicalizeMatchup(cards("As", "Ks"), cards("Qs", "Js"), cards("2s", "7h", "Td"))
+		_, v2, _, _ := CanonicalizeMatchup(cards("As", "Ks"), cards("Qh", "Jh"), cards("2s", "7h", "Td"))
+		if reflect.DeepEqual(v1, v2) {
+			t.Errorf("Expected different canonical villains, both got %s", GenerateBoardString(v1))
+		}
+	})
+}
+
+func TestSuitIsomorphism(t *testing.T) {
+	// テストケース1: ヒーローとボードで全スートが区別される場合は対称性がない
+	t.Run("No symmetry", func(t *testing.T) {
+		iso := NewSuitIsomorphism(cards("As", "Ks"), cards("2h", "7d", "Tc"))
+		if iso.Size() != 1 {
+			t.Errorf("Expected only the identity permutation, got %d", iso.Size())
+		}
+	})
+
+	// テストケース2: モノトーンボードでヒーローのハートとダイヤは入れ替え可能
+	t.Run("Swappable suits", func(t *testing.T) {
+		iso := NewSuitIsomorphism(cards("Ah", "Ad"), cards("2s", "7s", "Ts"))
+		if iso.Size() != 2 {
+			t.Fatalf("Expected 2 permutations, got %d", iso.Size())
+		}
+		if iso.CanonicalKey(cards("Kh", "Qc")) != iso.CanonicalKey(cards("Kd", "Qc")) {
+			t.Errorf("Expected KhQc and KdQc to be isomorphic")
+		}
+		if iso.CanonicalKey(cards("Kh", "Qh")) == iso.CanonicalKey(cards("Kc", "Qc")) {
+			t.Errorf("Expected KhQh and KcQc not to be isomorphic")
+		}
+	})
+
+	// テストケース3: 重複を除いた並列計算は全ハンドを個別に計算した結果と一致する
+	t.Run("Deduplicated range calculation matches per-hand results", func(t *testing.T) {
+		yourHand := cards("Ah", "Ad")
+		board := cards("2s", "7s", "Ts")
+		opponentRange := WeightedRange{
+			{Cards: cards("Kh", "Qc"), Weight: 1.0},
+			{Cards: cards("Kd", "Qc"), Weight: 0.5},
+			{Cards: cards("Kc", "Qc"), Weight: 1.0},
+			{Cards: cards("9h", "8h"), Weight: 0.25},
+			{Cards: cards("9d", "8d"), Weight: 1.0},
+			{Cards: cards("Js", "Jc"), Weight: 1.0},
+		}
+
+		var results []EquityResult
+		var weights []float64
+		for _, hand := range opponentRange {
+			result, err := CalculateHandVsHandEquity(yourHand, hand.Cards, board)
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			results = append(results, result)
+			weights = append(weights, hand.Weight)
+		}
+		want := CombineEquityResults(results, weights)
+
+		equities, got, err := CalculateHandVsWeightedRangeEquityParallel(yourHand, opponentRange, board)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if got != want {
+			t.Errorf("Expected %+v, got %+v", want, got)
+		}
+		for i, hand := range opponentRange {
+			if equities[GenerateBoardString(hand.Cards)] != results[i].Equity {
+				t.Errorf("%s: expected %.4f, got %.4f", GenerateBoardString(hand.Cards), results[i].Equity, equities[GenerateBoardString(hand.Cards)])
+			}
+		}
+	})
+}
@@ -25,7 +25,10 @@ func TestJudgeWinner(t *testing.T) {
 		}
 
 		// 関数の実行
-		result := JudgeWinner(yourHand, opponentHand, board)
+		result, err := JudgeWinner(yourHand, opponentHand, board)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
 
 		// 結果の検証
 		if result != "yourHand" {
@@ -51,7 +54,10 @@ func TestJudgeWinner(t *testing.T) {
 		}
 
 		// 関数の実行
-		result := JudgeWinner(yourHand, opponentHand, board)
+		result, err := JudgeWinner(yourHand, opponentHand, board)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
 
 		// 結果の検証
 		if result != "opponentHand" {
@@ -77,7 +83,10 @@ func TestJudgeWinner(t *testing.T) {
 		}
 
 		// 関数の実行
-		result := JudgeWinner(yourHand, opponentHand, board)
+		result, err := JudgeWinner(yourHand, opponentHand, board)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
 
 		// 結果の検証
 		if result != "tie" {
@@ -103,7 +112,10 @@ func TestJudgeWinner(t *testing.T) {
 		}
 
 		// 関数の実行
-		result := JudgeWinner(yourHand, opponentHand, board)
+		result, err := JudgeWinner(yourHand, opponentHand, board)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
 
 		// 結果の検証
 		if result != "yourHand" {
@@ -129,7 +141,10 @@ func TestJudgeWinner(t *testing.T) {
 		}
 
 		// 関数の実行
-		result := JudgeWinner(yourHand, opponentHand, board)
+		result, err := JudgeWinner(yourHand, opponentHand, board)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
 
 		// 結果の検証
 		if result != "yourHand" {
@@ -366,7 +381,10 @@ func TestJudgeWinnerPLO5(t *testing.T) {
 		}
 
 		// 関数の実行
-		result := JudgeWinner(yourHand, opponentHand, board)
+		result, err := JudgeWinner(yourHand, opponentHand, board)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
 
 		// 結果の検証 - yourHandがstraightで勝つべき
 		if result != "yourHand" {
@@ -476,3 +494,116 @@ func TestJudgeWinnerPLO5(t *testing.T) {
 		}
 	})
 }
+
+func TestJudgeWinnerPLO6(t *testing.T) {
+	// テストケース1: PLO6 - Auto-detect 6 card hands
+	t.Run("PLO6 - Auto detect 6 card hands", func(t *testing.T) {
+		// テストデータの準備
+		yourHand := []poker.Card{
+			poker.NewCard("Ah"),
+			poker.NewCard("Ad"),
+			poker.NewCard("Kh"),
+			poker.NewCard("Kd"),
+			poker.NewCard("Qs"),
+			poker.NewCard("Js"),
+		}
+		opponentHand := []poker.Card{
+			poker.NewCard("2h"),
+			poker.NewCard("3h"),
+			poker.NewCard("4c"),
+			poker.NewCard("5d"),
+			poker.NewCard("6s"),
+			poker.NewCard("7c"),
+		}
+		board := []poker.Card{
+			poker.NewCard("Ac"),
+			poker.NewCard("Ks"),
+			poker.NewCard("Qc"),
+			poker.NewCard("Jd"),
+			poker.NewCard("Th"),
+		}
+
+		// 関数の実行
+		result, err := JudgeWinner(yourHand, opponentHand, board)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		// 結果の検証 - yourHandがstraightで勝つべき
+		if result != "yourHand" {
+			t.Errorf("Expected 'yourHand' to win with straight, got %s", result)
+		}
+	})
+
+	// テストケース2: PLO6 - 1枚フラッシュの防止テスト
+	t.Run("PLO6 - Prevent one-card flush", func(t *testing.T) {
+		// テストデータの準備
+		yourHand := []poker.Card{
+			poker.NewCard("Th"), // ハートは1枚のみ
+			poker.NewCard("3c"),
+			poker.NewCard("4d"),
+			poker.NewCard("5s"),
+			poker.NewCard("6c"),
+			poker.NewCard("7d"),
+		}
+		opponentHand := []poker.Card{
+			poker.NewCard("9h"),
+			poker.NewCard("8h"), // ハートが2枚
+			poker.NewCard("2d"),
+			poker.NewCard("3s"),
+			poker.NewCard("4c"),
+			poker.NewCard("5d"),
+		}
+		board := []poker.Card{
+			poker.NewCard("Ah"),
+			poker.NewCard("Kh"),
+			poker.NewCard("Qh"),
+			poker.NewCard("Jh"),
+			poker.NewCard("2c"),
+		}
+
+		// 関数の実行
+		result := JudgeWinnerPLO6(yourHand, opponentHand, board)
+
+		// 結果の検証 - 手札のハート1枚ではフラッシュにならないため、opponentHandが勝つべき
+		if result != "opponentHand" {
+			t.Errorf("Expected 'opponentHand' to win with flush, got %s", result)
+		}
+	})
+
+	// テストケース3: PLO6 - 同じ役の引き分け
+	t.Run("PLO6 - Tie with board straight", func(t *testing.T) {
+		// テストデータの準備
+		yourHand := []poker.Card{
+			poker.NewCard("Kh"),
+			poker.NewCard("Qd"),
+			poker.NewCard("2c"),
+			poker.NewCard("3c"),
+			poker.NewCard("4d"),
+			poker.NewCard("5s"),
+		}
+		opponentHand := []poker.Card{
+			poker.NewCard("Kc"),
+			poker.NewCard("Qs"),
+			poker.NewCard("2d"),
+			poker.NewCard("3h"),
+			poker.NewCard("4s"),
+			poker.NewCard("5d"),
+		}
+		board := []poker.Card{
+			poker.NewCard("Ah"),
+			poker.NewCard("Jd"),
+			poker.NewCard("Ts"),
+			poker.NewCard("8c"),
+			poker.NewCard("7h"),
+		}
+
+		// 関数の実行
+		result := JudgeWinnerPLO6(yourHand, opponentHand, board)
+
+		// 結果の検証 - どちらもKQでブロードウェイストレートのため引き分け
+		if result != "tie" {
+			t.Errorf("Expected 'tie', got %s", result)
+		}
+	})
+}
@@ -0,0 +1,410 @@
+package poker
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"runtime"
+	"sort"
+	"sync"
+
+	"github.com/chehsunliu/poker"
+)
+
+// 1つの組み合わせでカード重複を避けるためのリトライ上限
+const maxRangeSampleAttempts = 1000
+
+// JudgeWinners は複数プレイヤーのハンドから勝者のインデックスを返します
+// 引き分けの場合は同じランクのプレイヤー全員のインデックスを返します
+// ゲームタイプはJudgeWinnerと同様にハンドの枚数から判定し、枚数が混在している場合はエラーを返します
+func JudgeWinners(hands [][]poker.Card, board []poker.Card) ([]int, error) {
+	variant, err := DetectVariant(hands...)
+	if err != nil {
+		return nil, err
+	}
+	return judgeWinners(variant, hands, board), nil
+}
+
+// judgeWinners はゲームタイプのルールで複数プレイヤーの勝者のインデックスを返します
+func judgeWinners(variant Variant, hands [][]poker.Card, board []poker.Card) []int {
+	var winners []int
+	bestRank := worstHandRank + 1 // どのハンドよりも弱いランク
+
+	for i, hand := range hands {
+		rank := variant.Evaluate(hand, board)
+		if rank < bestRank {
+			bestRank = rank
+			winners = []int{i}
+		} else if rank == bestRank {
+			winners = append(winners, i)
+		}
+	}
+
+	return winners
+}
+
+// validateMultiwayHands は複数プレイヤーのハンド・ボード・デッドカードを検証し、ゲームタイプを返します
+func validateMultiwayHands(hands [][]poker.Card, board []poker.Card, deadCards []poker.Card) (Variant, error) {
+	if len(hands) < 2 {
+		return 0, fmt.Errorf("at least 2 hands are required, got %d", len(hands))
+	}
+	variant, err := DetectVariant(hands...)
+	if err != nil {
+		return 0, err
+	}
+	if HasCardDuplicates(append([][]poker.Card{board, deadCards}, hands...)...) {
+		return 0, fmt.Errorf("duplicate cards detected")
+	}
+	return variant, ValidateBoardSize(board)
+}
+
+// addShowdownShares は1回のショーダウン結果を各プレイヤーの獲得ポットに加算します
+// 引き分けの場合はポットを勝者の人数で等分します
+func addShowdownShares(shares []float64, winners []int, weight float64) {
+	for _, winner := range winners {
+		shares[winner] += weight / float64(len(winners))
+	}
+}
+
+// CalculateMultiwayEquity は複数ハンド（3人以上も可）のエクイティを全数計算します
+// 結果は入力と同じ順序の各プレイヤーのエクイティ（%）です
+func CalculateMultiwayEquity(hands [][]poker.Card, board []poker.Card) ([]float64, error) {
+	return CalculateMultiwayEquityWithDeadCards(hands, board, nil)
+}
+
+// CalculateMultiwayEquityWithDeadCards はデッドカードを考慮したCalculateMultiwayEquityです
+// デッドカード（フォールドしたハンドや見えたカード）はデッキから除かれ、ボードに配られません
+func CalculateMultiwayEquityWithDeadCards(hands [][]poker.Card, board []poker.Card, deadCards []poker.Card) ([]float64, error) {
+	variant, err := validateMultiwayHands(hands, board, deadCards)
+	if err != nil {
+		return nil, err
+	}
+
+	remainingDeck := RemainingDeck(append([][]poker.Card{board, deadCards}, hands...)...)
+	if len(remainingDeck) < CardsToComplete(board) {
+		return nil, fmt.Errorf("insufficient remaining cards")
+	}
+
+	shares := make([]float64, len(hands))
+	totalOutcomes := 0.0
+
+	forEachRunout(board, remainingDeck, func(finalBoard []poker.Card) {
+		addShowdownShares(shares, judgeWinners(variant, hands, finalBoard), 1)
+		totalOutcomes++
+	})
+
+	for i := range shares {
+		shares[i] = shares[i] / totalOutcomes * 100
+	}
+	return shares, nil
+}
+
+// CalculateMultiwayEquityMonteCarlo は複数ハンドのエクイティをモンテカルロシミュレーションで計算します
+// rngに同じシードの乱数生成器を渡すと同じ結果を返します（nilの場合は現在時刻をシードとします）
+func CalculateMultiwayEquityMonteCarlo(hands [][]poker.Card, board []poker.Card, iterations int, rng *rand.Rand) ([]float64, error) {
+	return CalculateMultiwayEquityMonteCarloWithDeadCards(hands, board, nil, iterations, rng)
+}
+
+// CalculateMultiwayEquityMonteCarloWithDeadCards はデッドカードを考慮したCalculateMultiwayEquityMonteCarloです
+func CalculateMultiwayEquityMonteCarloWithDeadCards(hands [][]poker.Card, board []poker.Card, deadCards []poker.Card, iterations int, rng *rand.Rand) ([]float64, error) {
+	variant, err := validateMultiwayHands(hands, board, deadCards)
+	if err != nil {
+		return nil, err
+	}
+	if iterations <= 0 {
+		return nil, fmt.Errorf("iterations must be positive, got %d", iterations)
+	}
+
+	remainingDeck := RemainingDeck(append([][]poker.Card{board, deadCards}, hands...)...)
+	if len(remainingDeck) < CardsToComplete(board) {
+		return nil, fmt.Errorf("insufficient remaining cards")
+	}
+
+	// 乱数生成器を初期化（nilの場合は現在時刻をシードとする）
+	rng = randOrDefault(rng)
+
+	finalBoard := make([]poker.Card, len(board)+CardsToComplete(board))
+	copy(finalBoard, board)
+
+	shares := make([]float64, len(hands))
+	for i := 0; i < iterations; i++ {
+		dealRandomRunout(rng, len(board), remainingDeck, finalBoard)
+		addShowdownShares(shares, judgeWinners(variant, hands, finalBoard), 1)
+	}
+
+	for i := range shares {
+		shares[i] = shares[i] / float64(iterations) * 100
+	}
+	return shares, nil
+}
+
+// CalculateHandVsRangesEquity はヒーローのハンドと複数の相手レンジのマルチウェイエクイティを全数計算します
+// 相手ハンドの全組み合わせ（カード重複なし）×全ランアウトを頻度で重み付けして集計するため、
+// 大きなレンジではCalculateHandVsRangesEquityMonteCarloを使用してください
+// 結果はインデックス0がヒーロー、i+1がopponentRanges[i]のエクイティ（%）です
+func CalculateHandVsRangesEquity(yourHand []poker.Card, opponentRanges []WeightedRange, board []poker.Card) ([]float64, error) {
+	return CalculateHandVsRangesEquityContext(context.Background(), yourHand, opponentRanges, board, nil, nil)
+}
+
+// CalculateHandVsRangesEquityWithDeadCards はデッドカードを考慮したCalculateHandVsRangesEquityです
+// デッドカードはランアウトに配られず、デッドカードを含む相手ハンドは各レンジから除外されます
+func CalculateHandVsRangesEquityWithDeadCards(yourHand []poker.Card, opponentRanges []WeightedRange, board []poker.Card, deadCards []poker.Card) ([]float64, error) {
+	return CalculateHandVsRangesEquityContext(context.Background(), yourHand, opponentRanges, board, deadCards, nil)
+}
+
+// CalculateHandVsRangesEquityContext はctxのキャンセル・期限に対応したCalculateHandVsRangesEquityです
+// deadCards（nil可）の扱いはCalculateHandVsRangesEquityWithDeadCardsと同じです
+// 最初の相手レンジのハンドが1つ完了するたびにprogress（nil可）に進捗を通知します
+// ctxが中断された場合は完了したハンドの組み合わせのみで集計したエクイティとctx.Err()を返します
+func CalculateHandVsRangesEquityContext(ctx context.Context, yourHand []poker.Card, opponentRanges []WeightedRange, board []poker.Card, deadCards []poker.Card, progress ProgressFunc) ([]float64, error) {
+	if len(opponentRanges) == 0 {
+		return nil, fmt.Errorf("at least 1 opponent range is required")
+	}
+	if err := ValidateBoardSize(board); err != nil {
+		return nil, err
+	}
+	if HasCardDuplicates(yourHand, board, deadCards) {
+		return nil, fmt.Errorf("duplicate cards detected")
+	}
+	if _, err := detectRangeVariant(yourHand, opponentRanges...); err != nil {
+		return nil, err
+	}
+
+	shares := make([]float64, len(opponentRanges)+1)
+	totalWeight := 0.0
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+
+	numCPU := runtime.NumCPU()
+	semaphore := make(chan struct{}, numCPU) // 同時実行数をCPUコア数に制限
+
+	var firstHands WeightedRange
+	for _, firstHand := range opponentRanges[0] {
+		if firstHand.Weight > 0 && !HasCardDuplicates(yourHand, firstHand.Cards, board, deadCards) {
+			firstHands = append(firstHands, firstHand)
+		}
+	}
+	tracker := newProgressTracker(progress, len(firstHands))
+
+	// 最初のレンジのハンドごとに並列で計算
+	for _, firstHand := range firstHands {
+		if !acquireSlot(ctx, semaphore) {
+			break
+		}
+		wg.Add(1)
+
+		go func(first WeightedHand) {
+			defer wg.Done()
+			defer func() { <-semaphore }()
+
+			localShares := make([]float64, len(shares))
+			localWeight := 0.0
+
+			hands := [][]poker.Card{yourHand, first.Cards}
+			var enumerate func(rangeIdx int, weight float64)
+			enumerate = func(rangeIdx int, weight float64) {
+				if ctx.Err() != nil {
+					return
+				}
+				if rangeIdx == len(opponentRanges) {
+					equities, err := CalculateMultiwayEquityWithDeadCards(hands, board, deadCards)
+					if err != nil {
+						return
+					}
+					for i, equity := range equities {
+						localShares[i] += equity * weight
+					}
+					localWeight += weight
+					return
+				}
+				for _, opponentHand := range opponentRanges[rangeIdx] {
+					if opponentHand.Weight <= 0 || HasCardDuplicates(append(hands, opponentHand.Cards, board, deadCards)...) {
+						continue
+					}
+					hands = append(hands, opponentHand.Cards)
+					enumerate(rangeIdx+1, weight*opponentHand.Weight)
+					hands = hands[:len(hands)-1]
+				}
+			}
+			enumerate(1, first.Weight)
+
+			// 途中で中断されたハンドは組み合わせが欠けるため集計しない
+			if ctx.Err() != nil {
+				return
+			}
+
+			mu.Lock()
+			for i := range shares {
+				shares[i] += localShares[i]
+			}
+			totalWeight += localWeight
+			mu.Unlock()
+			tracker.advance(1)
+		}(firstHand)
+	}
+
+	wg.Wait()
+
+	if totalWeight == 0 {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		return nil, fmt.Errorf("no valid equity calculations")
+	}
+	for i := range shares {
+		shares[i] /= totalWeight
+	}
+	return shares, ctx.Err()
+}
+
+// CalculateHandVsRangesEquityMonteCarlo はヒーローのハンドと複数の相手レンジのマルチウェイエクイティを
+// モンテカルロシミュレーションで計算します。各イテレーションで各レンジから頻度に比例して
+// カード重複のないハンドを選び、ランアウトをランダムに配ります
+// 結果はインデックス0がヒーロー、i+1がopponentRanges[i]のエクイティ（%）です
+// rngはCalculateMultiwayEquityMonteCarloと同様です
+func CalculateHandVsRangesEquityMonteCarlo(yourHand []poker.Card, opponentRanges []WeightedRange, board []poker.Card, iterations int, rng *rand.Rand) ([]float64, error) {
+	return CalculateHandVsRangesEquityMonteCarloContext(context.Background(), yourHand, opponentRanges, board, nil, iterations, rng, nil)
+}
+
+// CalculateHandVsRangesEquityMonteCarloWithDeadCards はデッドカードを考慮したCalculateHandVsRangesEquityMonteCarloです
+// デッドカードの扱いはCalculateHandVsRangesEquityWithDeadCardsと同じです
+func CalculateHandVsRangesEquityMonteCarloWithDeadCards(yourHand []poker.Card, opponentRanges []WeightedRange, board []poker.Card, deadCards []poker.Card, iterations int, rng *rand.Rand) ([]float64, error) {
+	return CalculateHandVsRangesEquityMonteCarloContext(context.Background(), yourHand, opponentRanges, board, deadCards, iterations, rng, nil)
+}
+
+// contextCheckInterval はモンテカルロのイテレーションでctxの中断と進捗を確認する間隔です
+const contextCheckInterval = 1000
+
+// CalculateHandVsRangesEquityMonteCarloContext はctxのキャンセル・期限に対応したCalculateHandVsRangesEquityMonteCarloです
+// deadCards（nil可）の扱いはCalculateHandVsRangesEquityWithDeadCardsと同じです
+// contextCheckInterval回のイテレーションごとにprogress（nil可）に進捗（イテレーション数）を通知します
+// ctxが中断された場合はそれまでのイテレーションで集計したエクイティとctx.Err()を返します
+func CalculateHandVsRangesEquityMonteCarloContext(ctx context.Context, yourHand []poker.Card, opponentRanges []WeightedRange, board []poker.Card, deadCards []poker.Card, iterations int, rng *rand.Rand, progress ProgressFunc) ([]float64, error) {
+	if len(opponentRanges) == 0 {
+		return nil, fmt.Errorf("at least 1 opponent range is required")
+	}
+	if err := ValidateBoardSize(board); err != nil {
+		return nil, err
+	}
+	if HasCardDuplicates(yourHand, board, deadCards) {
+		return nil, fmt.Errorf("duplicate cards detected")
+	}
+	if iterations <= 0 {
+		return nil, fmt.Errorf("iterations must be positive, got %d", iterations)
+	}
+	variant, err := detectRangeVariant(yourHand, opponentRanges...)
+	if err != nil {
+		return nil, err
+	}
+
+	// 各レンジの累積頻度テーブルを作成（頻度に比例したサンプリング用）
+	samplers := make([]*rangeSampler, len(opponentRanges))
+	for i, opponentRange := range opponentRanges {
+		sampler, err := newRangeSampler(opponentRange, yourHand, board, deadCards)
+		if err != nil {
+			return nil, fmt.Errorf("opponent range %d: %v", i+1, err)
+		}
+		samplers[i] = sampler
+	}
+
+	// 乱数生成器を初期化（nilの場合は現在時刻をシードとする）
+	rng = randOrDefault(rng)
+
+	shares := make([]float64, len(opponentRanges)+1)
+	finalBoard := make([]poker.Card, len(board)+CardsToComplete(board))
+	copy(finalBoard, board)
+	hands := make([][]poker.Card, len(opponentRanges)+1)
+	hands[0] = yourHand
+	completed := 0
+	tracker := newProgressTracker(progress, iterations)
+
+	for i := 0; i < iterations; i++ {
+		if i%contextCheckInterval == 0 {
+			if ctx.Err() != nil {
+				break
+			}
+			if i > 0 {
+				tracker.advance(contextCheckInterval)
+			}
+		}
+		if !sampleOpponentHands(rng, samplers, hands) {
+			continue
+		}
+
+		remainingDeck := RemainingDeck(append(hands, board, deadCards)...)
+		if len(remainingDeck) < CardsToComplete(board) {
+			continue
+		}
+		dealRandomRunout(rng, len(board), remainingDeck, finalBoard)
+		addShowdownShares(shares, judgeWinners(variant, hands, finalBoard), 1)
+		completed++
+	}
+
+	if ctx.Err() == nil {
+		tracker.advance(iterations - tracker.done)
+	}
+
+	if completed == 0 {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		return nil, fmt.Errorf("no valid equity calculations")
+	}
+	for i := range shares {
+		shares[i] = shares[i] / float64(completed) * 100
+	}
+	return shares, ctx.Err()
+}
+
+// rangeSampler はレンジから頻度に比例してハンドをサンプリングします
+type rangeSampler struct {
+	hands      [][]poker.Card
+	cumWeights []float64
+}
+
+// newRangeSampler はusedCards（ヒーローのハンド・ボード・デッドカードなど）と重複しないハンドのみでサンプラーを作成します
+func newRangeSampler(weightedRange WeightedRange, usedCards ...[]poker.Card) (*rangeSampler, error) {
+	sampler := &rangeSampler{}
+	total := 0.0
+	for _, weightedHand := range weightedRange {
+		if weightedHand.Weight <= 0 || HasCardDuplicates(append(usedCards, weightedHand.Cards)...) {
+			continue
+		}
+		total += weightedHand.Weight
+		sampler.hands = append(sampler.hands, weightedHand.Cards)
+		sampler.cumWeights = append(sampler.cumWeights, total)
+	}
+	if len(sampler.hands) == 0 {
+		return nil, fmt.Errorf("no valid opponent hands")
+	}
+	return sampler, nil
+}
+
+// sample は頻度に比例してハンドを1つ選びます
+func (s *rangeSampler) sample(rng *rand.Rand) []poker.Card {
+	target := rng.Float64() * s.cumWeights[len(s.cumWeights)-1]
+	idx := sort.SearchFloat64s(s.cumWeights, target)
+	if idx >= len(s.hands) {
+		idx = len(s.hands) - 1
+	}
+	return s.hands[idx]
+}
+
+// sampleOpponentHands は各レンジからカード重複のないハンドを選び、hands[1:]に格納します
+// hands[0]にはヒーローのハンドが入っている必要があります
+func sampleOpponentHands(rng *rand.Rand, samplers []*rangeSampler, hands [][]poker.Card) bool {
+	for attempt := 0; attempt < maxRangeSampleAttempts; attempt++ {
+		valid := true
+		for i, sampler := range samplers {
+			hands[i+1] = sampler.sample(rng)
+			if HasCardDuplicates(hands[:i+2]...) {
+				valid = false
+				break
+			}
+		}
+		if valid {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,301 @@
+package poker
+
+import (
+	"math"
+	"testing"
+
+	"github.com/chehsunliu/poker"
+)
+
+func TestJudgeWinners(t *testing.T) {
+	// テストケース1: 3人中1人が勝つ場合
+	t.Run("Single winner", func(t *testing.T) {
+		hands := [][]poker.Card{
+			{poker.NewCard("Ah"), poker.NewCard("Ad")},
+			{poker.NewCard("Kh"), poker.NewCard("Kd")},
+			{poker.NewCard("Qh"), poker.NewCard("Qd")},
+		}
+		board := []poker.Card{
+			poker.NewCard("2c"),
+			poker.NewCard("7s"),
+			poker.NewCard("9d"),
+			poker.NewCard("Tc"),
+			poker.NewCard("3s"),
+		}
+
+		winners, err := JudgeWinners(hands, board)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if len(winners) != 1 || winners[0] != 0 {
+			t.Errorf("Expected player 0 to win, got %v", winners)
+		}
+	})
+
+	// テストケース2: ボードプレイで3人が引き分ける場合
+	t.Run("Three-way tie", func(t *testing.T) {
+		hands := [][]poker.Card{
+			{poker.NewCard("2h"), poker.NewCard("3d")},
+			{poker.NewCard("2c"), poker.NewCard("3h")},
+			{poker.NewCard("2d"), poker.NewCard("3c")},
+		}
+		board := []poker.Card{
+			poker.NewCard("As"),
+			poker.NewCard("Ks"),
+			poker.NewCard("Qs"),
+			poker.NewCard("Js"),
+			poker.NewCard("Ts"),
+		}
+
+		winners, err := JudgeWinners(hands, board)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if len(winners) != 3 {
+			t.Errorf("Expected 3-way tie, got %v", winners)
+		}
+	})
+
+	// テストケース3: PLOでは2枚ルールを守る
+	t.Run("PLO must use exactly 2 hole cards", func(t *testing.T) {
+		hands := [][]poker.Card{
+			{poker.NewCard("Ah"), poker.NewCard("2c"), poker.NewCard("3d"), poker.NewCard("4s")}, // ハート1枚のみ
+			{poker.NewCard("Kd"), poker.NewCard("Kc"), poker.NewCard("5c"), poker.NewCard("6d")}, // Kのセット
+			{poker.NewCard("Qd"), poker.NewCard("Qc"), poker.NewCard("7c"), poker.NewCard("8d")},
+		}
+		board := []poker.Card{
+			poker.NewCard("Kh"),
+			poker.NewCard("9h"),
+			poker.NewCard("7h"),
+			poker.NewCard("3h"),
+			poker.NewCard("2s"),
+		}
+
+		winners, err := JudgeWinners(hands, board)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if len(winners) != 1 || winners[0] != 1 {
+			t.Errorf("Expected player 1 to win with a set, got %v", winners)
+		}
+	})
+}
+
+func TestCalculateMultiwayEquity(t *testing.T) {
+	// テストケース1: リバーでの3人引き分けはポットを3等分する
+	t.Run("Three-way split on the river", func(t *testing.T) {
+		hands := [][]poker.Card{
+			{poker.NewCard("2h"), poker.NewCard("3d")},
+			{poker.NewCard("2c"), poker.NewCard("3h")},
+			{poker.NewCard("2d"), poker.NewCard("3c")},
+		}
+		board := []poker.Card{
+			poker.NewCard("As"),
+			poker.NewCard("Ks"),
+			poker.NewCard("Qs"),
+			poker.NewCard("Js"),
+			poker.NewCard("Ts"),
+		}
+
+		equities, err := CalculateMultiwayEquity(hands, board)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		for i, equity := range equities {
+			if math.Abs(equity-100.0/3) > 1e-9 {
+				t.Errorf("Expected player %d equity 33.33%%, got %.4f%%", i, equity)
+			}
+		}
+	})
+
+	// テストケース2: PLO 3-wayのエクイティの合計は100%
+	t.Run("PLO three-way equities sum to 100", func(t *testing.T) {
+		hands := [][]poker.Card{
+			{poker.NewCard("Ah"), poker.NewCard("Ad"), poker.NewCard("Kc"), poker.NewCard("Qc")},
+			{poker.NewCard("Kh"), poker.NewCard("Kd"), poker.NewCard("Jc"), poker.NewCard("Tc")},
+			{poker.NewCard("9h"), poker.NewCard("8h"), poker.NewCard("6s"), poker.NewCard("5s")},
+		}
+		board := []poker.Card{
+			poker.NewCard("2c"),
+			poker.NewCard("7d"),
+			poker.NewCard("Ts"),
+		}
+
+		equities, err := CalculateMultiwayEquity(hands, board)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if len(equities) != 3 {
+			t.Fatalf("Expected 3 equities, got %d", len(equities))
+		}
+
+		total := 0.0
+		for _, equity := range equities {
+			total += equity
+		}
+		if math.Abs(total-100) > 1e-9 {
+			t.Errorf("Expected equities to sum to 100%%, got %.4f%%", total)
+		}
+
+		// モンテカルロの結果が全数計算に近いことを確認
+		mcEquities, err := CalculateMultiwayEquityMonteCarlo(hands, board, ACCURATE_ITERATIONS, nil)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		for i := range equities {
+			if math.Abs(equities[i]-mcEquities[i]) > 3 {
+				t.Errorf("Player %d: Monte Carlo %.2f%% differs from exhaustive %.2f%%", i, mcEquities[i], equities[i])
+			}
+		}
+	})
+
+	// テストケース3: 入力エラー
+	t.Run("Invalid input", func(t *testing.T) {
+		board := []poker.Card{
+			poker.NewCard("2c"),
+			poker.NewCard("7d"),
+			poker.NewCard("Ts"),
+		}
+
+		mixed := [][]poker.Card{
+			{poker.NewCard("Ah"), poker.NewCard("Ad")},
+			{poker.NewCard("Kh"), poker.NewCard("Kd"), poker.NewCard("Jc"), poker.NewCard("Tc")},
+		}
+		if _, err := CalculateMultiwayEquity(mixed, board); err == nil {
+			t.Error("Expected error for mixed hand sizes, got nil")
+		}
+
+		duplicated := [][]poker.Card{
+			{poker.NewCard("Ah"), poker.NewCard("Ad")},
+			{poker.NewCard("Ah"), poker.NewCard("Kd")},
+		}
+		if _, err := CalculateMultiwayEquity(duplicated, board); err == nil {
+			t.Error("Expected error for duplicate cards, got nil")
+		}
+
+		single := [][]poker.Card{{poker.NewCard("Ah"), poker.NewCard("Ad")}}
+		if _, err := CalculateMultiwayEquityMonteCarlo(single, board, 100, nil); err == nil {
+			t.Error("Expected error for a single hand, got nil")
+		}
+	})
+
+	// テストケース4: デッドカードはランアウトに配られない
+	t.Run("Dead cards", func(t *testing.T) {
+		hands := [][]poker.Card{cards("As", "Ac"), cards("Ks", "Kc"), cards("Qs", "Qc")}
+		board := cards("2h", "7d", "Th", "3c")
+		deadCards := cards("Kd", "Kh", "Qd", "Qh")
+
+		// KK・QQのアウツがすべてデッドならAAの勝ちが確定する
+		equities, err := CalculateMultiwayEquityWithDeadCards(hands, board, deadCards)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if equities[0] != 100 {
+			t.Errorf("Expected 100%% equity for AA, got %v", equities)
+		}
+		mcEquities, err := CalculateMultiwayEquityMonteCarloWithDeadCards(hands, board, deadCards, FAST_ITERATIONS, NewRand(1))
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if mcEquities[0] != 100 {
+			t.Errorf("Expected 100%% Monte Carlo equity for AA, got %v", mcEquities)
+		}
+
+		if _, err := CalculateMultiwayEquityWithDeadCards(hands, board, cards("Ks")); err == nil {
+			t.Error("Expected error for a dead card in a hand, got nil")
+		}
+	})
+}
+
+func TestCalculateHandVsRangesEquity(t *testing.T) {
+	yourHand := []poker.Card{
+		poker.NewCard("Ah"),
+		poker.NewCard("Ad"),
+		poker.NewCard("Kc"),
+		poker.NewCard("Qc"),
+	}
+	rangeA := WeightedRange{
+		{Cards: []poker.Card{poker.NewCard("Kh"), poker.NewCard("Kd"), poker.NewCard("Jc"), poker.NewCard("Tc")}, Weight: 1.0},
+		{Cards: []poker.Card{poker.NewCard("Qh"), poker.NewCard("Qd"), poker.NewCard("Jd"), poker.NewCard("Td")}, Weight: 0.5},
+	}
+	rangeB := WeightedRange{
+		{Cards: []poker.Card{poker.NewCard("9h"), poker.NewCard("8h"), poker.NewCard("6s"), poker.NewCard("5s")}, Weight: 1.0},
+		{Cards: []poker.Card{poker.NewCard("Kd"), poker.NewCard("Ks"), poker.NewCard("9c"), poker.NewCard("8c")}, Weight: 1.0}, // rangeAの1ハンドと重複
+	}
+	board := []poker.Card{
+		poker.NewCard("2c"),
+		poker.NewCard("7d"),
+		poker.NewCard("Ts"),
+		poker.NewCard("3h"),
+	}
+
+	// テストケース1: 全数計算とモンテカルロが一致する
+	t.Run("Exhaustive and Monte Carlo agree", func(t *testing.T) {
+		equities, err := CalculateHandVsRangesEquity(yourHand, []WeightedRange{rangeA, rangeB}, board)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if len(equities) != 3 {
+			t.Fatalf("Expected 3 equities, got %d", len(equities))
+		}
+
+		total := 0.0
+		for _, equity := range equities {
+			total += equity
+		}
+		if math.Abs(total-100) > 1e-9 {
+			t.Errorf("Expected equities to sum to 100%%, got %.4f%%", total)
+		}
+
+		mcEquities, err := CalculateHandVsRangesEquityMonteCarlo(yourHand, []WeightedRange{rangeA, rangeB}, board, ACCURATE_ITERATIONS, nil)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		for i := range equities {
+			if math.Abs(equities[i]-mcEquities[i]) > 3 {
+				t.Errorf("Player %d: Monte Carlo %.2f%% differs from exhaustive %.2f%%", i, mcEquities[i], equities[i])
+			}
+		}
+	})
+
+	// テストケース2: 相手レンジがない場合
+	t.Run("No opponent ranges", func(t *testing.T) {
+		if _, err := CalculateHandVsRangesEquity(yourHand, nil, board); err == nil {
+			t.Error("Expected error for no opponent ranges, got nil")
+		}
+		if _, err := CalculateHandVsRangesEquityMonteCarlo(yourHand, nil, board, 100, nil); err == nil {
+			t.Error("Expected error for no opponent ranges, got nil")
+		}
+	})
+
+	// テストケース3: デッドカードを含む相手ハンドは除外され、ランアウトにも配られない
+	t.Run("Dead cards", func(t *testing.T) {
+		yourHand := cards("As", "Ac")
+		opponentRanges := []WeightedRange{
+			NewUniformRange([][]poker.Card{cards("Ks", "Kc"), cards("Kd", "Kh")}),
+			NewUniformRange([][]poker.Card{cards("Qs", "Qc"), cards("Qd", "Qh")}),
+		}
+		board := cards("2h", "7d", "Th", "3c")
+		deadCards := cards("Kd", "Kh", "Qd", "Qh")
+
+		// 残るKsKc・QsQcのアウツはすべてデッド
+		equities, err := CalculateHandVsRangesEquityWithDeadCards(yourHand, opponentRanges, board, deadCards)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if equities[0] != 100 {
+			t.Errorf("Expected 100%% equity for AA, got %v", equities)
+		}
+		mcEquities, err := CalculateHandVsRangesEquityMonteCarloWithDeadCards(yourHand, opponentRanges, board, deadCards, FAST_ITERATIONS, NewRand(1))
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if mcEquities[0] != 100 {
+			t.Errorf("Expected 100%% Monte Carlo equity for AA, got %v", mcEquities)
+		}
+
+		if _, err := CalculateHandVsRangesEquityWithDeadCards(yourHand, opponentRanges, board, cards("As")); err == nil {
+			t.Error("Expected error for a dead card in the hero hand, got nil")
+		}
+	})
+}
@@ -0,0 +1,111 @@
+package poker
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/chehsunliu/poker"
+)
+
+// Hand はプレイヤーのハンド（ホールカード）を表します
+type Hand []poker.Card
+
+// String はハンドの正規化された文字列（"AsKd"形式、ランクは大文字・スートは小文字）を返します
+func (h Hand) String() string {
+	return GenerateBoardString(h)
+}
+
+// Board はボードのカードを表します
+type Board []poker.Card
+
+// String はボードの正規化された文字列（"2d3cJc"形式）を返します
+func (b Board) String() string {
+	return GenerateBoardString(b)
+}
+
+// cardSeparators はカードの区切りとして読み飛ばす文字です
+const cardSeparators = " \t\r\n,"
+
+// ParseCard は1枚のカードを解析します
+// "As", "as", "AS", "10s", "Ts" のような表記を受け付けます
+func ParseCard(s string) (poker.Card, error) {
+	cards, err := parseCards(s)
+	if err != nil {
+		return 0, err
+	}
+	if len(cards) != 1 {
+		return 0, fmt.Errorf("expected 1 card, got %d: %q", len(cards), s)
+	}
+	return cards[0], nil
+}
+
+// ParseHand はハンドの文字列を解析します
+// "AsKdQcJc", "As Kd Qc Jc", "as,kd,qc,jc", "10sKd" のような表記を受け付け、
+// 不正なカード・重複したカード・未対応の枚数の場合はエラーを返します
+func ParseHand(s string) (Hand, error) {
+	cards, err := parseCards(s)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := VariantForHandSize(len(cards)); err != nil {
+		return nil, fmt.Errorf("invalid hand %q: %v", s, err)
+	}
+	if HasCardDuplicates(cards) {
+		return nil, fmt.Errorf("invalid hand %q: duplicate cards", s)
+	}
+	return Hand(cards), nil
+}
+
+// ParseBoard はボードの文字列を解析します
+// 表記はParseHandと同じで、空文字列はプリフロップ（0枚）として扱います
+// 不正なカード・重複したカード・0/3/4/5枚以外の場合はエラーを返します
+func ParseBoard(s string) (Board, error) {
+	cards, err := parseCards(s)
+	if err != nil {
+		return nil, err
+	}
+	if err := ValidateBoardSize(cards); err != nil {
+		return nil, fmt.Errorf("invalid board %q: %v", s, err)
+	}
+	if HasCardDuplicates(cards) {
+		return nil, fmt.Errorf("invalid board %q: duplicate cards", s)
+	}
+	return Board(cards), nil
+}
+
+// parseCards は区切り文字の有無にかかわらずカードの並びを解析します
+func parseCards(s string) ([]poker.Card, error) {
+	cards := []poker.Card{}
+	for i := 0; i < len(s); {
+		if strings.IndexByte(cardSeparators, s[i]) >= 0 {
+			i++
+			continue
+		}
+
+		// ランク（"10"はTとして扱う）
+		var rank string
+		if strings.HasPrefix(s[i:], "10") {
+			rank = "T"
+			i += 2
+		} else {
+			rank = strings.ToUpper(s[i : i+1])
+			i++
+		}
+		if !strings.Contains("23456789TJQKA", rank) {
+			return nil, fmt.Errorf("invalid card rank %q in %q", rank, s)
+		}
+
+		// スート
+		if i >= len(s) {
+			return nil, fmt.Errorf("missing suit for rank %q in %q", rank, s)
+		}
+		suit := strings.ToLower(s[i : i+1])
+		i++
+		if !strings.Contains("shdc", suit) {
+			return nil, fmt.Errorf("invalid card suit %q in %q", suit, s)
+		}
+
+		cards = append(cards, poker.NewCard(rank+suit))
+	}
+	return cards, nil
+}
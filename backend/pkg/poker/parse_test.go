@@ -0,0 +1,115 @@
+package poker
+
+import (
+	"testing"
+
+	"github.com/chehsunliu/poker"
+)
+
+func TestParseCard(t *testing.T) {
+	// テストケース1: 様々な表記を受け付ける
+	t.Run("Valid notations", func(t *testing.T) {
+		testCases := []struct {
+			input    string
+			expected string
+		}{
+			{"As", "As"},
+			{"as", "As"},
+			{"AS", "As"},
+			{"10s", "Ts"},
+			{"Td", "Td"},
+			{" 2c ", "2c"},
+		}
+
+		for _, tc := range testCases {
+			card, err := ParseCard(tc.input)
+			if err != nil {
+				t.Errorf("Expected no error for %q, got %v", tc.input, err)
+				continue
+			}
+			if card != poker.NewCard(tc.expected) {
+				t.Errorf("For %q, expected %s, got %s", tc.input, tc.expected, card.String())
+			}
+		}
+	})
+
+	// テストケース2: 不正な表記はエラー
+	t.Run("Invalid notations", func(t *testing.T) {
+		for _, input := range []string{"", "A", "Xs", "Ax", "1s", "AsKd"} {
+			if _, err := ParseCard(input); err == nil {
+				t.Errorf("Expected error for %q", input)
+			}
+		}
+	})
+}
+
+func TestParseHand(t *testing.T) {
+	// テストケース1: 区切り文字・大文字小文字・"10"表記を正規化する
+	t.Run("Canonical strings", func(t *testing.T) {
+		testCases := []struct {
+			input    string
+			expected string
+		}{
+			{"AsKd", "AsKd"},
+			{"As Kd", "AsKd"},
+			{"ACADAH2C", "AcAdAh2c"},
+			{"as,kd,qc,jc", "AsKdQcJc"},
+			{"10sKd9h8h7c", "TsKd9h8h7c"},
+		}
+
+		for _, tc := range testCases {
+			hand, err := ParseHand(tc.input)
+			if err != nil {
+				t.Errorf("Expected no error for %q, got %v", tc.input, err)
+				continue
+			}
+			if hand.String() != tc.expected {
+				t.Errorf("For %q, expected %s, got %s", tc.input, tc.expected, hand.String())
+			}
+		}
+	})
+
+	// テストケース2: 不正なカード・重複・未対応の枚数はエラー
+	t.Run("Invalid hands", func(t *testing.T) {
+		for _, input := range []string{"", "AsAs", "AsKdQc", "AsKdQcJcTc9c8c", "AsKx", "AsK"} {
+			if _, err := ParseHand(input); err == nil {
+				t.Errorf("Expected error for %q", input)
+			}
+		}
+	})
+}
+
+func TestParseBoard(t *testing.T) {
+	// テストケース1: 0/3/4/5枚のボード
+	t.Run("Valid boards", func(t *testing.T) {
+		testCases := []struct {
+			input    string
+			expected string
+		}{
+			{"", ""},
+			{"2d3cJc", "2d3cJc"},
+			{"2D 3C JC 10h", "2d3cJcTh"},
+			{"2d3cJcTh9s", "2d3cJcTh9s"},
+		}
+
+		for _, tc := range testCases {
+			board, err := ParseBoard(tc.input)
+			if err != nil {
+				t.Errorf("Expected no error for %q, got %v", tc.input, err)
+				continue
+			}
+			if board.String() != tc.expected {
+				t.Errorf("For %q, expected %s, got %s", tc.input, tc.expected, board.String())
+			}
+		}
+	})
+
+	// テストケース2: 不正な枚数・重複はエラー
+	t.Run("Invalid boards", func(t *testing.T) {
+		for _, input := range []string{"2d3c", "2d3cJcTh9s8s", "2d2dJc", "2d3cZc"} {
+			if _, err := ParseBoard(input); err == nil {
+				t.Errorf("Expected error for %q", input)
+			}
+		}
+	})
+}
@@ -0,0 +1,190 @@
+package poker
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math/rand"
+	"runtime"
+	"sync"
+
+	"github.com/chehsunliu/poker"
+)
+
+// PreflopEquityConfig はプリフロップのエクイティ計算（モンテカルロ）の設定です
+// 相手ハンドと5枚のボードを同時にサンプリングし、95%信頼区間の半幅がTargetMargin以下になった時点で終了します
+type PreflopEquityConfig struct {
+	TargetMargin     float64 // 目標とする95%信頼区間の半幅（%）
+	MinIterations    int     // 収束判定を始めるまでの最小イテレーション数
+	MaxIterations    int     // 最大イテレーション数（収束しない場合もここで終了）
+	ConvergenceCheck int     // 収束判定を行う間隔（イテレーション数）
+}
+
+// DefaultPreflopEquityConfig はデフォルトのプリフロップ計算設定を返します（±0.5%）
+func DefaultPreflopEquityConfig() PreflopEquityConfig {
+	return PreflopEquityConfig{
+		TargetMargin:     0.5,
+		MinIterations:    10000,
+		MaxIterations:    200000,
+		ConvergenceCheck: 5000,
+	}
+}
+
+// validate は設定値を検証します
+func (c PreflopEquityConfig) validate() error {
+	if c.MaxIterations <= 0 {
+		return fmt.Errorf("max iterations must be positive, got %d", c.MaxIterations)
+	}
+	if c.ConvergenceCheck <= 0 {
+		return fmt.Errorf("convergence check interval must be positive, got %d", c.ConvergenceCheck)
+	}
+	return nil
+}
+
+// CalculatePreflopEquityVsRange はプリフロップのオールインでの、頻度付きレンジに対するエクイティを計算します
+// 相手ハンドを頻度に比例してサンプリングし、ボードは5枚すべてを配るモンテカルロで計算します
+// 結果のSamplesはイテレーション数で、標準誤差・95%信頼区間が含まれます
+// rngに同じシードの乱数生成器を渡すと同じ結果を返します（nilの場合は現在時刻をシードとします）
+func CalculatePreflopEquityVsRange(yourHand []poker.Card, opponentRange WeightedRange, config PreflopEquityConfig, rng *rand.Rand) (EquityResult, error) {
+	return CalculatePreflopEquityVsRangeContext(context.Background(), yourHand, opponentRange, config, rng)
+}
+
+// CalculatePreflopEquityVsRangeContext はctxのキャンセル・期限に対応したCalculatePreflopEquityVsRangeです
+// ctxは収束判定の間隔ごとに確認し、中断された場合はそれまでの結果（Partial=true）とctx.Err()を返します
+func CalculatePreflopEquityVsRangeContext(ctx context.Context, yourHand []poker.Card, opponentRange WeightedRange, config PreflopEquityConfig, rng *rand.Rand) (EquityResult, error) {
+	if err := config.validate(); err != nil {
+		return EquityResult{}, err
+	}
+	variant, err := detectRangeVariant(yourHand, opponentRange)
+	if err != nil {
+		return EquityResult{}, err
+	}
+	sampler, err := newRangeSampler(opponentRange, yourHand)
+	if err != nil {
+		return EquityResult{}, err
+	}
+
+	// 乱数生成器を初期化（nilの場合は現在時刻をシードとする）
+	rng = randOrDefault(rng)
+
+	// ヒーローのハンドを除いたデッキから、イテレーションごとに相手ハンドのカードを除いてボードを配る
+	heroDeck := RemainingDeck(yourHand)
+	deck := make([]poker.Card, 0, len(heroDeck))
+	board := make([]poker.Card, 5)
+	evaluator := NewShowdownEvaluator(variant, yourHand, nil)
+
+	var outcomes outcomeCounter
+	for i := 1; i <= config.MaxIterations; i++ {
+		opponentHand := sampler.sample(rng)
+		deck = deck[:0]
+		for _, card := range heroDeck {
+			if !containsCard(opponentHand, card) {
+				deck = append(deck, card)
+			}
+		}
+		dealRandomRunout(rng, 0, deck, board)
+
+		evaluator.SetHands(yourHand, opponentHand)
+		outcomes.add(evaluator.JudgeRunout(board))
+
+		// 収束チェック（95%信頼区間の半幅が目標以下になったら終了）
+		if i%config.ConvergenceCheck == 0 {
+			if err := ctx.Err(); err != nil {
+				return outcomes.result(false).asPartial(), err
+			}
+			if i >= config.MinIterations && outcomes.result(false).Margin() <= config.TargetMargin {
+				break
+			}
+		}
+	}
+
+	return outcomes.result(false), nil
+}
+
+// CalculatePreflopEquityTable はhandsの各ハンド（アグレッサーのレンジなど）の、相手レンジに対するプリフロップのエクイティを並列計算します
+// 結果はhandsと同じ順序で返します
+func CalculatePreflopEquityTable(hands [][]poker.Card, opponentRange WeightedRange, config PreflopEquityConfig, rng *rand.Rand) ([]EquityResult, error) {
+	return CalculatePreflopEquityTableContext(context.Background(), hands, opponentRange, config, rng, nil)
+}
+
+// CalculatePreflopEquityTableContext はctxのキャンセル・期限に対応したCalculatePreflopEquityTableです
+// 相手レンジがスートの入れ替えに対して対称な場合は、スートを入れ替えただけのハンドのエクイティが同じになるため、
+// 同値類ごとに1回だけ計算して結果を展開します
+// 各同値類のシードはrngから入力順に生成するため、同じシードのrngからは並列実行でも同じ結果になります
+// 進捗はハンド数でprogress（nil可）に通知し、中断された場合は計算できなかったハンドをSamples=0として結果とctx.Err()を返します
+func CalculatePreflopEquityTableContext(ctx context.Context, hands [][]poker.Card, opponentRange WeightedRange, config PreflopEquityConfig, rng *rand.Rand, progress ProgressFunc) ([]EquityResult, error) {
+	if len(hands) == 0 {
+		return nil, fmt.Errorf("no hands to calculate")
+	}
+	if err := config.validate(); err != nil {
+		return nil, err
+	}
+	handRange := NewUniformRange(hands)
+	if _, err := detectRangeVariant(hands[0], handRange, opponentRange); err != nil {
+		return nil, err
+	}
+
+	// スートを入れ替えただけのハンドを同値類にまとめる（相手レンジが対称でない場合は1ハンドずつ）
+	indices := make([]int, len(hands))
+	for i := range indices {
+		indices[i] = i
+	}
+	var groups [][]int
+	if IsSuitSymmetric(opponentRange) {
+		groups = NewSuitIsomorphism(nil, nil).groupIsomorphicHands(handRange, indices)
+		log.Printf("Suit isomorphism: %d hands reduced to %d unique preflop matchups", len(hands), len(groups))
+	} else {
+		for _, i := range indices {
+			groups = append(groups, []int{i})
+		}
+	}
+
+	results := make([]EquityResult, len(hands))
+	seeds := deriveSeeds(randOrDefault(rng), len(groups))
+	var firstErr error
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+
+	semaphore := make(chan struct{}, runtime.NumCPU())
+	tracker := newProgressTracker(progress, len(hands))
+
+	for g, group := range groups {
+		if !acquireSlot(ctx, semaphore) {
+			break
+		}
+		wg.Add(1)
+
+		go func(groupIdx int, handIndices []int) {
+			defer wg.Done()
+			defer func() { <-semaphore }()
+
+			// 代表ハンドのみ計算し、同値類の全ハンドに結果を展開する（各ゴルーチンは異なるインデックスにのみ書き込む）
+			groupRng := rand.New(rand.NewSource(seeds[groupIdx]))
+			result, err := CalculatePreflopEquityVsRangeContext(ctx, hands[handIndices[0]], opponentRange, config, groupRng)
+			if err != nil {
+				if ctx.Err() == nil {
+					mu.Lock()
+					if firstErr == nil {
+						firstErr = fmt.Errorf("%s: %v", GenerateBoardString(hands[handIndices[0]]), err)
+					}
+					mu.Unlock()
+				}
+			} else {
+				for _, handIdx := range handIndices {
+					results[handIdx] = result
+				}
+			}
+			tracker.advance(len(handIndices))
+		}(g, group)
+	}
+
+	wg.Wait()
+
+	if err := ctx.Err(); err != nil {
+		return results, err
+	}
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	return results, nil
+}
@@ -0,0 +1,111 @@
+package poker
+
+import (
+	"testing"
+
+	"github.com/chehsunliu/poker"
+)
+
+func TestCalculatePreflopEquityVsRange(t *testing.T) {
+	yourHand := cards("As", "Ad", "Kh", "Kc")
+	opponentRange := WeightedRange{
+		{Cards: cards("7s", "6d", "3h", "2c"), Weight: 1.0},
+		{Cards: cards("8s", "7d", "4h", "2h"), Weight: 0.5},
+		{Cards: cards("As", "9d", "5h", "2d"), Weight: 1.0}, // ヒーローと重複するため除外
+	}
+	config := PreflopEquityConfig{TargetMargin: 1.0, MinIterations: 2000, MaxIterations: 20000, ConvergenceCheck: 1000}
+
+	// テストケース1: 信頼区間が目標以下になった時点で終了する
+	t.Run("Converges to target margin", func(t *testing.T) {
+		result, err := CalculatePreflopEquityVsRange(yourHand, opponentRange, config, NewRand(1))
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if result.Samples > config.MaxIterations || result.Samples < config.MinIterations || result.Exhaustive {
+			t.Errorf("Expected between %d and %d samples, got %+v", config.MinIterations, config.MaxIterations, result)
+		}
+		if result.Samples < config.MaxIterations && result.Margin() > config.TargetMargin {
+			t.Errorf("Expected margin <= %.2f%%, got %.2f%%", config.TargetMargin, result.Margin())
+		}
+		// AAKKは低いラグに対して大きく有利
+		if result.Equity < 60 || result.Equity > 90 {
+			t.Errorf("Expected equity between 60%% and 90%%, got %.2f%%", result.Equity)
+		}
+
+		again, _ := CalculatePreflopEquityVsRange(yourHand, opponentRange, config, NewRand(1))
+		if again != result {
+			t.Errorf("Expected identical results for the same seed, got %+v and %+v", result, again)
+		}
+	})
+
+	// テストケース2: 不正な設定・ゲームタイプが混在する場合はエラー
+	t.Run("Invalid input", func(t *testing.T) {
+		if _, err := CalculatePreflopEquityVsRange(yourHand, opponentRange, PreflopEquityConfig{}, nil); err == nil {
+			t.Error("Expected error for an empty config, got nil")
+		}
+		mixedRange := WeightedRange{{Cards: cards("7s", "6d", "3h", "2c", "9c"), Weight: 1.0}}
+		if _, err := CalculatePreflopEquityVsRange(yourHand, mixedRange, config, nil); err == nil {
+			t.Error("Expected error for a PLO5 range against a PLO4 hand, got nil")
+		}
+	})
+}
+
+func TestCalculatePreflopEquityTable(t *testing.T) {
+	config := PreflopEquityConfig{TargetMargin: 2.0, MinIterations: 1000, MaxIterations: 5000, ConvergenceCheck: 1000}
+	hands := [][]poker.Card{
+		cards("As", "Ad", "Kh", "Kc"),
+		cards("Ah", "Ac", "Ks", "Kd"), // 1つ目とスートを入れ替えただけのハンド
+		cards("Js", "Ts", "9h", "8h"),
+	}
+
+	// テストケース1: スート対称なレンジでは、スートを入れ替えただけのハンドは同じ結果になる
+	t.Run("Symmetric range", func(t *testing.T) {
+		// QQ23のダブルスーテッド（2と3のスートの組み合わせをすべて含む）
+		var opponentHands [][]poker.Card
+		for _, x := range []string{"s", "h", "d", "c"} {
+			for _, y := range []string{"s", "h", "d", "c"} {
+				if x < y {
+					opponentHands = append(opponentHands, cards("Q"+x, "Q"+y, "2"+x, "3"+y), cards("Q"+x, "Q"+y, "2"+y, "3"+x))
+				}
+			}
+		}
+		opponentRange := NewUniformRange(opponentHands)
+		if !IsSuitSymmetric(opponentRange) {
+			t.Fatal("Expected the range to be suit-symmetric")
+		}
+
+		results, err := CalculatePreflopEquityTable(hands, opponentRange, config, NewRand(1))
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if len(results) != len(hands) {
+			t.Fatalf("Expected %d results, got %d", len(hands), len(results))
+		}
+		if results[0] != results[1] {
+			t.Errorf("Expected isomorphic hands to share a result, got %+v and %+v", results[0], results[1])
+		}
+		for i, result := range results {
+			if result.Samples == 0 || result.Equity <= 0 || result.Equity >= 100 {
+				t.Errorf("Hand %d: expected a calculated equity, got %+v", i, result)
+			}
+		}
+
+		again, _ := CalculatePreflopEquityTable(hands, opponentRange, config, NewRand(1))
+		for i := range results {
+			if again[i] != results[i] {
+				t.Errorf("Hand %d: expected identical results for the same seed, got %+v and %+v", i, results[i], again[i])
+			}
+		}
+	})
+
+	// テストケース2: スートが偏ったレンジは対称ではない
+	t.Run("Asymmetric range", func(t *testing.T) {
+		opponentRange := WeightedRange{{Cards: cards("Qs", "Qd", "2s", "3d"), Weight: 1.0}}
+		if IsSuitSymmetric(opponentRange) {
+			t.Error("Expected the range not to be suit-symmetric")
+		}
+		if _, err := CalculatePreflopEquityTable(nil, opponentRange, config, nil); err == nil {
+			t.Error("Expected error for no hands, got nil")
+		}
+	})
+}
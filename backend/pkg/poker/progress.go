@@ -0,0 +1,72 @@
+package poker
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Progress はレンジ計算の進捗を表します
+type Progress struct {
+	Done    int           // 完了した処理単位の数（ハンド数、マルチウェイのモンテカルロではイテレーション数）
+	Total   int           // 処理単位の総数
+	Elapsed time.Duration // 計算開始からの経過時間
+	ETA     time.Duration // 残り時間の推定値（Doneが0の場合は0）
+}
+
+// Percent は進捗率（0〜100）を返します
+func (p Progress) Percent() float64 {
+	if p.Total == 0 {
+		return 100
+	}
+	return float64(p.Done) / float64(p.Total) * 100
+}
+
+// ProgressFunc はレンジ計算の進捗を受け取るコールバックです
+// 呼び出しは直列化されるため、コールバック側で排他制御をする必要はありません
+// 処理単位ごとに呼び出されるため、ログ出力などはコールバック側で間引いてください
+type ProgressFunc func(Progress)
+
+// progressTracker は並列計算の完了数を数え、ProgressFuncに通知します
+type progressTracker struct {
+	mu    sync.Mutex
+	fn    ProgressFunc
+	total int
+	done  int
+	start time.Time
+}
+
+// newProgressTracker はtotal個の処理単位の進捗を通知するトラッカーを作成します（fnはnilでも構いません）
+func newProgressTracker(fn ProgressFunc, total int) *progressTracker {
+	return &progressTracker{fn: fn, total: total, start: time.Now()}
+}
+
+// advance は完了数をn増やし、コールバックに進捗を通知します
+func (p *progressTracker) advance(n int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.done += n
+	if p.fn == nil {
+		return
+	}
+	elapsed := time.Since(p.start)
+	var eta time.Duration
+	if p.done > 0 && p.done < p.total {
+		eta = time.Duration(float64(elapsed) / float64(p.done) * float64(p.total-p.done))
+	}
+	p.fn(Progress{Done: p.done, Total: p.total, Elapsed: elapsed, ETA: eta})
+}
+
+// acquireSlot はセマフォの空きを待ちます。空きを待つ間にctxがキャンセルされた場合はfalseを返します
+func acquireSlot(ctx context.Context, semaphore chan struct{}) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+	select {
+	case semaphore <- struct{}{}:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
@@ -0,0 +1,110 @@
+package poker
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/chehsunliu/poker"
+)
+
+// allHoldemHands はusedCardsと重複しない全てのNLHEハンドを頻度1.0のレンジとして返します
+func allHoldemHands(usedCards ...[]poker.Card) WeightedRange {
+	deck := RemainingDeck(usedCards...)
+	var hands [][]poker.Card
+	for i := 0; i < len(deck); i++ {
+		for j := i + 1; j < len(deck); j++ {
+			hands = append(hands, []poker.Card{deck[i], deck[j]})
+		}
+	}
+	return NewUniformRange(hands)
+}
+
+func TestRangeCalculationContext(t *testing.T) {
+	yourHand := cards("As", "Ks")
+	board := cards("2h", "7d", "Tc")
+	fullRange := allHoldemHands(yourHand, board)
+	smallRange := fullRange[:20]
+
+	// テストケース1: 進捗は完了したハンド数を全ハンド数まで通知する
+	t.Run("Progress reaches total", func(t *testing.T) {
+		var updates []Progress
+		_, result, err := CalculateHandVsWeightedRangeEquityParallelContext(context.Background(), yourHand, smallRange, board, nil, func(p Progress) {
+			updates = append(updates, p)
+		})
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if result.Partial {
+			t.Errorf("Expected a complete result, got Partial=true")
+		}
+		if len(updates) != len(smallRange) {
+			t.Fatalf("Expected %d progress updates, got %d", len(smallRange), len(updates))
+		}
+		for i, p := range updates {
+			if p.Done != i+1 || p.Total != len(smallRange) {
+				t.Errorf("Update %d: expected %d/%d, got %d/%d", i, i+1, len(smallRange), p.Done, p.Total)
+			}
+		}
+		if last := updates[len(updates)-1]; last.ETA != 0 || last.Percent() != 100 {
+			t.Errorf("Expected final update with no ETA at 100%%, got %+v", last)
+		}
+	})
+
+	// テストケース2: 既にキャンセルされたctxでは計算せずにエラーを返す
+	t.Run("Cancelled before start", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		equities, _, err := CalculateHandVsWeightedRangeEquityParallelContext(ctx, yourHand, fullRange, board, nil, nil)
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("Expected context.Canceled, got %v", err)
+		}
+		if equities != nil {
+			t.Errorf("Expected no equities, got %d", len(equities))
+		}
+
+		_, err = CalculateHandVsRangesEquityMonteCarloContext(ctx, yourHand, []WeightedRange{fullRange}, board, nil, 10000, NewRand(1), nil)
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("Multiway: expected context.Canceled, got %v", err)
+		}
+	})
+
+	// テストケース3: 途中でキャンセルすると計算済みのハンドのみの部分的な結果を返す
+	t.Run("Cancelled midway returns partial result", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		equities, result, err := CalculateHandVsWeightedRangeEquityParallelContext(ctx, yourHand, fullRange, board, nil, func(p Progress) {
+			cancel()
+		})
+		if !errors.Is(err, context.Canceled) {
+			t.Fatalf("Expected context.Canceled, got %v", err)
+		}
+		if !result.Partial || result.Exhaustive {
+			t.Errorf("Expected a partial, non-exhaustive result, got %+v", result)
+		}
+		if len(equities) == 0 || len(equities) >= len(fullRange) {
+			t.Errorf("Expected a partial set of equities, got %d of %d", len(equities), len(fullRange))
+		}
+	})
+
+	// テストケース4: 期限切れのレンジ同士の計算はPartialの結果かエラーを返す
+	t.Run("Deadline exceeded", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+
+		start := time.Now()
+		result, err := CalculateRangeVsRangeEquityContext(ctx, fullRange, fullRange, board, nil, nil)
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Fatalf("Expected context.DeadlineExceeded, got %v", err)
+		}
+		if result != nil && !result.Partial {
+			t.Errorf("Expected result to be marked as partial")
+		}
+		if elapsed := time.Since(start); elapsed > 5*time.Second {
+			t.Errorf("Expected calculation to stop promptly, took %v", elapsed)
+		}
+	})
+}
@@ -0,0 +1,71 @@
+package poker
+
+import (
+	"math/rand"
+	"time"
+)
+
+// NewRand は指定したシードの乱数生成器を返します
+// 同じシードを渡したモンテカルロ計算・サンプリングは同じ結果を返します
+// seedが0の場合は現在時刻をシードとして使用するため、結果は再現できません
+func NewRand(seed int64) *rand.Rand {
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+	return rand.New(rand.NewSource(seed))
+}
+
+// randOrDefault はrngがnilの場合に現在時刻をシードとした乱数生成器を返します
+func randOrDefault(rng *rand.Rand) *rand.Rand {
+	if rng == nil {
+		return NewRand(0)
+	}
+	return rng
+}
+
+// deriveSeeds は並列計算のゴルーチンごとに使うシードをrngから入力順に生成します
+// ゴルーチンの実行順序に関係なく、同じrngから同じ結果を得るために使用します
+func deriveSeeds(rng *rand.Rand, n int) []int64 {
+	seeds := make([]int64, n)
+	for i := range seeds {
+		seeds[i] = rng.Int63()
+	}
+	return seeds
+}
+
+// indexedResults は並列計算のハンドごとの結果を入力順に保持します
+// ゴルーチンの完了順序に関係なく同じ順序で集計し、浮動小数点の丸めまで結果を再現可能にします
+// 各ゴルーチンは異なるインデックスにのみ書き込むため、ロックは不要です
+type indexedResults struct {
+	results []EquityResult
+	weights []float64
+	valid   []bool
+}
+
+func newIndexedResults(n int) *indexedResults {
+	return &indexedResults{
+		results: make([]EquityResult, n),
+		weights: make([]float64, n),
+		valid:   make([]bool, n),
+	}
+}
+
+// set はi番目のハンドの結果を保存します
+func (r *indexedResults) set(i int, result EquityResult, weight float64) {
+	r.results[i] = result
+	r.weights[i] = weight
+	r.valid[i] = true
+}
+
+// collect は保存された結果と頻度を入力順に返します
+func (r *indexedResults) collect() ([]EquityResult, []float64) {
+	var results []EquityResult
+	var weights []float64
+	for i, ok := range r.valid {
+		if ok {
+			results = append(results, r.results[i])
+			weights = append(weights, r.weights[i])
+		}
+	}
+	return results, weights
+}
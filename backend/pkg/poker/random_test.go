@@ -0,0 +1,74 @@
+package poker
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/chehsunliu/poker"
+)
+
+func TestSeededReproducibility(t *testing.T) {
+	yourHand := cards("As", "Ac", "Kd", "Qd")
+	board := cards("2h", "7d", "Ts")
+	opponentHands := [][]poker.Card{
+		cards("Ks", "Kc", "Jd", "Td"),
+		cards("Qs", "Qc", "9d", "8d"),
+		cards("Js", "Jc", "6h", "5h"),
+		cards("9s", "8s", "6c", "5c"),
+	}
+
+	// テストケース1: 同じシードのハンド同士のモンテカルロは同じ結果になる
+	t.Run("Hand vs hand Monte Carlo", func(t *testing.T) {
+		first, err := CalculateHandVsHandEquityMonteCarlo(yourHand, opponentHands[0], board, FAST_ITERATIONS, NewRand(42))
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		second, _ := CalculateHandVsHandEquityMonteCarlo(yourHand, opponentHands[0], board, FAST_ITERATIONS, NewRand(42))
+		if first != second {
+			t.Errorf("Expected identical results for the same seed, got %+v and %+v", first, second)
+		}
+
+		other, _ := CalculateHandVsHandEquityMonteCarlo(yourHand, opponentHands[0], board, FAST_ITERATIONS, NewRand(43))
+		if first == other {
+			t.Errorf("Expected different results for different seeds, got %+v", other)
+		}
+	})
+
+	// テストケース2: 並列のレンジ計算もゴルーチンの実行順序に関係なく同じ結果になる
+	t.Run("Hand vs range Monte Carlo", func(t *testing.T) {
+		firstEquities, firstResult, err := CalculateHandVsRangeEquityMonteCarloParallel(yourHand, opponentHands, board, "FAST", NewRand(7))
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		secondEquities, secondResult, _ := CalculateHandVsRangeEquityMonteCarloParallel(yourHand, opponentHands, board, "FAST", NewRand(7))
+		if !reflect.DeepEqual(firstEquities, secondEquities) || firstResult != secondResult {
+			t.Errorf("Expected identical results for the same seed, got %+v and %+v", firstResult, secondResult)
+		}
+	})
+
+	// テストケース3: 適応的サンプリングは同じハンドを選ぶ
+	t.Run("Adaptive range sampling", func(t *testing.T) {
+		config := AdaptiveSamplingConfig{MinSamples: 1, MaxSamples: 2, PilotSamples: 1, TargetError: 0.01, ConfidenceZ: ConfidenceZ95}
+		firstEquities, firstResult, err := CalculateHandVsRangeAdaptiveWithDetails(yourHand, opponentHands, board, config, NewRand(99))
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		secondEquities, secondResult, _ := CalculateHandVsRangeAdaptiveWithDetails(yourHand, opponentHands, board, config, NewRand(99))
+		if !reflect.DeepEqual(firstEquities, secondEquities) || firstResult != secondResult {
+			t.Errorf("Expected identical samples for the same seed, got %v and %v", firstEquities, secondEquities)
+		}
+	})
+
+	// テストケース4: マルチウェイのモンテカルロ
+	t.Run("Multiway Monte Carlo", func(t *testing.T) {
+		hands := [][]poker.Card{yourHand, opponentHands[0], opponentHands[2]}
+		first, err := CalculateMultiwayEquityMonteCarlo(hands, board, FAST_ITERATIONS, NewRand(5))
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		second, _ := CalculateMultiwayEquityMonteCarlo(hands, board, FAST_ITERATIONS, NewRand(5))
+		if !reflect.DeepEqual(first, second) {
+			t.Errorf("Expected identical results for the same seed, got %v and %v", first, second)
+		}
+	})
+}
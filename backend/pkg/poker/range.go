@@ -0,0 +1,82 @@
+package poker
+
+import (
+	"fmt"
+	"math/rand"
+
+	"github.com/chehsunliu/poker"
+)
+
+// WeightedHand はレンジ内の1ハンドとその頻度を表します
+type WeightedHand struct {
+	Cards  []poker.Card
+	Weight float64 // 頻度（0〜1。CSVの"@100"は1.0、"@1"は0.01）
+}
+
+// WeightedRange は頻度付きのハンドレンジを表します
+type WeightedRange []WeightedHand
+
+// NewUniformRange は全ハンドの頻度を1.0としたレンジを作成します
+func NewUniformRange(hands [][]poker.Card) WeightedRange {
+	weightedRange := make(WeightedRange, 0, len(hands))
+	for _, hand := range hands {
+		weightedRange = append(weightedRange, WeightedHand{Cards: hand, Weight: 1.0})
+	}
+	return weightedRange
+}
+
+// Hands はレンジ内のハンドのみを返します
+func (r WeightedRange) Hands() [][]poker.Card {
+	hands := make([][]poker.Card, 0, len(r))
+	for _, weightedHand := range r {
+		hands = append(hands, weightedHand.Cards)
+	}
+	return hands
+}
+
+// Sample はレンジから頻度に比例してハンドを1つ選びます（頻度0のハンドは選びません）
+func (r WeightedRange) Sample(rng *rand.Rand) ([]poker.Card, error) {
+	sampler, err := newRangeSampler(r)
+	if err != nil {
+		return nil, fmt.Errorf("no hands with a positive weight in range")
+	}
+	return sampler.sample(randOrDefault(rng)), nil
+}
+
+// Weights はハンド文字列（GenerateBoardString形式）から頻度へのマップを返します
+func (r WeightedRange) Weights() map[string]float64 {
+	weights := make(map[string]float64, len(r))
+	for _, weightedHand := range r {
+		weights[GenerateBoardString(weightedHand.Cards)] = weightedHand.Weight
+	}
+	return weights
+}
+
+// TotalWeight はレンジ全体の頻度の合計を返します
+func (r WeightedRange) TotalWeight() float64 {
+	total := 0.0
+	for _, weightedHand := range r {
+		total += weightedHand.Weight
+	}
+	return total
+}
+
+// WeightedAverageEquity は頻度で重み付けした平均エクイティを計算します
+// weightsに存在しないハンドは頻度1.0として扱います
+func WeightedAverageEquity(equities map[string]float64, weights map[string]float64) float64 {
+	totalEquity := 0.0
+	totalWeight := 0.0
+	for hand, equity := range equities {
+		weight, ok := weights[hand]
+		if !ok {
+			weight = 1.0
+		}
+		totalEquity += equity * weight
+		totalWeight += weight
+	}
+
+	if totalWeight == 0 {
+		return 0
+	}
+	return totalEquity / totalWeight
+}
@@ -0,0 +1,107 @@
+package poker
+
+import (
+	"math"
+	"testing"
+
+	"github.com/chehsunliu/poker"
+)
+
+func TestWeightedRange(t *testing.T) {
+	hands := [][]poker.Card{
+		{poker.NewCard("Ah"), poker.NewCard("Ad"), poker.NewCard("Kc"), poker.NewCard("Qc")},
+		{poker.NewCard("Kh"), poker.NewCard("Kd"), poker.NewCard("Jc"), poker.NewCard("Tc")},
+	}
+
+	// テストケース1: 一様レンジの作成
+	t.Run("Uniform range", func(t *testing.T) {
+		weightedRange := NewUniformRange(hands)
+
+		if len(weightedRange) != 2 {
+			t.Fatalf("Expected 2 hands, got %d", len(weightedRange))
+		}
+		if weightedRange.TotalWeight() != 2.0 {
+			t.Errorf("Expected total weight 2.0, got %.2f", weightedRange.TotalWeight())
+		}
+		if len(weightedRange.Hands()) != 2 {
+			t.Errorf("Expected 2 hands from Hands(), got %d", len(weightedRange.Hands()))
+		}
+	})
+
+	// テストケース2: ハンド文字列から頻度へのマップ
+	t.Run("Weights map", func(t *testing.T) {
+		weightedRange := WeightedRange{
+			{Cards: hands[0], Weight: 1.0},
+			{Cards: hands[1], Weight: 0.25},
+		}
+
+		weights := weightedRange.Weights()
+		if weights["AhAdKcQc"] != 1.0 {
+			t.Errorf("Expected weight 1.0 for AhAdKcQc, got %.2f", weights["AhAdKcQc"])
+		}
+		if weights["KhKdJcTc"] != 0.25 {
+			t.Errorf("Expected weight 0.25 for KhKdJcTc, got %.2f", weights["KhKdJcTc"])
+		}
+	})
+
+	// テストケース3: 頻度に比例してハンドを選ぶ
+	t.Run("Sample by weight", func(t *testing.T) {
+		weightedRange := WeightedRange{
+			{Cards: hands[0], Weight: 1.0},
+			{Cards: hands[1], Weight: 0.01},
+			{Cards: cards("Qh", "Qd", "9c", "8c"), Weight: 0},
+		}
+
+		rng := NewRand(1)
+		counts := make(map[string]int)
+		for i := 0; i < 10000; i++ {
+			hand, err := weightedRange.Sample(rng)
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			counts[GenerateBoardString(hand)]++
+		}
+		if counts["QhQd9c8c"] != 0 {
+			t.Errorf("Expected zero weight hands never to be sampled, got %d", counts["QhQd9c8c"])
+		}
+		// 1.0と0.01の比率（約99%と約1%）
+		if counts["KhKdJcTc"] < 30 || counts["KhKdJcTc"] > 200 {
+			t.Errorf("Expected about 1%% of samples to be KhKdJcTc, got %d of 10000", counts["KhKdJcTc"])
+		}
+
+		if _, err := (WeightedRange{{Cards: hands[0], Weight: 0}}).Sample(rng); err == nil {
+			t.Errorf("Expected an error for a range without positive weights")
+		}
+	})
+}
+
+func TestWeightedAverageEquity(t *testing.T) {
+	// テストケース1: 頻度で重み付けされる
+	t.Run("Weighted by frequency", func(t *testing.T) {
+		equities := map[string]float64{"hand1": 80, "hand2": 20}
+		weights := map[string]float64{"hand1": 1.0, "hand2": 0.01}
+
+		average := WeightedAverageEquity(equities, weights)
+		expected := (80*1.0 + 20*0.01) / 1.01
+		if math.Abs(average-expected) > 1e-9 {
+			t.Errorf("Expected %.4f, got %.4f", expected, average)
+		}
+	})
+
+	// テストケース2: 頻度がないハンドは1.0として扱う
+	t.Run("Missing weight defaults to 1.0", func(t *testing.T) {
+		equities := map[string]float64{"hand1": 80, "hand2": 20}
+
+		average := WeightedAverageEquity(equities, nil)
+		if average != 50 {
+			t.Errorf("Expected 50.00, got %.2f", average)
+		}
+	})
+
+	// テストケース3: 空の結果
+	t.Run("Empty equities", func(t *testing.T) {
+		if average := WeightedAverageEquity(map[string]float64{}, nil); average != 0 {
+			t.Errorf("Expected 0 for empty equities, got %.2f", average)
+		}
+	})
+}
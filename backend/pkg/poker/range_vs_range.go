@@ -0,0 +1,401 @@
+package poker
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"runtime"
+	"sort"
+	"sync"
+
+	"github.com/chehsunliu/poker"
+)
+
+// RangeVsRangeResult はレンジ同士のエクイティ計算結果を表します
+type RangeVsRangeResult struct {
+	HeroEquities        map[string]float64      // ヒーローレンジの各ハンドの相手レンジに対するエクイティ
+	VillainEquities     map[string]float64      // 相手レンジの各ハンドのヒーローレンジに対するエクイティ
+	HeroEquity          float64                 // ヒーローレンジ全体のエクイティ（頻度で重み付け）
+	VillainEquity       float64                 // 相手レンジ全体のエクイティ（頻度で重み付け）
+	HeroDistribution    EquityDistributionCurve // ヒーローレンジのエクイティ分布
+	VillainDistribution EquityDistributionCurve // 相手レンジのエクイティ分布
+	Partial             bool                    // キャンセル・期限切れにより一部のハンドのみで計算した結果か
+}
+
+// EquityDistributionPoint はエクイティ分布曲線上の1点を表します
+type EquityDistributionPoint struct {
+	Hand       string  `json:"hand"`
+	Percentile float64 `json:"percentile"` // このハンド以下のエクイティを持つハンドの頻度の割合（0〜100）
+	Equity     float64 `json:"equity"`
+}
+
+// EquityDistributionCurve はエクイティの昇順に並んだ分布曲線です
+type EquityDistributionCurve []EquityDistributionPoint
+
+// EquityDistribution は各ハンドのエクイティから頻度で重み付けした分布曲線を作成します
+// weightsに存在しないハンドは頻度1.0として扱います
+func EquityDistribution(equities map[string]float64, weights map[string]float64) EquityDistributionCurve {
+	curve := make(EquityDistributionCurve, 0, len(equities))
+	totalWeight := 0.0
+	handWeights := make(map[string]float64, len(equities))
+	for hand, equity := range equities {
+		weight, ok := weights[hand]
+		if !ok {
+			weight = 1.0
+		}
+		handWeights[hand] = weight
+		totalWeight += weight
+		curve = append(curve, EquityDistributionPoint{Hand: hand, Equity: equity})
+	}
+
+	if totalWeight == 0 {
+		return EquityDistributionCurve{}
+	}
+
+	// エクイティの昇順（同じ場合はハンド文字列順）に並べる
+	sort.Slice(curve, func(i, j int) bool {
+		if curve[i].Equity != curve[j].Equity {
+			return curve[i].Equity < curve[j].Equity
+		}
+		return curve[i].Hand < curve[j].Hand
+	})
+
+	cumulative := 0.0
+	for i := range curve {
+		cumulative += handWeights[curve[i].Hand]
+		curve[i].Percentile = cumulative / totalWeight * 100
+	}
+	return curve
+}
+
+// EquityAt は指定したパーセンタイル（0〜100）に位置するハンドのエクイティを返します
+func (c EquityDistributionCurve) EquityAt(percentile float64) float64 {
+	if len(c) == 0 {
+		return 0
+	}
+	idx := sort.Search(len(c), func(i int) bool {
+		return c[i].Percentile >= percentile
+	})
+	if idx >= len(c) {
+		idx = len(c) - 1
+	}
+	return c[idx].Equity
+}
+
+// rangeMatchupTotals はハンドごとのエクイティ集計値を保持します
+type rangeMatchupTotals struct {
+	equitySum map[string]float64
+	weightSum map[string]float64
+}
+
+func newRangeMatchupTotals() *rangeMatchupTotals {
+	return &rangeMatchupTotals{
+		equitySum: make(map[string]float64),
+		weightSum: make(map[string]float64),
+	}
+}
+
+func (t *rangeMatchupTotals) add(hand string, equity float64, weight float64) {
+	t.equitySum[hand] += equity * weight
+	t.weightSum[hand] += weight
+}
+
+func (t *rangeMatchupTotals) merge(other *rangeMatchupTotals) {
+	for hand, sum := range other.equitySum {
+		t.equitySum[hand] += sum
+		t.weightSum[hand] += other.weightSum[hand]
+	}
+}
+
+func (t *rangeMatchupTotals) equities() map[string]float64 {
+	equities := make(map[string]float64, len(t.equitySum))
+	for hand, sum := range t.equitySum {
+		if t.weightSum[hand] > 0 {
+			equities[hand] = sum / t.weightSum[hand]
+		}
+	}
+	return equities
+}
+
+// CalculateRangeVsRangeEquity はレンジ同士のエクイティを全数計算します
+// カードが重複しない全てのハンドの組み合わせについてCalculateHandVsHandEquityを実行し、
+// 各ハンドのエクイティを相手ハンドの頻度で重み付けして集計します
+// 組み合わせ数はレンジサイズの積になるため、大きなレンジではCalculateRangeVsRangeEquityMonteCarloを使用してください
+func CalculateRangeVsRangeEquity(heroRange WeightedRange, villainRange WeightedRange, board []poker.Card) (*RangeVsRangeResult, error) {
+	return CalculateRangeVsRangeEquityContext(context.Background(), heroRange, villainRange, board, nil, nil)
+}
+
+// CalculateRangeVsRangeEquityWithDeadCards はデッドカードを考慮したCalculateRangeVsRangeEquityです
+// デッドカードはランアウトに配られず、デッドカードを含むハンドは両方のレンジから除外されます
+func CalculateRangeVsRangeEquityWithDeadCards(heroRange WeightedRange, villainRange WeightedRange, board []poker.Card, deadCards []poker.Card) (*RangeVsRangeResult, error) {
+	return CalculateRangeVsRangeEquityContext(context.Background(), heroRange, villainRange, board, deadCards, nil)
+}
+
+// CalculateRangeVsRangeEquityContext はctxのキャンセル・期限に対応したCalculateRangeVsRangeEquityです
+// ヒーローレンジのハンドが1つ完了するたびにprogress（nil可）に進捗を通知します
+// deadCards（nil可）の扱いはCalculateRangeVsRangeEquityWithDeadCardsと同じです
+// ctxが中断された場合は完了したヒーローハンドのみで集計した結果（Partial=true）とctx.Err()を返します
+func CalculateRangeVsRangeEquityContext(ctx context.Context, heroRange WeightedRange, villainRange WeightedRange, board []poker.Card, deadCards []poker.Card, progress ProgressFunc) (*RangeVsRangeResult, error) {
+	if err := ValidateBoardSize(board); err != nil {
+		return nil, err
+	}
+	if HasCardDuplicates(board, deadCards) {
+		return nil, fmt.Errorf("duplicate cards detected")
+	}
+	variant, err := detectRangeVsRangeVariant(heroRange, villainRange)
+	if err != nil {
+		return nil, err
+	}
+
+	heroTotals := newRangeMatchupTotals()
+	villainTotals := newRangeMatchupTotals()
+	var matchupEquitySum, matchupWeightSum float64 // 組み合わせ全体の集計（レンジ全体のエクイティ用）
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+
+	numCPU := runtime.NumCPU()
+	semaphore := make(chan struct{}, numCPU) // 同時実行数をCPUコア数に制限
+
+	heroHands := validRangeHands(heroRange, board, deadCards)
+	tracker := newProgressTracker(progress, len(heroHands))
+
+	// ヒーローレンジのハンドごとに並列で計算
+	for _, heroHand := range heroHands {
+		if !acquireSlot(ctx, semaphore) {
+			break
+		}
+		wg.Add(1)
+
+		go func(hero WeightedHand) {
+			defer wg.Done()
+			defer func() { <-semaphore }()
+
+			heroStr := GenerateBoardString(hero.Cards)
+			localHero := newRangeMatchupTotals()
+			localVillain := newRangeMatchupTotals()
+			localEquitySum, localWeightSum := 0.0, 0.0
+
+			// カード重複チェック（デッドカードを含む）・頻度0のハンドは除外
+			var targets []int
+			for i, villainHand := range villainRange {
+				if villainHand.Weight > 0 && !HasCardDuplicates(hero.Cards, villainHand.Cards, board, deadCards) {
+					targets = append(targets, i)
+				}
+			}
+
+			// スートを入れ替えただけの相手ハンドはエクイティが同じため、同値類ごとに1回だけ計算する
+			groups := NewSuitIsomorphism(hero.Cards, board, deadCards...).groupIsomorphicHands(villainRange, targets)
+			if len(deadCards) == 0 {
+				prefetchRangeEquities(variant, hero.Cards, groupRepresentatives(villainRange, groups), board)
+			}
+
+			for _, group := range groups {
+				// 途中で中断されたハンドは相手ハンドが欠けるため集計しない
+				if ctx.Err() != nil {
+					return
+				}
+
+				result, err := CalculateHandVsHandEquityWithDeadCards(hero.Cards, villainRange[group[0]].Cards, board, deadCards)
+				if err != nil {
+					continue
+				}
+				equity := result.Equity
+
+				for _, villainIdx := range group {
+					villainHand := villainRange[villainIdx]
+					localHero.add(heroStr, equity, villainHand.Weight)
+					localVillain.add(GenerateBoardString(villainHand.Cards), 100-equity, hero.Weight)
+					localEquitySum += equity * hero.Weight * villainHand.Weight
+					localWeightSum += hero.Weight * villainHand.Weight
+				}
+			}
+
+			mu.Lock()
+			heroTotals.merge(localHero)
+			villainTotals.merge(localVillain)
+			matchupEquitySum += localEquitySum
+			matchupWeightSum += localWeightSum
+			mu.Unlock()
+			tracker.advance(1)
+		}(heroHand)
+	}
+
+	wg.Wait()
+
+	result, err := buildRangeVsRangeResult(ctx, heroRange, villainRange, heroTotals, villainTotals)
+	if result == nil {
+		return nil, err
+	}
+
+	// 全数計算ではカード除去を考慮した組み合わせ単位の加重平均をレンジ全体のエクイティとする
+	result.HeroEquity = matchupEquitySum / matchupWeightSum
+	result.VillainEquity = 100 - result.HeroEquity
+	return result, err
+}
+
+// CalculateRangeVsRangeEquityMonteCarlo はレンジ同士のエクイティをモンテカルロシミュレーションで計算します
+// 両レンジの各ハンドについて、相手レンジから頻度に比例してハンドを選びランアウトを配る試行をiterations回行います
+// 各ハンドのシードはrngから入力順に生成するため、同じシードのrngからは並列実行でも同じ結果になります
+func CalculateRangeVsRangeEquityMonteCarlo(heroRange WeightedRange, villainRange WeightedRange, board []poker.Card, iterations int, rng *rand.Rand) (*RangeVsRangeResult, error) {
+	return CalculateRangeVsRangeEquityMonteCarloContext(context.Background(), heroRange, villainRange, board, nil, iterations, rng, nil)
+}
+
+// CalculateRangeVsRangeEquityMonteCarloWithDeadCards はデッドカードを考慮したCalculateRangeVsRangeEquityMonteCarloです
+// デッドカードの扱いはCalculateRangeVsRangeEquityWithDeadCardsと同じです
+func CalculateRangeVsRangeEquityMonteCarloWithDeadCards(heroRange WeightedRange, villainRange WeightedRange, board []poker.Card, deadCards []poker.Card, iterations int, rng *rand.Rand) (*RangeVsRangeResult, error) {
+	return CalculateRangeVsRangeEquityMonteCarloContext(context.Background(), heroRange, villainRange, board, deadCards, iterations, rng, nil)
+}
+
+// CalculateRangeVsRangeEquityMonteCarloContext はctxのキャンセル・期限に対応したCalculateRangeVsRangeEquityMonteCarloです
+// 両レンジのハンドが1つ完了するたびにprogress（nil可）に進捗を通知します
+// deadCards（nil可）の扱いはCalculateRangeVsRangeEquityWithDeadCardsと同じです
+// ctxが中断された場合は完了したハンドのみで集計した結果（Partial=true）とctx.Err()を返します
+func CalculateRangeVsRangeEquityMonteCarloContext(ctx context.Context, heroRange WeightedRange, villainRange WeightedRange, board []poker.Card, deadCards []poker.Card, iterations int, rng *rand.Rand, progress ProgressFunc) (*RangeVsRangeResult, error) {
+	if err := ValidateBoardSize(board); err != nil {
+		return nil, err
+	}
+	if HasCardDuplicates(board, deadCards) {
+		return nil, fmt.Errorf("duplicate cards detected")
+	}
+	if iterations <= 0 {
+		return nil, fmt.Errorf("iterations must be positive, got %d", iterations)
+	}
+	variant, err := detectRangeVsRangeVariant(heroRange, villainRange)
+	if err != nil {
+		return nil, err
+	}
+
+	rng = randOrDefault(rng)
+	tracker := newProgressTracker(progress, len(validRangeHands(heroRange, board, deadCards))+len(validRangeHands(villainRange, board, deadCards)))
+	heroTotals, err := sampleRangeEquities(ctx, variant, heroRange, villainRange, board, deadCards, iterations, rng, tracker)
+	if err != nil {
+		return nil, fmt.Errorf("hero range: %v", err)
+	}
+	villainTotals, err := sampleRangeEquities(ctx, variant, villainRange, heroRange, board, deadCards, iterations, rng, tracker)
+	if err != nil {
+		return nil, fmt.Errorf("villain range: %v", err)
+	}
+
+	return buildRangeVsRangeResult(ctx, heroRange, villainRange, heroTotals, villainTotals)
+}
+
+// validRangeHands は頻度が正でボード・デッドカードと重複しないハンドを返します
+func validRangeHands(weightedRange WeightedRange, board []poker.Card, deadCards []poker.Card) WeightedRange {
+	var hands WeightedRange
+	for _, hand := range weightedRange {
+		if hand.Weight > 0 && !HasCardDuplicates(hand.Cards, board, deadCards) {
+			hands = append(hands, hand)
+		}
+	}
+	return hands
+}
+
+// detectRangeVsRangeVariant は両レンジの全ハンドが同じゲームタイプであることを検証します
+func detectRangeVsRangeVariant(heroRange WeightedRange, villainRange WeightedRange) (Variant, error) {
+	if len(heroRange) == 0 {
+		return 0, fmt.Errorf("hero range is empty")
+	}
+	return detectRangeVariant(heroRange[0].Cards, heroRange, villainRange)
+}
+
+// sampleRangeEquities はplayerRangeの各ハンドについてopponentRangeに対するエクイティをサンプリングで推定します
+// ctxが中断された場合は完了したハンドのみの集計値を返します
+func sampleRangeEquities(ctx context.Context, variant Variant, playerRange WeightedRange, opponentRange WeightedRange, board []poker.Card, deadCards []poker.Card, iterations int, rng *rand.Rand, tracker *progressTracker) (*rangeMatchupTotals, error) {
+	sampler, err := newRangeSampler(opponentRange, board, deadCards)
+	if err != nil {
+		return nil, err
+	}
+	seeds := deriveSeeds(rng, len(playerRange))
+
+	totals := newRangeMatchupTotals()
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+
+	numCPU := runtime.NumCPU()
+	semaphore := make(chan struct{}, numCPU)
+
+	for i, playerHand := range playerRange {
+		if playerHand.Weight <= 0 || HasCardDuplicates(playerHand.Cards, board, deadCards) {
+			continue
+		}
+
+		if !acquireSlot(ctx, semaphore) {
+			break
+		}
+		wg.Add(1)
+
+		go func(seed int64, player WeightedHand) {
+			defer wg.Done()
+			defer func() { <-semaphore }()
+
+			rng := rand.New(rand.NewSource(seed))
+			finalBoard := make([]poker.Card, len(board)+CardsToComplete(board))
+			copy(finalBoard, board)
+			hands := [][]poker.Card{player.Cards, nil}
+			// ハンドはサンプルごとにSetHandsで入れ替える
+			evaluator := NewShowdownEvaluator(variant, nil, nil)
+			evaluator.SetBoard(board)
+
+			wins := 0.0
+			completed := 0
+			for i := 0; i < iterations; i++ {
+				// 途中で中断されたハンドはサンプル数が足りないため集計しない
+				if i%contextCheckInterval == 0 && ctx.Err() != nil {
+					return
+				}
+				if !sampleOpponentHands(rng, []*rangeSampler{sampler}, hands) {
+					continue
+				}
+				remainingDeck := RemainingDeck(hands[0], hands[1], board, deadCards)
+				dealRandomRunout(rng, len(board), remainingDeck, finalBoard)
+
+				evaluator.SetHands(hands[0], hands[1])
+				switch evaluator.JudgeRunout(finalBoard[len(board):]) {
+				case "yourHand":
+					wins++
+				case "tie":
+					wins += 0.5
+				}
+				completed++
+			}
+
+			tracker.advance(1)
+			if completed == 0 {
+				return
+			}
+
+			mu.Lock()
+			totals.add(GenerateBoardString(player.Cards), wins/float64(completed)*100, 1)
+			mu.Unlock()
+		}(seeds[i], playerHand)
+	}
+
+	wg.Wait()
+	return totals, nil
+}
+
+// buildRangeVsRangeResult は集計値から平均エクイティと分布曲線を計算します
+// ctxが中断されていた場合は、集計できた範囲の結果（Partial=true）とctx.Err()を返します
+func buildRangeVsRangeResult(ctx context.Context, heroRange WeightedRange, villainRange WeightedRange, heroTotals *rangeMatchupTotals, villainTotals *rangeMatchupTotals) (*RangeVsRangeResult, error) {
+	heroEquities := heroTotals.equities()
+	villainEquities := villainTotals.equities()
+	if len(heroEquities) == 0 || len(villainEquities) == 0 {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		return nil, fmt.Errorf("no valid equity calculations")
+	}
+
+	heroWeights := heroRange.Weights()
+	villainWeights := villainRange.Weights()
+
+	return &RangeVsRangeResult{
+		HeroEquities:        heroEquities,
+		VillainEquities:     villainEquities,
+		HeroEquity:          WeightedAverageEquity(heroEquities, heroWeights),
+		VillainEquity:       WeightedAverageEquity(villainEquities, villainWeights),
+		HeroDistribution:    EquityDistribution(heroEquities, heroWeights),
+		VillainDistribution: EquityDistribution(villainEquities, villainWeights),
+		Partial:             ctx.Err() != nil,
+	}, ctx.Err()
+}
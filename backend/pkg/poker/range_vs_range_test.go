@@ -0,0 +1,164 @@
+package poker
+
+import (
+	"math"
+	"testing"
+
+	"github.com/chehsunliu/poker"
+)
+
+func TestEquityDistribution(t *testing.T) {
+	equities := map[string]float64{"hand1": 80, "hand2": 20, "hand3": 50}
+	weights := map[string]float64{"hand1": 1.0, "hand2": 2.0, "hand3": 1.0}
+
+	curve := EquityDistribution(equities, weights)
+
+	// テストケース1: エクイティの昇順に並ぶ
+	t.Run("Sorted by equity", func(t *testing.T) {
+		if len(curve) != 3 {
+			t.Fatalf("Expected 3 points, got %d", len(curve))
+		}
+		for i := 1; i < len(curve); i++ {
+			if curve[i-1].Equity > curve[i].Equity {
+				t.Errorf("Curve is not sorted at index %d", i)
+			}
+		}
+	})
+
+	// テストケース2: パーセンタイルは頻度で重み付けされる
+	t.Run("Percentiles are weighted", func(t *testing.T) {
+		expected := []float64{50, 75, 100}
+		for i, point := range curve {
+			if math.Abs(point.Percentile-expected[i]) > 1e-9 {
+				t.Errorf("Expected percentile %.2f at index %d, got %.2f", expected[i], i, point.Percentile)
+			}
+		}
+		if curve.EquityAt(50) != 20 {
+			t.Errorf("Expected equity 20 at 50th percentile, got %.2f", curve.EquityAt(50))
+		}
+		if curve.EquityAt(60) != 50 {
+			t.Errorf("Expected equity 50 at 60th percentile, got %.2f", curve.EquityAt(60))
+		}
+		if curve.EquityAt(100) != 80 {
+			t.Errorf("Expected equity 80 at 100th percentile, got %.2f", curve.EquityAt(100))
+		}
+	})
+
+	// テストケース3: 空の入力
+	t.Run("Empty equities", func(t *testing.T) {
+		empty := EquityDistribution(map[string]float64{}, nil)
+		if len(empty) != 0 || empty.EquityAt(50) != 0 {
+			t.Error("Expected empty curve for empty equities")
+		}
+	})
+}
+
+func TestCalculateRangeVsRangeEquity(t *testing.T) {
+	heroRange := WeightedRange{
+		{Cards: []poker.Card{poker.NewCard("Ah"), poker.NewCard("Ad"), poker.NewCard("Kc"), poker.NewCard("Qc")}, Weight: 1.0},
+		{Cards: []poker.Card{poker.NewCard("9h"), poker.NewCard("8h"), poker.NewCard("6s"), poker.NewCard("5s")}, Weight: 0.5},
+	}
+	villainRange := WeightedRange{
+		{Cards: []poker.Card{poker.NewCard("Kh"), poker.NewCard("Kd"), poker.NewCard("Jc"), poker.NewCard("Tc")}, Weight: 1.0},
+		{Cards: []poker.Card{poker.NewCard("Qh"), poker.NewCard("Qd"), poker.NewCard("Jd"), poker.NewCard("Td")}, Weight: 0.25},
+		{Cards: []poker.Card{poker.NewCard("Ac"), poker.NewCard("As"), poker.NewCard("4d"), poker.NewCard("3d")}, Weight: 1.0},
+	}
+	board := []poker.Card{
+		poker.NewCard("2c"),
+		poker.NewCard("7d"),
+		poker.NewCard("Ts"),
+	}
+
+	result, err := CalculateRangeVsRangeEquity(heroRange, villainRange, board)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	// テストケース1: 両サイドの各ハンドの結果が返される
+	t.Run("Both sides are returned", func(t *testing.T) {
+		if len(result.HeroEquities) != 2 {
+			t.Errorf("Expected 2 hero equities, got %d", len(result.HeroEquities))
+		}
+		if len(result.VillainEquities) != 3 {
+			t.Errorf("Expected 3 villain equities, got %d", len(result.VillainEquities))
+		}
+		if len(result.HeroDistribution) != 2 || len(result.VillainDistribution) != 3 {
+			t.Error("Expected distribution curves for both ranges")
+		}
+		if math.Abs(result.HeroEquity+result.VillainEquity-100) > 1e-9 {
+			t.Errorf("Expected range equities to sum to 100%%, got %.4f%%", result.HeroEquity+result.VillainEquity)
+		}
+	})
+
+	// テストケース2: ハンド単位の結果がCalculateHandVsRangeEquityと一致する
+	t.Run("Matches hand vs range calculation", func(t *testing.T) {
+		heroHand := heroRange[0]
+		_, expectedResult, err := CalculateHandVsWeightedRangeEquityParallel(heroHand.Cards, villainRange, board)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		expected := expectedResult.Equity
+		actual := result.HeroEquities[GenerateBoardString(heroHand.Cards)]
+		if math.Abs(actual-expected) > 1e-9 {
+			t.Errorf("Expected %.4f%%, got %.4f%%", expected, actual)
+		}
+	})
+
+	// テストケース3: モンテカルロの結果が全数計算に近い
+	t.Run("Monte Carlo agrees with exhaustive", func(t *testing.T) {
+		mcResult, err := CalculateRangeVsRangeEquityMonteCarlo(heroRange, villainRange, board, ACCURATE_ITERATIONS, nil)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		for hand, equity := range result.HeroEquities {
+			if math.Abs(mcResult.HeroEquities[hand]-equity) > 3 {
+				t.Errorf("Hero %s: Monte Carlo %.2f%% differs from exhaustive %.2f%%", hand, mcResult.HeroEquities[hand], equity)
+			}
+		}
+		for hand, equity := range result.VillainEquities {
+			if math.Abs(mcResult.VillainEquities[hand]-equity) > 3 {
+				t.Errorf("Villain %s: Monte Carlo %.2f%% differs from exhaustive %.2f%%", hand, mcResult.VillainEquities[hand], equity)
+			}
+		}
+	})
+
+	// テストケース4: 不正なボード
+	t.Run("Invalid board", func(t *testing.T) {
+		if _, err := CalculateRangeVsRangeEquity(heroRange, villainRange, board[:2]); err == nil {
+			t.Error("Expected error for invalid board, got nil")
+		}
+	})
+
+	// テストケース5: デッドカードを含むハンドは除外され、ランアウトにも配られない
+	t.Run("Dead cards", func(t *testing.T) {
+		deadCards := []poker.Card{poker.NewCard("Jd")}
+		deadResult, err := CalculateRangeVsRangeEquityWithDeadCards(heroRange, villainRange, board, deadCards)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if _, ok := deadResult.VillainEquities["QhQdJdTd"]; ok || len(deadResult.VillainEquities) != 2 {
+			t.Errorf("Expected the villain hand with a dead card to be excluded, got %v", deadResult.VillainEquities)
+		}
+
+		heroHand := heroRange[0]
+		_, expected, err := CalculateHandVsWeightedRangeEquityParallelWithDeadCards(heroHand.Cards, villainRange, board, deadCards)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if actual := deadResult.HeroEquities[GenerateBoardString(heroHand.Cards)]; math.Abs(actual-expected.Equity) > 1e-9 {
+			t.Errorf("Expected %.4f%%, got %.4f%%", expected.Equity, actual)
+		}
+
+		mcResult, err := CalculateRangeVsRangeEquityMonteCarloWithDeadCards(heroRange, villainRange, board, deadCards, FAST_ITERATIONS, NewRand(1))
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if len(mcResult.VillainEquities) != 2 {
+			t.Errorf("Expected 2 villain equities, got %v", mcResult.VillainEquities)
+		}
+
+		if _, err := CalculateRangeVsRangeEquityWithDeadCards(heroRange, villainRange, board, []poker.Card{poker.NewCard("Ts")}); err == nil {
+			t.Error("Expected error for a dead card on the board, got nil")
+		}
+	})
+}
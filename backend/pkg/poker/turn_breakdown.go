@@ -0,0 +1,329 @@
+package poker
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"sort"
+	"sync"
+
+	"github.com/chehsunliu/poker"
+)
+
+// TurnCardOutcome はターンカードがどちらのアウツかを表します
+type TurnCardOutcome int
+
+const (
+	TurnCardNeutral    TurnCardOutcome = iota // リードしているプレイヤーが変わらないカード
+	TurnCardHeroOut                           // フロップでリードされていたヒーローが逆転するカード（ヒーローのアウツ）
+	TurnCardVillainOut                        // フロップでリードしていたヒーローが逆転されるカード（相手のアウツ）
+)
+
+var turnCardOutcomeNames = map[TurnCardOutcome]string{
+	TurnCardNeutral:    "neutral",
+	TurnCardHeroOut:    "hero_out",
+	TurnCardVillainOut: "villain_out",
+}
+
+// String はアウツの種類の名前を返します（"hero_out"など）
+func (o TurnCardOutcome) String() string {
+	if name, ok := turnCardOutcomeNames[o]; ok {
+		return name
+	}
+	return fmt.Sprintf("TurnCardOutcome(%d)", int(o))
+}
+
+// MarshalText はJSONにアウツの種類の名前を出力します
+func (o TurnCardOutcome) MarshalText() ([]byte, error) {
+	if _, ok := turnCardOutcomeNames[o]; !ok {
+		return nil, fmt.Errorf("unknown turn card outcome: %d", int(o))
+	}
+	return []byte(o.String()), nil
+}
+
+// UnmarshalText はアウツの種類の名前を解析します
+func (o *TurnCardOutcome) UnmarshalText(text []byte) error {
+	for outcome, name := range turnCardOutcomeNames {
+		if name == string(text) {
+			*o = outcome
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown turn card outcome: %s", text)
+}
+
+// TurnCardEquity は1枚のターンカードが落ちた後のヒーローのエクイティです
+type TurnCardEquity struct {
+	Card    string          `json:"card"`    // ターンカード（"Ah"など）
+	Equity  float64         `json:"equity"`  // ターン後のヒーローのエクイティ（%、リバーを全数計算）
+	Delta   float64         `json:"delta"`   // フロップのエクイティからの変化（%ポイント）
+	Ahead   float64         `json:"ahead"`   // ターン時点でヒーローの役がリードしている割合（%、引き分けは0.5として計算）
+	Outcome TurnCardOutcome `json:"outcome"` // どちらのアウツか
+}
+
+// TurnBreakdown はフロップのエクイティと、ターンカードごとのエクイティの内訳です
+type TurnBreakdown struct {
+	FlopEquity float64          `json:"flop_equity"` // フロップでのヒーローのエクイティ（%）
+	FlopAhead  float64          `json:"flop_ahead"`  // フロップ時点でヒーローの役がリードしている割合（%）
+	Turns      []TurnCardEquity `json:"turns"`       // ターンカードごとの内訳（デッキの順序）
+	Partial    bool             `json:"partial"`     // キャンセル・期限切れにより一部のハンドのみで計算した結果か
+}
+
+// HeroOuts はヒーローのアウツ（フロップでリードされていて、ターンで逆転するカード）を返します
+// ターン時点の役の強さで判定するため、相手のリバーでの逆転（リドロー）を残すカードも含みます
+func (b TurnBreakdown) HeroOuts() []TurnCardEquity {
+	return b.filterTurns(TurnCardHeroOut)
+}
+
+// VillainOuts は相手のアウツ（フロップでリードしていて、ターンで逆転されるカード）を返します
+func (b TurnBreakdown) VillainOuts() []TurnCardEquity {
+	return b.filterTurns(TurnCardVillainOut)
+}
+
+func (b TurnBreakdown) filterTurns(outcome TurnCardOutcome) []TurnCardEquity {
+	var turns []TurnCardEquity
+	for _, turn := range b.Turns {
+		if turn.Outcome == outcome {
+			turns = append(turns, turn)
+		}
+	}
+	return turns
+}
+
+// BestTurns はヒーローのエクイティが高いターンカードを上位n枚返します
+func (b TurnBreakdown) BestTurns(n int) []TurnCardEquity {
+	return b.sortedTurns(n, func(x, y TurnCardEquity) bool { return x.Equity > y.Equity })
+}
+
+// WorstTurns はヒーローのエクイティが低いターンカードを上位n枚返します
+func (b TurnBreakdown) WorstTurns(n int) []TurnCardEquity {
+	return b.sortedTurns(n, func(x, y TurnCardEquity) bool { return x.Equity < y.Equity })
+}
+
+// sortedTurns はターンカードを並べ替えて先頭n枚を返します（エクイティが同じ場合はデッキの順序）
+func (b TurnBreakdown) sortedTurns(n int, less func(x, y TurnCardEquity) bool) []TurnCardEquity {
+	turns := append([]TurnCardEquity(nil), b.Turns...)
+	sort.SliceStable(turns, func(i, j int) bool { return less(turns[i], turns[j]) })
+	if n < len(turns) {
+		turns = turns[:n]
+	}
+	return turns
+}
+
+// turnTotals はターンカード（isoDeckのインデックス）ごとの集計です
+type turnTotals struct {
+	weight [52]float64 // ターンカードを含まないハンドの頻度の合計
+	equity [52]float64 // ターン後のエクイティ（%）の頻度による重み付き合計
+	ahead  [52]float64 // ターン時点でリードしている割合（%）の頻度による重み付き合計
+
+	flopWeight float64
+	flopEquity float64
+	flopAhead  float64
+}
+
+// showdownScore は判定結果をヒーローの獲得割合（勝ち1、引き分け0.5、負け0）に変換します
+func showdownScore(winner string) float64 {
+	switch winner {
+	case "yourHand":
+		return 1
+	case "tie":
+		return 0.5
+	}
+	return 0
+}
+
+// addHand は1つの相手ハンドについてターン・リバーを全数計算し、頻度weightで集計に加えます
+// evaluatorにはヒーローと相手のハンド、フロップが設定されている必要があります
+func (t *turnTotals) addHand(evaluator *ShowdownEvaluator, remainingDeck []poker.Card, weight float64) {
+	t.flopAhead += showdownScore(evaluator.Judge()) * 100 * weight
+
+	var points float64
+	var runouts int
+	for _, turn := range remainingDeck {
+		evaluator.Push(turn)
+		ahead := showdownScore(evaluator.Judge())
+
+		var turnPoints float64
+		var rivers int
+		for _, river := range remainingDeck {
+			if river == turn {
+				continue
+			}
+			evaluator.Push(river)
+			turnPoints += showdownScore(evaluator.Judge())
+			evaluator.Pop()
+			rivers++
+		}
+		evaluator.Pop()
+
+		idx := isoCardIndex[turn]
+		t.weight[idx] += weight
+		t.equity[idx] += turnPoints / float64(rivers) * 100 * weight
+		t.ahead[idx] += ahead * 100 * weight
+		points += turnPoints
+		runouts += rivers
+	}
+
+	t.flopWeight += weight
+	t.flopEquity += points / float64(runouts) * 100 * weight
+}
+
+// merge は別の集計結果を加えます
+func (t *turnTotals) merge(other *turnTotals) {
+	for i := range t.weight {
+		t.weight[i] += other.weight[i]
+		t.equity[i] += other.equity[i]
+		t.ahead[i] += other.ahead[i]
+	}
+	t.flopWeight += other.flopWeight
+	t.flopEquity += other.flopEquity
+	t.flopAhead += other.flopAhead
+}
+
+// breakdown は集計からターンカードごとの内訳を作成します
+func (t *turnTotals) breakdown() TurnBreakdown {
+	result := TurnBreakdown{
+		FlopEquity: t.flopEquity / t.flopWeight,
+		FlopAhead:  t.flopAhead / t.flopWeight,
+	}
+	for i, card := range isoDeck {
+		if t.weight[i] == 0 {
+			continue
+		}
+		turn := TurnCardEquity{
+			Card:   card.String(),
+			Equity: t.equity[i] / t.weight[i],
+			Ahead:  t.ahead[i] / t.weight[i],
+		}
+		turn.Delta = turn.Equity - result.FlopEquity
+		switch {
+		case result.FlopAhead <= 50 && turn.Ahead > 50:
+			turn.Outcome = TurnCardHeroOut
+		case result.FlopAhead >= 50 && turn.Ahead < 50:
+			turn.Outcome = TurnCardVillainOut
+		}
+		result.Turns = append(result.Turns, turn)
+	}
+	return result
+}
+
+// validateTurnBreakdownFlop はターンカードの内訳を計算するボードがフロップ（3枚）かを検証します
+func validateTurnBreakdownFlop(flop []poker.Card) error {
+	if len(flop) != 3 {
+		return fmt.Errorf("flop must have 3 cards, got %d", len(flop))
+	}
+	return nil
+}
+
+// CalculateTurnBreakdown はハンド同士のフロップでのエクイティと、ターンカードごとのエクイティ・アウツを計算します
+// CalculateHandVsHandEquityと同じランアウトをターンカードごとに集計するため、FlopEquityは全数計算のエクイティと一致します
+// アウツはターン時点の役の強さで判定します（フロップでリードされていて、ターンでリードするカードがヒーローのアウツ）
+func CalculateTurnBreakdown(yourHand []poker.Card, opponentHand []poker.Card, flop []poker.Card) (TurnBreakdown, error) {
+	return CalculateTurnBreakdownWithDeadCards(yourHand, opponentHand, flop, nil)
+}
+
+// CalculateTurnBreakdownWithDeadCards はデッドカードを考慮したCalculateTurnBreakdownです
+// デッドカードはターン・リバーに配られないため、内訳のターンカードにも含まれません
+func CalculateTurnBreakdownWithDeadCards(yourHand []poker.Card, opponentHand []poker.Card, flop []poker.Card, deadCards []poker.Card) (TurnBreakdown, error) {
+	if err := validateTurnBreakdownFlop(flop); err != nil {
+		return TurnBreakdown{}, err
+	}
+	if HasCardDuplicates(yourHand, opponentHand, flop, deadCards) {
+		return TurnBreakdown{}, fmt.Errorf("duplicate cards detected")
+	}
+	variant, err := DetectVariant(yourHand, opponentHand)
+	if err != nil {
+		return TurnBreakdown{}, err
+	}
+
+	evaluator := NewShowdownEvaluator(variant, yourHand, opponentHand)
+	evaluator.SetBoard(flop)
+
+	var totals turnTotals
+	totals.addHand(evaluator, RemainingDeck(yourHand, opponentHand, flop, deadCards), 1)
+	return totals.breakdown(), nil
+}
+
+// CalculateTurnBreakdownVsRange はヒーローのハンドと相手のレンジのターンカードごとのエクイティ・アウツを計算します
+// エクイティとリードしている割合は頻度で重み付けした平均です（ターンカードを含む相手ハンドはそのカードの集計から除外します）
+func CalculateTurnBreakdownVsRange(yourHand []poker.Card, opponentRange WeightedRange, flop []poker.Card) (TurnBreakdown, error) {
+	return CalculateTurnBreakdownVsRangeContext(context.Background(), yourHand, opponentRange, flop, nil, nil)
+}
+
+// CalculateTurnBreakdownVsRangeWithDeadCards はデッドカードを考慮したCalculateTurnBreakdownVsRangeです
+// デッドカードはターン・リバーに配られず、デッドカードを含む相手ハンドはレンジから除外されます
+func CalculateTurnBreakdownVsRangeWithDeadCards(yourHand []poker.Card, opponentRange WeightedRange, flop []poker.Card, deadCards []poker.Card) (TurnBreakdown, error) {
+	return CalculateTurnBreakdownVsRangeContext(context.Background(), yourHand, opponentRange, flop, deadCards, nil)
+}
+
+// CalculateTurnBreakdownVsRangeContext はctxによるキャンセル・期限と進捗の通知に対応したCalculateTurnBreakdownVsRangeです
+// deadCards（nil可）の扱いはCalculateTurnBreakdownVsRangeWithDeadCardsと同じです
+// 途中で中断された場合は、それまでに計算できたハンドの結果（Partial=true）とctx.Err()を返します
+func CalculateTurnBreakdownVsRangeContext(ctx context.Context, yourHand []poker.Card, opponentRange WeightedRange, flop []poker.Card, deadCards []poker.Card, progress ProgressFunc) (TurnBreakdown, error) {
+	if err := validateTurnBreakdownFlop(flop); err != nil {
+		return TurnBreakdown{}, err
+	}
+	if HasCardDuplicates(yourHand, flop, deadCards) {
+		return TurnBreakdown{}, fmt.Errorf("duplicate cards detected")
+	}
+	variant, err := detectRangeVariant(yourHand, opponentRange)
+	if err != nil {
+		return TurnBreakdown{}, err
+	}
+
+	// カード重複チェック（デッドカードを含む）・頻度0のハンドは除外
+	var targets []WeightedHand
+	for _, opponentHand := range opponentRange {
+		if opponentHand.Weight > 0 && !HasCardDuplicates(yourHand, opponentHand.Cards, flop, deadCards) {
+			targets = append(targets, opponentHand)
+		}
+	}
+	tracker := newProgressTracker(progress, len(targets))
+
+	// ハンドごとに集計し、ゴルーチンの完了順序に関係なく入力順にまとめる
+	handTotals := make([]*turnTotals, len(targets))
+	var wg sync.WaitGroup
+	semaphore := make(chan struct{}, runtime.NumCPU()) // 同時実行数をCPUコア数に制限
+
+	for i, opponentHand := range targets {
+		// セマフォを取得（中断された場合は以降のハンドを計算しない）
+		if !acquireSlot(ctx, semaphore) {
+			break
+		}
+		wg.Add(1)
+
+		go func(i int, opponentHand WeightedHand) {
+			defer wg.Done()
+			defer func() { <-semaphore }()
+
+			evaluator := NewShowdownEvaluator(variant, yourHand, opponentHand.Cards)
+			evaluator.SetBoard(flop)
+			totals := &turnTotals{}
+			totals.addHand(evaluator, RemainingDeck(yourHand, opponentHand.Cards, flop, deadCards), opponentHand.Weight)
+			handTotals[i] = totals
+			tracker.advance(1)
+		}(i, opponentHand)
+	}
+
+	wg.Wait()
+
+	var totals turnTotals
+	for _, t := range handTotals {
+		if t != nil {
+			totals.merge(t)
+		}
+	}
+	if totals.flopWeight == 0 {
+		if err := ctx.Err(); err != nil {
+			return TurnBreakdown{}, err
+		}
+		return TurnBreakdown{}, fmt.Errorf("no valid hands in opponent range")
+	}
+
+	result := totals.breakdown()
+	if err := ctx.Err(); err != nil {
+		result.Partial = true
+		return result, err
+	}
+	return result, nil
+}
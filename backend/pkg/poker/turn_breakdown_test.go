@@ -0,0 +1,166 @@
+package poker
+
+import (
+	"context"
+	"math"
+	"testing"
+)
+
+func TestCalculateTurnBreakdown(t *testing.T) {
+	yourHand := cards("Ah", "Kh")
+	opponentHand := cards("Qs", "Qd")
+	flop := cards("Qh", "7h", "2c")
+
+	breakdown, err := CalculateTurnBreakdown(yourHand, opponentHand, flop)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	// テストケース1: ターンごとの集計の平均は全数計算のエクイティと一致する
+	t.Run("Flop equity matches exhaustive equity", func(t *testing.T) {
+		expected, _ := CalculateHandVsHandEquity(yourHand, opponentHand, flop)
+		if math.Abs(breakdown.FlopEquity-expected.Equity) > 1e-9 {
+			t.Errorf("Expected flop equity %.4f%%, got %.4f%%", expected.Equity, breakdown.FlopEquity)
+		}
+		if len(breakdown.Turns) != 45 {
+			t.Errorf("Expected 45 turn cards, got %d", len(breakdown.Turns))
+		}
+		if breakdown.FlopAhead != 0 {
+			t.Errorf("Expected hero to be behind on the flop, got %.1f%%", breakdown.FlopAhead)
+		}
+	})
+
+	// テストケース2: ボードをペアにしないハートがヒーローのアウツになる（相手のセットにはリバーでフルハウスのリドローが残る）
+	t.Run("Hero outs", func(t *testing.T) {
+		var outs []string
+		for _, turn := range breakdown.HeroOuts() {
+			outs = append(outs, turn.Card)
+		}
+		expected := []string{"3h", "4h", "5h", "6h", "8h", "9h", "Th", "Jh"}
+		if len(outs) != len(expected) {
+			t.Fatalf("Expected outs %v, got %v", expected, outs)
+		}
+		seen := make(map[string]bool)
+		for _, out := range outs {
+			seen[out] = true
+		}
+		for _, card := range expected {
+			if !seen[card] {
+				t.Errorf("Expected %s to be an out, got %v", card, outs)
+			}
+		}
+		if villainOuts := breakdown.VillainOuts(); len(villainOuts) != 0 {
+			t.Errorf("Expected no villain outs when hero is behind, got %v", villainOuts)
+		}
+	})
+
+	// テストケース3: 最良・最悪のターン
+	t.Run("Best and worst turns", func(t *testing.T) {
+		best := breakdown.BestTurns(3)
+		if len(best) != 3 || best[0].Equity < best[1].Equity || best[1].Equity < best[2].Equity {
+			t.Fatalf("Expected 3 turns in descending order, got %+v", best)
+		}
+		if best[0].Outcome != TurnCardHeroOut || best[0].Delta <= 0 {
+			t.Errorf("Expected the best turn to be a hero out, got %+v", best[0])
+		}
+		worst := breakdown.WorstTurns(1)
+		if len(worst) != 1 || worst[0].Equity != 0 {
+			t.Errorf("Expected the worst turn to leave hero drawing dead, got %+v", worst)
+		}
+	})
+
+	// テストケース4: フロップ以外のボードはエラー
+	t.Run("Rejects non-flop boards", func(t *testing.T) {
+		if _, err := CalculateTurnBreakdown(yourHand, opponentHand, cards("Qh", "7h", "2c", "3s")); err == nil {
+			t.Errorf("Expected an error for a turn board")
+		}
+	})
+}
+
+func TestCalculateTurnBreakdownVsRange(t *testing.T) {
+	yourHand := cards("As", "Ad", "Kh", "Qc")
+	flop := cards("9c", "5h", "2d")
+	opponentRange := WeightedRange{
+		{Cards: cards("9s", "9d", "Kd", "Qd"), Weight: 1.0},
+		{Cards: cards("Jh", "Th", "8s", "7c"), Weight: 0.5},
+		{Cards: cards("Kh", "Jd", "8h", "7h"), Weight: 1.0}, // ヒーローとカードが重複するため除外
+	}
+
+	breakdown, err := CalculateTurnBreakdownVsRange(yourHand, opponentRange, flop)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	// テストケース1: フロップのエクイティは頻度で重み付けしたレンジのエクイティと一致する
+	t.Run("Flop equity matches range equity", func(t *testing.T) {
+		_, expected, err := CalculateHandVsWeightedRangeEquityParallel(yourHand, opponentRange, flop)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if math.Abs(breakdown.FlopEquity-expected.Equity) > 1e-9 {
+			t.Errorf("Expected flop equity %.4f%%, got %.4f%%", expected.Equity, breakdown.FlopEquity)
+		}
+	})
+
+	// テストケース2: 相手ハンドに含まれるターンカードはそのハンドを除いて集計する
+	t.Run("Card removal", func(t *testing.T) {
+		single, _ := CalculateTurnBreakdown(yourHand, opponentRange[0].Cards, flop)
+		var fromRange, fromHand *TurnCardEquity
+		for i := range breakdown.Turns {
+			if breakdown.Turns[i].Card == "Jh" {
+				fromRange = &breakdown.Turns[i]
+			}
+		}
+		for i := range single.Turns {
+			if single.Turns[i].Card == "Jh" {
+				fromHand = &single.Turns[i]
+			}
+		}
+		if fromRange == nil || fromHand == nil || math.Abs(fromRange.Equity-fromHand.Equity) > 1e-9 {
+			t.Errorf("Expected the Jh turn to only count 9s9dKdQd, got %+v and %+v", fromRange, fromHand)
+		}
+	})
+
+	// テストケース3: キャンセル済みのコンテキストではエラー
+	t.Run("Cancelled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		if _, err := CalculateTurnBreakdownVsRangeContext(ctx, yourHand, opponentRange, flop, nil, nil); err != context.Canceled {
+			t.Errorf("Expected context.Canceled, got %v", err)
+		}
+	})
+
+	// テストケース4: デッドカードはターンカードにならず、デッドカードを含む相手ハンドは除外される
+	t.Run("Dead cards", func(t *testing.T) {
+		deadCards := cards("Jh")
+		deadBreakdown, err := CalculateTurnBreakdownVsRangeWithDeadCards(yourHand, opponentRange, flop, deadCards)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		for _, turn := range deadBreakdown.Turns {
+			if turn.Card == "Jh" {
+				t.Errorf("Expected the dead card not to be a turn card, got %+v", turn)
+			}
+		}
+
+		// 残るのは9s9dKdQdのみ
+		_, expected, err := CalculateHandVsWeightedRangeEquityParallelWithDeadCards(yourHand, opponentRange, flop, deadCards)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if math.Abs(deadBreakdown.FlopEquity-expected.Equity) > 1e-9 {
+			t.Errorf("Expected flop equity %.4f%%, got %.4f%%", expected.Equity, deadBreakdown.FlopEquity)
+		}
+		single, err := CalculateTurnBreakdownWithDeadCards(yourHand, opponentRange[0].Cards, flop, deadCards)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if len(single.Turns) != 40 || math.Abs(single.FlopEquity-deadBreakdown.FlopEquity) > 1e-9 {
+			t.Errorf("Expected 40 turn cards with the same flop equity, got %d turns and %.4f%%", len(single.Turns), single.FlopEquity)
+		}
+
+		if _, err := CalculateTurnBreakdownWithDeadCards(yourHand, opponentRange[0].Cards, flop, cards("9c")); err == nil {
+			t.Error("Expected error for a dead card on the flop, got nil")
+		}
+	})
+}
@@ -37,3 +37,50 @@ func HasCardDuplicates(cards ...[]poker.Card) bool {
 	}
 	return false
 }
+
+// ValidateBoardSize checks that the board has 0 (preflop), 3 (flop), 4 (turn) or 5 (river) cards
+func ValidateBoardSize(board []poker.Card) error {
+	switch len(board) {
+	case 0, 3, 4, 5:
+		return nil
+	default:
+		return fmt.Errorf("invalid board size: %d (must be 0, 3, 4 or 5 cards)", len(board))
+	}
+}
+
+// CardsToComplete returns how many cards must be dealt to complete a 5-card board
+func CardsToComplete(board []poker.Card) int {
+	if len(board) >= 5 {
+		return 0
+	}
+	return 5 - len(board)
+}
+
+// RemainingDeck returns the cards of a full 52-card deck that are not in any of the provided card arrays
+func RemainingDeck(usedCards ...[]poker.Card) []poker.Card {
+	used := make(map[poker.Card]bool)
+	for _, cards := range usedCards {
+		for _, card := range cards {
+			used[card] = true
+		}
+	}
+
+	remainingDeck := make([]poker.Card, 0, 52)
+	for _, card := range FullDeck() {
+		if !used[card] {
+			remainingDeck = append(remainingDeck, card)
+		}
+	}
+	return remainingDeck
+}
+
+// FullDeck returns all 52 cards in a fixed order (2s, 2h, 2d, 2c, 3s, ... Ac)
+func FullDeck() []poker.Card {
+	cards := make([]poker.Card, 0, 52)
+	for _, rank := range "23456789TJQKA" {
+		for _, suit := range "shdc" {
+			cards = append(cards, poker.NewCard(string(rank)+string(suit)))
+		}
+	}
+	return cards
+}
@@ -224,3 +224,51 @@ func TestHasCardDuplicates(t *testing.T) {
 		}
 	})
 }
+
+func TestValidateBoardSize(t *testing.T) {
+	// テストケース1: 有効なボード枚数
+	t.Run("Valid board sizes", func(t *testing.T) {
+		for _, size := range []int{0, 3, 4, 5} {
+			board := FullDeck()[:size]
+			if err := ValidateBoardSize(board); err != nil {
+				t.Errorf("Expected no error for %d-card board, got %v", size, err)
+			}
+			if CardsToComplete(board) != 5-size {
+				t.Errorf("Expected %d cards to complete %d-card board, got %d", 5-size, size, CardsToComplete(board))
+			}
+		}
+	})
+
+	// テストケース2: 無効なボード枚数
+	t.Run("Invalid board sizes", func(t *testing.T) {
+		for _, size := range []int{1, 2, 6} {
+			if err := ValidateBoardSize(FullDeck()[:size]); err == nil {
+				t.Errorf("Expected error for %d-card board, got nil", size)
+			}
+		}
+	})
+}
+
+func TestRemainingDeck(t *testing.T) {
+	hand := []poker.Card{
+		poker.NewCard("Ah"),
+		poker.NewCard("Kd"),
+	}
+	board := []poker.Card{
+		poker.NewCard("2c"),
+		poker.NewCard("7d"),
+		poker.NewCard("Ts"),
+	}
+
+	remainingDeck := RemainingDeck(hand, board)
+
+	if len(remainingDeck) != 47 {
+		t.Errorf("Expected 47 remaining cards, got %d", len(remainingDeck))
+	}
+	if HasCardDuplicates(remainingDeck, hand, board) {
+		t.Error("Expected remaining deck to exclude used cards")
+	}
+	if len(FullDeck()) != 52 || HasCardDuplicates(FullDeck()) {
+		t.Error("Expected full deck to contain 52 distinct cards")
+	}
+}
@@ -0,0 +1,183 @@
+package poker
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/chehsunliu/poker"
+)
+
+// Variant はゲームの種類（ハンドの枚数と役の作り方のルール）を表します
+type Variant int
+
+const (
+	VariantHoldem Variant = iota // テキサスホールデム（2枚、ハンドとボードの任意の5枚）
+	VariantPLO4                  // 4-card PLO（ハンドから2枚、ボードから3枚）
+	VariantPLO5                  // 5-card PLO
+	VariantPLO6                  // 6-card PLO
+)
+
+// Variants は定義済みの全ゲームタイプです
+var Variants = []Variant{VariantHoldem, VariantPLO4, VariantPLO5, VariantPLO6}
+
+// variantInfo はゲームタイプごとの定義です
+type variantInfo struct {
+	name     string // 表示名（画像タイトルなど）
+	handSize int    // ハンドの枚数
+	omaha    bool   // ハンドから2枚、ボードから3枚を使う必要があるか
+	gameType string // DBのgame_typeカラムの値
+	dataDir  string // レンジデータのディレクトリ名（data/<dataDir>/...）
+}
+
+var variantInfos = map[Variant]variantInfo{
+	VariantHoldem: {name: "Hold'em", handSize: 2, omaha: false, gameType: "holdem", dataDir: "nlhe"},
+	VariantPLO4:   {name: "PLO", handSize: 4, omaha: true, gameType: "4card_plo", dataDir: "plo4"},
+	VariantPLO5:   {name: "PLO5", handSize: 5, omaha: true, gameType: "5card_plo", dataDir: "plo5"},
+	VariantPLO6:   {name: "PLO6", handSize: 6, omaha: true, gameType: "6card_plo", dataDir: "plo6"},
+}
+
+func (v Variant) info() variantInfo {
+	info, ok := variantInfos[v]
+	if !ok {
+		panic(fmt.Sprintf("unknown variant: %d", int(v)))
+	}
+	return info
+}
+
+// String はゲームタイプの表示名を返します（"Hold'em", "PLO", "PLO5", "PLO6"）
+func (v Variant) String() string {
+	if _, ok := variantInfos[v]; !ok {
+		return fmt.Sprintf("Variant(%d)", int(v))
+	}
+	return v.info().name
+}
+
+// HandSize はハンドの枚数を返します
+func (v Variant) HandSize() int {
+	return v.info().handSize
+}
+
+// IsOmaha はハンドから2枚、ボードから3枚を使うオマハ系のルールかを返します
+func (v Variant) IsOmaha() bool {
+	return v.info().omaha
+}
+
+// GameType はDBのgame_typeカラムに保存する値を返します（"4card_plo"など）
+func (v Variant) GameType() string {
+	return v.info().gameType
+}
+
+// DataDir はレンジデータのディレクトリ名を返します（"plo4"など）
+func (v Variant) DataDir() string {
+	return v.info().dataDir
+}
+
+// VariantForHandSize はハンドの枚数からゲームタイプを返します
+func VariantForHandSize(size int) (Variant, error) {
+	for _, v := range Variants {
+		if v.HandSize() == size {
+			return v, nil
+		}
+	}
+	return 0, fmt.Errorf("unsupported hand size: %d", size)
+}
+
+// VariantFromGameType はDBのgame_typeカラムの値からゲームタイプを返します
+func VariantFromGameType(gameType string) (Variant, error) {
+	for _, v := range Variants {
+		if v.GameType() == gameType {
+			return v, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown game type: %s", gameType)
+}
+
+// ParseVariant はゲームタイプ名を解析します
+// 表示名（"PLO5"）、game_type（"5card_plo"）、データディレクトリ名（"plo5"）を大文字小文字を区別せずに受け付けます
+func ParseVariant(name string) (Variant, error) {
+	for _, v := range Variants {
+		info := v.info()
+		for _, candidate := range []string{info.name, info.gameType, info.dataDir} {
+			if strings.EqualFold(name, candidate) {
+				return v, nil
+			}
+		}
+	}
+	return 0, fmt.Errorf("unknown variant: %s", name)
+}
+
+// DetectVariant は全ハンドの枚数が一致していることを確認し、ゲームタイプを判定します
+// 枚数が異なるハンドが混在している場合はエラーを返します
+func DetectVariant(hands ...[]poker.Card) (Variant, error) {
+	if len(hands) == 0 {
+		return 0, fmt.Errorf("at least 1 hand is required")
+	}
+	for _, hand := range hands[1:] {
+		if len(hand) != len(hands[0]) {
+			return 0, fmt.Errorf("mixed hand sizes: %d and %d cards", len(hands[0]), len(hand))
+		}
+	}
+	return VariantForHandSize(len(hands[0]))
+}
+
+// ValidateHand はハンドの枚数がゲームタイプと一致しているかを検証します
+func (v Variant) ValidateHand(hand []poker.Card) error {
+	if len(hand) != v.HandSize() {
+		return fmt.Errorf("%s hand must have %d cards, got %d", v, v.HandSize(), len(hand))
+	}
+	return nil
+}
+
+// ValidateRange はレンジ内の全ハンドの枚数がゲームタイプと一致しているかを検証します
+func (v Variant) ValidateRange(weightedRange WeightedRange) error {
+	for _, weightedHand := range weightedRange {
+		if err := v.ValidateHand(weightedHand.Cards); err != nil {
+			return fmt.Errorf("invalid hand %s in range: %v", GenerateBoardString(weightedHand.Cards), err)
+		}
+	}
+	return nil
+}
+
+// Evaluate はゲームタイプのルールでハンドの最良のランクを返します（小さいほど強い）
+func (v Variant) Evaluate(hand []poker.Card, board []poker.Card) int32 {
+	if v.IsOmaha() {
+		return evaluateOmahaHand(hand, board)
+	}
+
+	// Hold'emはボードとハンドの全カードから最良の5枚を選ぶ
+	var buf [7]poker.Card
+	n := copy(buf[:], board)
+	n += copy(buf[n:], hand)
+	return evaluateBestFive(buf[:n])
+}
+
+// Judge はゲームタイプのルールで2つのハンドの勝敗を判定します
+// 戻り値は "yourHand", "opponentHand", "tie" のいずれかです
+func (v Variant) Judge(yourHand []poker.Card, opponentHand []poker.Card, board []poker.Card) string {
+	return compareRanks(v.Evaluate(yourHand, board), v.Evaluate(opponentHand, board))
+}
+
+// compareRanks は2つのランク（小さいほど強い）から勝敗を判定します
+func compareRanks(yourRank int32, opponentRank int32) string {
+	if yourRank < opponentRank {
+		return "yourHand"
+	} else if yourRank > opponentRank {
+		return "opponentHand"
+	} else {
+		return "tie"
+	}
+}
+
+// detectRangeVariant はヒーローのハンドからゲームタイプを判定し、相手レンジの全ハンドが同じ枚数であることを検証します
+func detectRangeVariant(yourHand []poker.Card, opponentRanges ...WeightedRange) (Variant, error) {
+	variant, err := DetectVariant(yourHand)
+	if err != nil {
+		return 0, err
+	}
+	for _, opponentRange := range opponentRanges {
+		if err := variant.ValidateRange(opponentRange); err != nil {
+			return 0, err
+		}
+	}
+	return variant, nil
+}
@@ -0,0 +1,147 @@
+package poker
+
+import (
+	"testing"
+
+	"github.com/chehsunliu/poker"
+)
+
+func TestVariantLookup(t *testing.T) {
+	// テストケース1: ハンドの枚数からゲームタイプを判定
+	t.Run("Variant for hand size", func(t *testing.T) {
+		testCases := []struct {
+			size     int
+			expected Variant
+		}{
+			{2, VariantHoldem},
+			{4, VariantPLO4},
+			{5, VariantPLO5},
+			{6, VariantPLO6},
+		}
+
+		for _, tc := range testCases {
+			variant, err := VariantForHandSize(tc.size)
+			if err != nil {
+				t.Errorf("Expected no error for size %d, got %v", tc.size, err)
+			}
+			if variant != tc.expected {
+				t.Errorf("For size %d, expected %s, got %s", tc.size, tc.expected, variant)
+			}
+		}
+
+		if _, err := VariantForHandSize(3); err == nil {
+			t.Error("Expected error for unsupported hand size")
+		}
+	})
+
+	// テストケース2: game_typeとの相互変換
+	t.Run("Game type round trip", func(t *testing.T) {
+		for _, variant := range Variants {
+			parsed, err := VariantFromGameType(variant.GameType())
+			if err != nil {
+				t.Errorf("Expected no error for %s, got %v", variant.GameType(), err)
+			}
+			if parsed != variant {
+				t.Errorf("Expected %s, got %s", variant, parsed)
+			}
+		}
+
+		if _, err := VariantFromGameType("7card_plo"); err == nil {
+			t.Error("Expected error for unknown game type")
+		}
+	})
+
+	// テストケース3: 表示名・game_type・ディレクトリ名の解析
+	t.Run("Parse variant names", func(t *testing.T) {
+		testCases := []struct {
+			name     string
+			expected Variant
+		}{
+			{"PLO5", VariantPLO5},
+			{"plo5", VariantPLO5},
+			{"5card_plo", VariantPLO5},
+			{"PLO", VariantPLO4},
+			{"plo4", VariantPLO4},
+			{"holdem", VariantHoldem},
+			{"6card_plo", VariantPLO6},
+		}
+
+		for _, tc := range testCases {
+			variant, err := ParseVariant(tc.name)
+			if err != nil {
+				t.Errorf("Expected no error for %s, got %v", tc.name, err)
+			}
+			if variant != tc.expected {
+				t.Errorf("For %s, expected %s, got %s", tc.name, tc.expected, variant)
+			}
+		}
+
+		if _, err := ParseVariant("stud"); err == nil {
+			t.Error("Expected error for unknown variant")
+		}
+	})
+}
+
+func TestDetectVariant(t *testing.T) {
+	// テストケース1: 同じ枚数のハンド
+	t.Run("Same hand sizes", func(t *testing.T) {
+		variant, err := DetectVariant(cards("As", "Ks", "Qs", "Js"), cards("Ah", "Kh", "Qh", "Jh"))
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if variant != VariantPLO4 {
+			t.Errorf("Expected PLO, got %s", variant)
+		}
+	})
+
+	// テストケース2: 枚数が混在している場合はエラー
+	t.Run("Mixed hand sizes", func(t *testing.T) {
+		if _, err := DetectVariant(cards("As", "Ks", "Qs", "Js"), cards("Ah", "Kh", "Qh", "Jh", "Th")); err == nil {
+			t.Error("Expected error for mixed hand sizes")
+		}
+	})
+
+	// テストケース3: JudgeWinnerも枚数の混在をエラーにする
+	t.Run("JudgeWinner rejects mixed hand sizes", func(t *testing.T) {
+		board := cards("2c", "7d", "9h", "Tc", "3s")
+		if _, err := JudgeWinner(cards("As", "Ks"), cards("Ah", "Kh", "Qh", "Jh"), board); err == nil {
+			t.Error("Expected error for mixed hand sizes")
+		}
+	})
+
+	// テストケース4: レンジ計算も枚数の混在をエラーにする
+	t.Run("Range equity rejects mixed hand sizes", func(t *testing.T) {
+		opponentRange := WeightedRange{
+			{Cards: cards("Ah", "Kh", "Qh", "Jh"), Weight: 1},
+			{Cards: cards("2h", "3h", "4h", "5h", "6h"), Weight: 1},
+		}
+		_, _, err := CalculateHandVsWeightedRangeEquityParallel(cards("As", "Ks", "Qs", "Js"), opponentRange, cards("2c", "7d", "9c"))
+		if err == nil {
+			t.Error("Expected error for mixed hand sizes in range")
+		}
+	})
+}
+
+func TestVariantJudge(t *testing.T) {
+	board := []poker.Card{
+		poker.NewCard("Kh"),
+		poker.NewCard("Qh"),
+		poker.NewCard("Jh"),
+		poker.NewCard("Th"),
+		poker.NewCard("5c"),
+	}
+
+	// PLOではハンドから2枚使う必要があるため、1枚のハートではフラッシュにならない
+	yourHand := cards("Ah", "2c", "3d", "4s")
+	opponentHand := cards("Kc", "Kd", "7s", "8s")
+	if result := VariantPLO4.Judge(yourHand, opponentHand, board); result != "opponentHand" {
+		t.Errorf("Expected 'opponentHand' to win under PLO rules, got %s", result)
+	}
+
+	// Hold'emではハンドとボードの任意の5枚を使えるため、ロイヤルフラッシュになる
+	yourHand = cards("Ah", "2c")
+	opponentHand = cards("Kc", "Kd")
+	if result := VariantHoldem.Judge(yourHand, opponentHand, board); result != "yourHand" {
+		t.Errorf("Expected 'yourHand' to win under Hold'em rules, got %s", result)
+	}
+}
@@ -8,6 +8,16 @@ CREATE TABLE IF NOT EXISTS daily_quiz_results (
     result TEXT,
     average_equity DECIMAL(5,2),
     game_type VARCHAR(20) NOT NULL DEFAULT '4card_plo',
+    equity_std_error DECIMAL(5,2),
+    equity_margin DECIMAL(5,2),
+    samples_used INTEGER,
+    exhaustive BOOLEAN,
+    seed BIGINT,
+    hand_class_breakdown TEXT,
+    second_flop VARCHAR(255),
+    board_texture VARCHAR(255),
+    blocker_insight TEXT,
+    call_decision TEXT,
     created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
 );
 
@@ -16,4 +26,18 @@ CREATE INDEX IF NOT EXISTS idx_daily_quiz_results_date ON daily_quiz_results(dat
 CREATE INDEX IF NOT EXISTS idx_daily_quiz_results_scenario ON daily_quiz_results(scenario);
 CREATE INDEX IF NOT EXISTS idx_daily_quiz_results_hero_hand ON daily_quiz_results(hero_hand);
 CREATE INDEX IF NOT EXISTS idx_daily_quiz_results_flop ON daily_quiz_results(flop);
-CREATE INDEX IF NOT EXISTS idx_daily_quiz_results_game_type ON daily_quiz_results(game_type);
\ No newline at end of file
+CREATE INDEX IF NOT EXISTS idx_daily_quiz_results_game_type ON daily_quiz_results(game_type);
+
+-- 全数計算したハンド同士のエクイティを保存するキャッシュテーブル
+-- キーはゲームタイプとスートを正準化した(ヒーロー, ボード, 相手)の組み合わせ
+CREATE TABLE IF NOT EXISTS equity_cache (
+    matchup_key VARCHAR(128) PRIMARY KEY,
+    game_type VARCHAR(20) NOT NULL,
+    wins INTEGER NOT NULL,
+    ties INTEGER NOT NULL,
+    losses INTEGER NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    last_used_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_equity_cache_last_used_at ON equity_cache(last_used_at);
@@ -17,6 +17,8 @@
 #   -m <モード>     : Monte Carloの精度モード (FAST/NORMAL/ACCURATE、デフォルト: NORMAL)
 #   -j <ジョブ数>   : 並列ジョブ数 (デフォルト: CPU数)
 #   -A              : Adaptive samplingモードを有効にする
+#   -s <シード>     : 乱数シード (デフォルト: 現在時刻から生成、同じシードと日付で同じクイズを再生成)
+#   -T <時間>       : 1日分のエクイティ計算の制限時間 (例: 30m、デフォルト: 無制限)
 #   -h              : ヘルプを表示
 
 # 日付生成関数
@@ -48,9 +50,11 @@ USE_MONTE_CARLO=false
 MONTE_CARLO_MODE="NORMAL"
 USE_ADAPTIVE_SAMPLING=false
 MAX_JOBS=""
+SEED=""
+TIMEOUT=""
 
 # コマンドライン引数の解析
-while getopts "l:d:D:N:H:p:u:P:n:Mm:j:Ah" opt; do
+while getopts "l:d:D:N:H:p:u:P:n:Mm:j:As:T:h" opt; do
   case $opt in
     l) LOG_FILE=$OPTARG ;;
     d) DATA_DIR=$OPTARG ;;
@@ -65,6 +69,8 @@ while getopts "l:d:D:N:H:p:u:P:n:Mm:j:Ah" opt; do
     m) MONTE_CARLO_MODE=$OPTARG ;;
     j) MAX_JOBS=$OPTARG ;;
     A) USE_ADAPTIVE_SAMPLING=true ;;
+    s) SEED=$OPTARG ;;
+    T) TIMEOUT=$OPTARG ;;
     h)
       echo "使用方法: $0 [オプション]"
       echo "オプション:"
@@ -81,6 +87,8 @@ while getopts "l:d:D:N:H:p:u:P:n:Mm:j:Ah" opt; do
       echo "  -m <モード>     : Monte Carloの精度モード (FAST/NORMAL/ACCURATE、デフォルト: NORMAL)"
       echo "  -j <ジョブ数>   : 並列ジョブ数 (デフォルト: CPU数)"
       echo "  -A              : Adaptive samplingモードを有効にする"
+      echo "  -s <シード>     : 乱数シード (デフォルト: 現在時刻から生成、同じシードと日付で同じクイズを再生成)"
+      echo "  -T <時間>       : 1日分のエクイティ計算の制限時間 (例: 30m、デフォルト: 無制限)"
       echo "  -h              : ヘルプを表示"
       exit 0
       ;;
@@ -187,6 +195,12 @@ for ((i=0; i<DAYS_COUNT; i++)); do
   if [ -n "$MAX_JOBS" ]; then
     CMD_ARGS="$CMD_ARGS -jobs $MAX_JOBS"
   fi
+  if [ -n "$SEED" ]; then
+    CMD_ARGS="$CMD_ARGS -seed $SEED"
+  fi
+  if [ -n "$TIMEOUT" ]; then
+    CMD_ARGS="$CMD_ARGS -timeout $TIMEOUT"
+  fi
 
   # バッチ処理の実行
   echo "$(date '+%Y-%m-%d %H:%M:%S') - 日付: $CURRENT_DATE の処理を開始します"